@@ -8,14 +8,11 @@ import (
 	"os"
 	"os/signal"
 	"syscall"
-	"time"
 
 	"github.com/saimonsiddique/blog-api/internal/app"
 	"github.com/saimonsiddique/blog-api/internal/config"
 )
 
-const shutdownTimeout = 30 * time.Second
-
 func main() {
 	if err := run(); err != nil {
 		log.Fatalf("Application failed: %v", err)
@@ -58,7 +55,7 @@ func run() error {
 	}
 
 	// Graceful shutdown
-	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
 	defer cancel()
 
 	log.Println("Shutting down gracefully...")
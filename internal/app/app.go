@@ -2,15 +2,23 @@ package app
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/saimonsiddique/blog-api/internal/cache"
 	"github.com/saimonsiddique/blog-api/internal/config"
 	"github.com/saimonsiddique/blog-api/internal/database"
+	"github.com/saimonsiddique/blog-api/internal/domain"
+	"github.com/saimonsiddique/blog-api/internal/events"
 	"github.com/saimonsiddique/blog-api/internal/handler"
+	"github.com/saimonsiddique/blog-api/internal/metrics"
+	"github.com/saimonsiddique/blog-api/internal/pkg/captcha"
+	"github.com/saimonsiddique/blog-api/internal/pkg/password"
+	"github.com/saimonsiddique/blog-api/internal/pkg/version"
 	"github.com/saimonsiddique/blog-api/internal/queue"
 	"github.com/saimonsiddique/blog-api/internal/repository"
 	"github.com/saimonsiddique/blog-api/internal/service"
@@ -24,28 +32,70 @@ const (
 	idleTimeout  = 60 * time.Second
 )
 
+// pingPath is the liveness probe route, relative to the base path
+const pingPath = "/ping"
+
 type App struct {
-	config       *config.Config
-	router       *gin.Engine
-	logger       *logrus.Logger
-	server       *http.Server
-	db           *pgxpool.Pool
-	queue        *queue.RabbitMQ
-	worker       *worker.PostPublishWorker
-	workerCtx    context.Context
-	workerCancel context.CancelFunc
+	config        *config.Config
+	router        *gin.Engine
+	logger        *logrus.Logger
+	server        *http.Server
+	db            *pgxpool.Pool
+	replica       *pgxpool.Pool
+	queue         *queue.RabbitMQ
+	worker        *worker.PostPublishWorker
+	janitor       *worker.StaleDraftJanitor
+	accounts      *worker.InactiveAccountJanitor
+	broker        *events.Broker[domain.PostPublishedNotification]
+	brokerRelay   *queue.Relay[domain.PostPublishedNotification]
+	listCache     *cache.TTL[*domain.ListPostsResponse]
+	inbox         *events.Broker[domain.Notification]
+	inboxRelay    *queue.Relay[domain.Notification]
+	unreadCounts  *cache.TTL[*domain.UnreadNotificationsResponse]
+	passwords     password.Hasher
+	captcha       captcha.Verifier
+	featureFlags  *service.FeatureFlagService
+	workerCtx     context.Context
+	workerCancel  context.CancelFunc
+	httpStopped   bool
+	workerStopped bool
+	queueClosed   bool
+	dbClosed      bool
+	dependencies  domain.DependencyVersions
 }
 
 func New(cfg *config.Config) (*App, error) {
 	// Initialize logger
 	logger := initLogger(cfg.App.Environment)
 
+	// Initialize password hashing
+	passwordHasher, err := password.New(cfg.Users.PasswordAlgorithm)
+	if err != nil {
+		return nil, err
+	}
+
+	// Initialize CAPTCHA verification (a no-op unless a provider is set)
+	captchaVerifier, err := captcha.New(cfg.Captcha.Provider, cfg.Captcha.Secret, cfg.Captcha.Timeout)
+	if err != nil {
+		return nil, err
+	}
+
 	// Initialize database
 	db, err := database.NewPostgresPool(&cfg.Database)
 	if err != nil {
 		return nil, fmt.Errorf("failed to initialize database: %w", err)
 	}
 
+	// Initialize optional read replica
+	var replica *pgxpool.Pool
+	if cfg.ReadReplica != nil {
+		replica, err = database.NewPostgresPool(cfg.ReadReplica)
+		if err != nil {
+			db.Close()
+			return nil, fmt.Errorf("failed to initialize read replica: %w", err)
+		}
+	}
+
 	// Initialize RabbitMQ
 	queueCfg := &queue.Config{
 		Host:     cfg.RabbitMQ.Host,
@@ -56,12 +106,55 @@ func New(cfg *config.Config) (*App, error) {
 	}
 	rabbitMQ, err := queue.NewRabbitMQ(queueCfg, logger)
 	if err != nil {
+		if replica != nil {
+			replica.Close()
+		}
 		db.Close()
 		return nil, fmt.Errorf("failed to initialize RabbitMQ: %w", err)
 	}
 
+	// Log build and dependency versions once at startup
+	dependencies := dependencyVersions(db, rabbitMQ, logger)
+	buildInfo := version.Get()
+	logger.WithFields(logrus.Fields{
+		"version":         buildInfo.Version,
+		"commit":          buildInfo.Commit,
+		"buildTime":       buildInfo.BuildTime,
+		"goVersion":       buildInfo.GoVersion,
+		"databaseVersion": dependencies.Database,
+		"rabbitmqVersion": dependencies.RabbitMQ,
+	}).Info("Starting application")
+
+	// Initialize the broker for live publish notifications
+	broker := events.NewBroker[domain.PostPublishedNotification]()
+
+	// Initialize the broker for live user notifications
+	inbox := events.NewBroker[domain.Notification]()
+
+	// Initialize the post list cache (opt-in)
+	var listCache *cache.TTL[*domain.ListPostsResponse]
+	if cfg.Posts.ListCacheTTL > 0 {
+		listCache = cache.NewTTL[*domain.ListPostsResponse]("post_list", cfg.Posts.ListCacheTTL)
+	}
+
+	// Initialize the unread notification count cache
+	var unreadCounts *cache.TTL[*domain.UnreadNotificationsResponse]
+	if cfg.Notifications.UnreadCountCacheTTL > 0 {
+		unreadCounts = cache.NewTTL[*domain.UnreadNotificationsResponse]("notification_unread_count", cfg.Notifications.UnreadCountCacheTTL)
+	}
+
+	// Load feature flags; defaults apply until the first successful load
+	featureFlags := service.NewFeatureFlagService(repository.NewFeatureFlagRepository(db), logger)
+	if err := featureFlags.Refresh(context.Background()); err != nil {
+		logger.WithError(err).Warn("Failed to load feature flags, using defaults")
+	}
+
+	// Relay live notifications between instances through RabbitMQ
+	brokerRelay := queue.NewRelay(rabbitMQ, domain.ExchangePostPublished, broker, logger)
+	inboxRelay := queue.NewRelay(rabbitMQ, domain.ExchangeNotifications, inbox, logger)
+
 	// Initialize worker
-	postPublishWorker := worker.NewPostPublishWorker(rabbitMQ, db, logger)
+	postPublishWorker := worker.NewPostPublishWorker(rabbitMQ, db, logger, cfg.Worker.Concurrency, brokerRelay, listCache, inboxRelay, unreadCounts)
 
 	// Configure Gin mode
 	if cfg.App.Environment == "production" {
@@ -76,10 +169,29 @@ func New(cfg *config.Config) (*App, error) {
 		router:       gin.New(),
 		logger:       logger,
 		db:           db,
+		replica:      replica,
 		queue:        rabbitMQ,
 		worker:       postPublishWorker,
+		broker:       broker,
+		brokerRelay:  brokerRelay,
+		listCache:    listCache,
+		inbox:        inbox,
+		inboxRelay:   inboxRelay,
+		unreadCounts: unreadCounts,
+		passwords:    passwordHasher,
+		captcha:      captchaVerifier,
+		featureFlags: featureFlags,
 		workerCtx:    workerCtx,
 		workerCancel: workerCancel,
+		dependencies: dependencies,
+	}
+
+	// Only believe forwarded client addresses from known proxies
+	if len(cfg.Server.TrustedProxies) > 0 {
+		if err := app.router.SetTrustedProxies(cfg.Server.TrustedProxies); err != nil {
+			app.cleanup()
+			return nil, fmt.Errorf("failed to set trusted proxies: %w", err)
+		}
 	}
 
 	// Setup middleware
@@ -88,15 +200,62 @@ func New(cfg *config.Config) (*App, error) {
 	// Setup routes
 	app.setupRoutes()
 
+	app.setupServer()
+
 	// Start worker
 	if err := app.worker.Start(app.workerCtx); err != nil {
 		app.cleanup()
 		return nil, fmt.Errorf("failed to start worker: %w", err)
 	}
 
+	// Receive live notifications published on any instance
+	if err := app.brokerRelay.Start(); err != nil {
+		app.cleanup()
+		return nil, fmt.Errorf("failed to start post relay: %w", err)
+	}
+	if err := app.inboxRelay.Start(); err != nil {
+		app.cleanup()
+		return nil, fmt.Errorf("failed to start notification relay: %w", err)
+	}
+
+	// Keep feature flags in sync with changes made on other instances
+	app.featureFlags.StartRefresh(app.workerCtx, cfg.Features.RefreshInterval)
+
+	// Start stale draft janitor (opt-in)
+	if cfg.Posts.StaleDraftArchiveAfter > 0 {
+		postRepo := repository.NewPostRepository(db, replica)
+		app.janitor = worker.NewStaleDraftJanitor(postRepo, logger, cfg.Posts.StaleDraftArchiveAfter, cfg.Posts.StaleDraftCheckInterval)
+		app.janitor.Start(app.workerCtx)
+	}
+
+	// Start inactive account janitor (opt-in)
+	if cfg.Users.DeactivateInactiveAfter > 0 {
+		userRepo := repository.NewUserRepository(db)
+		app.accounts = worker.NewInactiveAccountJanitor(userRepo, logger, cfg.Users.DeactivateInactiveAfter, cfg.Users.InactiveCheckInterval, cfg.Users.DeactivateInactiveAdmins)
+		app.accounts.Start(app.workerCtx)
+	}
+
 	return app, nil
 }
 
+// dependencyVersions looks up the server versions of the database and broker.
+// Failures are logged rather than fatal since they're informational only.
+func dependencyVersions(db *pgxpool.Pool, rabbitMQ *queue.RabbitMQ, logger *logrus.Logger) domain.DependencyVersions {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	dbVersion, err := database.ServerVersion(ctx, db)
+	if err != nil {
+		logger.WithError(err).Warn("Could not determine database version")
+		dbVersion = "unknown"
+	}
+
+	return domain.DependencyVersions{
+		Database: dbVersion,
+		RabbitMQ: rabbitMQ.ServerVersion(),
+	}
+}
+
 func initLogger(env string) *logrus.Logger {
 	logger := logrus.New()
 	logger.SetFormatter(&logrus.JSONFormatter{
@@ -113,96 +272,295 @@ func initLogger(env string) *logrus.Logger {
 }
 
 func (a *App) setupMiddleware() {
-	// Recovery middleware
-	a.router.Use(gin.Recovery())
+	// Request ID middleware; first, so every response, including recovered
+	// panics, carries the ID in the configured header
+	a.router.Use(handler.RequestIDMiddleware(a.config.App.RequestIDHeader, a.config.App.RequestIDFromTraceparent))
+
+	// Recovery middleware; panics get an enveloped error response
+	a.router.Use(gin.CustomRecovery(handler.Recovered))
+
+	// Logger middleware; liveness probes are too frequent to log
+	a.router.Use(gin.LoggerWithConfig(gin.LoggerConfig{
+		SkipPaths: []string{a.config.Server.BasePath + pingPath},
+	}))
+
+	// Believe forwarded headers only from trusted proxies
+	a.router.Use(handler.TrustedProxyMiddleware(a.config.Server.TrustedProxies))
+
+	// Timestamp encoding for JSON responses
+	a.router.Use(handler.TimeFormatMiddleware(a.config.App.TimeFormat))
+
+	// CORS preflight middleware; route groups set headers for actual requests
+	publicCORS, protectedCORS := a.corsPolicies()
+	a.router.Use(handler.CORSPreflightMiddleware(publicCORS, protectedCORS))
+
+	// CSRF protection for requests authenticated by cookie
+	if a.config.JWT.Cookies.Enabled {
+		a.router.Use(handler.CSRFMiddleware(&a.config.JWT))
+	}
+
+	// Query debug middleware (never in production)
+	if a.config.App.DebugQueries && a.config.App.Environment != "production" {
+		a.router.Use(handler.QueryDebugMiddleware())
+	}
 
-	// Logger middleware
-	a.router.Use(gin.Logger())
+	// Body logging middleware (never in production)
+	if a.config.App.DebugBodies && a.config.App.Environment != "production" {
+		a.router.Use(handler.BodyLogMiddleware(a.logger, a.config.App.DebugBodyMaxBytes))
+	}
+}
+
+// corsPolicies returns the CORS policies for public and authenticated routes
+func (a *App) corsPolicies() (public, protected handler.CORSPolicy) {
+	public = handler.CORSPolicy{AllowedOrigins: a.config.CORS.PublicOrigins}
+	protected = handler.CORSPolicy{AllowedOrigins: a.config.CORS.ProtectedOrigins, AllowCredentials: true}
+	return public, protected
 }
 
 func (a *App) setupRoutes() {
 	// Initialize repositories
 	userRepo := repository.NewUserRepository(a.db)
 	authRepo := repository.NewAuthRepository(a.db)
-	postRepo := repository.NewPostRepository(a.db)
+	postRepo := repository.NewPostRepository(a.db, a.replica)
+	seriesRepo := repository.NewSeriesRepository(a.db)
+	statsRepo := repository.NewStatsRepository(a.db)
+	inviteRepo := repository.NewInviteRepository(a.db)
+	notificationRepo := repository.NewNotificationRepository(a.db)
 
 	// Initialize queue publisher
 	postPublisher := queue.NewPostPublisher(a.queue)
 
 	// Initialize services
-	authService := service.NewAuthService(userRepo, authRepo, &a.config.JWT)
-	userService := service.NewUserService(userRepo)
-	postService := service.NewPostService(postRepo, userRepo, postPublisher)
+	notificationService := service.NewNotificationService(notificationRepo, userRepo, a.inboxRelay, a.unreadCounts)
+	authService := service.NewAuthService(userRepo, authRepo, &a.config.JWT, &a.config.Users, a.featureFlags, inviteRepo, a.passwords, notificationService, a.captcha)
+	userService := service.NewUserService(userRepo, &a.config.Users)
+	postService := service.NewPostService(postRepo, userRepo, seriesRepo, postPublisher, a.config.App.SlugMaxLength, &a.config.Posts, a.listCache, a.brokerRelay, notificationService)
+	adminService := service.NewAdminService(statsRepo)
+	seriesService := service.NewSeriesService(seriesRepo, postRepo, userRepo, a.config.App.SlugMaxLength)
 
 	// Initialize handlers
-	healthHandler := handler.NewHealthHandler(a.db)
-	authHandler := handler.NewAuthHandler(authService)
+	healthHandler := handler.NewHealthHandler(a.db, a.dependencies)
+	authHandler := handler.NewAuthHandler(authService, a.config.Server.PublicURL, &a.config.JWT)
 	userHandler := handler.NewUserHandler(userService)
-	postHandler := handler.NewPostHandler(postService)
+	postHandler := handler.NewPostHandler(postService, a.config.Server.PublicURL)
+	seriesHandler := handler.NewSeriesHandler(seriesService)
+	notificationHandler := handler.NewNotificationHandler(notificationService, a.config.Server.PublicURL)
+	featureFlagHandler := handler.NewFeatureFlagHandler(a.featureFlags)
+	adminHandler := handler.NewAdminHandler(adminService)
+	utilsHandler := handler.NewUtilsHandler(a.config.App.SlugMaxLength)
+	eventsHandler := handler.NewEventsHandler(a.broker)
+
+	// Limit open streaming connections per client and overall
+	streamLimiter := handler.NewStreamLimiter(a.config.Streams.MaxPerClient, a.config.Streams.MaxConnections, a.config.Streams.RetryAfter)
+
+	// Reject oversized post bodies before they are read in full
+	postBodyLimit := handler.BodyLimitMiddleware(int64(a.config.Posts.MaxBodyBytes))
+
+	// Limit concurrent post writes per user
+	postWriteLimiter := handler.NewUserConcurrencyLimiter(a.config.Posts.MaxConcurrentWrites)
+
+	// Audit requests made with impersonation tokens
+	impersonationAudit := handler.ImpersonationAuditMiddleware(a.logger)
+
+	// CORS policies per route group
+	publicCORSPolicy, protectedCORSPolicy := a.corsPolicies()
+	publicCORS := handler.CORSMiddleware(publicCORSPolicy)
+	protectedCORS := handler.CORSMiddleware(protectedCORSPolicy)
+
+	// All routes live under the configured base path (empty by default)
+	base := a.router.Group(a.config.Server.BasePath)
+
+	// Liveness probe, with no dependency checks
+	base.GET(pingPath, healthHandler.Ping)
 
 	// Health check
-	a.router.GET("/health", healthHandler.HealthCheck)
+	base.GET("/health", healthHandler.HealthCheck)
+	base.GET("/health/ready", healthHandler.ReadinessCheck)
+
+	// Build info
+	base.GET("/version", healthHandler.Version)
+
+	// Prometheus metrics
+	base.GET("/metrics", gin.WrapH(metrics.Handler()))
 
 	// API v1 routes
-	v1 := a.router.Group("/api/v1")
+	v1 := base.Group("/api/v1")
 	{
 		// Public auth routes
 		auth := v1.Group("/auth")
+		auth.Use(protectedCORS)
 		{
 			auth.POST("/register", authHandler.Register)
 			auth.POST("/login", authHandler.Login)
 			auth.POST("/refresh", authHandler.RefreshToken)
+			auth.POST("/logout", authHandler.Logout)
 		}
 
-		// Public post routes
-		v1.GET("/posts", postHandler.ListPosts)
-		v1.GET("/posts/:id", postHandler.GetPost)
+		// Public post and profile routes (authentication is optional)
+		public := v1.Group("")
+		public.Use(publicCORS, handler.OptionalAuthMiddleware(&a.config.JWT), impersonationAudit)
+		{
+			public.GET("/posts", postHandler.ListPosts)
+			public.GET("/posts/:id", postHandler.GetPost)
+			public.POST("/users/batch", userHandler.GetUsersBatch)
+		}
+
+		// Public series routes
+		v1.GET("/series/:slug", publicCORS, seriesHandler.GetSeries)
+
+		// Utility routes
+		v1.GET("/utils/slugify", publicCORS, utilsHandler.Slugify)
+
+		// Live publish notifications (Server-Sent Events)
+		v1.GET("/events", publicCORS, streamLimiter.Middleware("events"), eventsHandler.Stream)
 
 		// Protected routes
 		protected := v1.Group("")
-		protected.Use(handler.AuthMiddleware(&a.config.JWT))
+		protected.Use(protectedCORS, handler.AuthMiddleware(&a.config.JWT), impersonationAudit)
 		{
 			// User routes
 			protected.GET("/me", userHandler.GetProfile)
 			protected.PUT("/me", userHandler.UpdateProfile)
+			protected.GET("/me/history", postHandler.ReadHistory)
+			protected.GET("/me/bookmarks", postHandler.ListBookmarks)
+			protected.GET("/me/stats", postHandler.GetMyStats)
+			protected.GET("/me/editable", postHandler.ListEditablePosts)
+			protected.GET("/me/posts/grouped", postHandler.ListMyPostsGrouped)
+
+			// Notification routes
+			protected.GET("/me/notifications", notificationHandler.ListNotifications)
+			protected.GET("/me/notifications/unread-count", notificationHandler.UnreadCount)
+			protected.GET("/me/notifications/stream", streamLimiter.Middleware("notifications"), notificationHandler.Stream)
+			protected.POST("/me/notifications/read-all", notificationHandler.MarkAllRead)
+			protected.POST("/me/notifications/:id/read", notificationHandler.MarkRead)
 
 			// Post routes
-			protected.POST("/posts", postHandler.CreatePost)
-			protected.PUT("/posts/:id", postHandler.UpdatePost)
+			protected.POST("/posts", postBodyLimit, postWriteLimiter.Middleware(), postHandler.CreatePost)
+			protected.PUT("/posts/:id", postBodyLimit, postWriteLimiter.Middleware(), postHandler.UpdatePost)
 			protected.DELETE("/posts/:id", postHandler.DeletePost)
+			protected.POST("/posts/interactions", postHandler.PostInteractions)
+			protected.POST("/posts/:id/read", postHandler.MarkRead)
+			protected.PUT("/posts/:id/progress", postHandler.SaveReadProgress)
+			protected.POST("/posts/:id/bookmark", postHandler.BookmarkPost)
+			protected.DELETE("/posts/:id/bookmark", postHandler.RemoveBookmark)
+
+			// Series routes
+			protected.POST("/series", seriesHandler.CreateSeries)
+			protected.POST("/series/:slug/posts", seriesHandler.AddPost)
+			protected.DELETE("/series/:slug/posts/:postId", seriesHandler.RemovePost)
+			protected.PUT("/series/:slug/order", seriesHandler.ReorderPosts)
+		}
+
+		// Admin routes
+		admin := v1.Group("/admin")
+		admin.Use(protectedCORS, handler.AuthMiddleware(&a.config.JWT), impersonationAudit, handler.RequireRole(domain.RoleAdmin), handler.AuditMiddleware(a.logger))
+		{
+			admin.GET("/stats", adminHandler.GetStats)
+			admin.POST("/posts/:id/publish", postHandler.ForcePublishPost)
+			admin.POST("/posts/:id/unpublish", postHandler.ForceUnpublishPost)
+			admin.POST("/posts/:id/transfer", postHandler.TransferPost)
+			admin.POST("/users/:id/impersonate", authHandler.Impersonate)
+			admin.GET("/sessions", authHandler.ListSessions)
+			admin.DELETE("/sessions/:id", authHandler.RevokeSession)
+			admin.GET("/feature-flags", featureFlagHandler.ListFlags)
+			admin.PUT("/feature-flags/:key", featureFlagHandler.SetFlag)
+			admin.POST("/invites", authHandler.CreateInvite)
 		}
 	}
 }
 
 func (a *App) Run() error {
-	addr := fmt.Sprintf("%s:%s", a.config.Server.Host, a.config.Server.Port)
-
 	a.logger.WithFields(logrus.Fields{
-		"address":     addr,
+		"address":     a.server.Addr,
 		"environment": a.config.App.Environment,
+		"tls":         a.config.Server.TLSEnabled(),
 	}).Info("Starting server")
 
-	// Create HTTP server
+	// HTTP/2 is negotiated automatically when serving TLS
+	if a.config.Server.TLSEnabled() {
+		return a.server.ListenAndServeTLS(a.config.Server.TLSCertFile, a.config.Server.TLSKeyFile)
+	}
+
+	return a.server.ListenAndServe()
+}
+
+// setupServer creates the HTTP server. It is built up front, rather than in
+// Run, so Shutdown never races with Run starting the server.
+func (a *App) setupServer() {
 	a.server = &http.Server{
-		Addr:         addr,
+		Addr:         fmt.Sprintf("%s:%s", a.config.Server.Host, a.config.Server.Port),
 		Handler:      a.router,
 		ReadTimeout:  readTimeout,
 		WriteTimeout: writeTimeout,
 		IdleTimeout:  idleTimeout,
 	}
 
-	return a.server.ListenAndServe()
+	// End event streams on shutdown; Shutdown doesn't interrupt active requests
+	a.server.RegisterOnShutdown(a.broker.Close)
+	a.server.RegisterOnShutdown(a.inbox.Close)
 }
 
+// Shutdown stops the application in a fixed order, so nothing is closed while
+// something else still uses it:
+//
+//  1. stop accepting connections and drain in-flight HTTP requests
+//  2. stop the background workers, letting in-flight events finish
+//  3. close the RabbitMQ connection
+//  4. close the read replica and primary database pools
+//
+// Each phase has its own budget from the server config, capped by ctx. A
+// phase that runs out of time is cut short (remaining HTTP connections are
+// closed) and shutdown moves on, so later phases always run. Every phase runs
+// at most once, so calling both Shutdown and Close is safe.
 func (a *App) Shutdown(ctx context.Context) error {
-	a.logger.Info("Shutting down server...")
+	a.logger.Info("Shutting down...")
+
+	serverCfg := a.config.Server
+	return errors.Join(
+		a.runShutdownPhase(ctx, "http", serverCfg.ShutdownHTTPTimeout, a.stopHTTP),
+		a.runShutdownPhase(ctx, "worker", serverCfg.ShutdownWorkerTimeout, a.stopWorker),
+		a.runShutdownPhase(ctx, "rabbitmq", serverCfg.ShutdownCloseTimeout, a.closeQueue),
+		a.runShutdownPhase(ctx, "database", serverCfg.ShutdownCloseTimeout, a.closeDatabases),
+	)
+}
+
+// Close runs any shutdown phases that haven't run yet, bounded by the
+// shutdown timeout
+func (a *App) Close() {
+	a.cleanup()
+}
 
-	if a.server == nil {
+func (a *App) cleanup() {
+	ctx, cancel := context.WithTimeout(context.Background(), a.config.Server.ShutdownTimeout)
+	defer cancel()
+
+	if err := a.Shutdown(ctx); err != nil {
+		a.logger.WithError(err).Error("Shutdown did not complete cleanly")
+	}
+}
+
+// runShutdownPhase runs one shutdown phase with its own budget
+func (a *App) runShutdownPhase(ctx context.Context, name string, budget time.Duration, phase func(context.Context) error) error {
+	ctx, cancel := context.WithTimeout(ctx, budget)
+	defer cancel()
+
+	if err := phase(ctx); err != nil {
+		a.logger.WithError(err).Errorf("Shutdown phase %q failed", name)
+		return fmt.Errorf("%s shutdown: %w", name, err)
+	}
+	return nil
+}
+
+// stopHTTP stops accepting connections and waits for in-flight requests.
+// Connections still open when ctx expires are closed.
+func (a *App) stopHTTP(ctx context.Context) error {
+	if a.server == nil || a.httpStopped {
 		return nil
 	}
+	a.httpStopped = true
 
 	if err := a.server.Shutdown(ctx); err != nil {
-		a.logger.WithError(err).Error("Server shutdown failed")
+		_ = a.server.Close()
 		return err
 	}
 
@@ -210,28 +568,90 @@ func (a *App) Shutdown(ctx context.Context) error {
 	return nil
 }
 
-func (a *App) Close() {
-	a.cleanup()
+// stopWorker cancels the background workers and waits for in-flight work until ctx expires
+func (a *App) stopWorker(ctx context.Context) error {
+	if a.workerCancel == nil || a.workerStopped {
+		return nil
+	}
+	a.workerStopped = true
+
+	a.workerCancel()
+	if err := a.worker.Wait(ctx); err != nil {
+		return err
+	}
+
+	if a.janitor != nil {
+		if err := a.janitor.Wait(ctx); err != nil {
+			return err
+		}
+	}
+
+	if a.accounts != nil {
+		if err := a.accounts.Wait(ctx); err != nil {
+			return err
+		}
+	}
+
+	a.logger.Info("Worker stopped")
+	return nil
 }
 
-func (a *App) cleanup() {
-	a.logger.Info("Cleaning up resources...")
+func (a *App) closeQueue(ctx context.Context) error {
+	if a.queue == nil || a.queueClosed {
+		return nil
+	}
+	a.queueClosed = true
+
+	if err := closeWithin(ctx, a.queue.Close); err != nil {
+		return err
+	}
+
+	a.logger.Info("RabbitMQ connection closed")
+	return nil
+}
 
-	// Stop worker
-	if a.workerCancel != nil {
-		a.workerCancel()
-		a.logger.Info("Worker stopped")
+func (a *App) closeDatabases(ctx context.Context) error {
+	if a.dbClosed {
+		return nil
 	}
+	a.dbClosed = true
 
-	// Close RabbitMQ
-	if a.queue != nil {
-		a.queue.Close()
-		a.logger.Info("RabbitMQ connection closed")
+	if a.replica != nil {
+		if err := closeWithin(ctx, closePool(a.replica)); err != nil {
+			return err
+		}
+		a.logger.Info("Read replica connection closed")
 	}
 
-	// Close database
 	if a.db != nil {
-		a.db.Close()
+		if err := closeWithin(ctx, closePool(a.db)); err != nil {
+			return err
+		}
 		a.logger.Info("Database connection closed")
 	}
+
+	return nil
+}
+
+// closeWithin runs close, returning early if ctx expires first. Closing may
+// block, e.g. a pool waits for acquired connections to be released.
+func closeWithin(ctx context.Context, close func() error) error {
+	done := make(chan error, 1)
+	go func() {
+		done <- close()
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func closePool(pool *pgxpool.Pool) func() error {
+	return func() error {
+		pool.Close()
+		return nil
+	}
 }
@@ -0,0 +1,175 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/saimonsiddique/blog-api/internal/config"
+	"github.com/saimonsiddique/blog-api/internal/worker"
+	"github.com/sirupsen/logrus"
+)
+
+// newShutdownTestApp returns an App serving handler on a local listener, with
+// the given shutdown budgets and no other dependencies
+func newShutdownTestApp(t *testing.T, handler http.Handler, server config.ServerConfig) (*App, string) {
+	t.Helper()
+
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+
+	a := &App{
+		config: &config.Config{Server: server},
+		logger: logger,
+		server: &http.Server{Handler: handler},
+	}
+	go a.server.Serve(listener)
+	t.Cleanup(func() { a.server.Close() })
+
+	return a, "http://" + listener.Addr().String()
+}
+
+func TestCloseWithinReturnsWhenContextExpires(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	release := make(chan struct{})
+	defer close(release)
+
+	start := time.Now()
+	err := closeWithin(ctx, func() error {
+		<-release
+		return nil
+	})
+
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("closeWithin() error = %v, want DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("closeWithin() took %v, want it to return when ctx expires", elapsed)
+	}
+}
+
+func TestCloseWithinReturnsCloseError(t *testing.T) {
+	errClose := errors.New("close failed")
+
+	err := closeWithin(context.Background(), func() error { return errClose })
+	if !errors.Is(err, errClose) {
+		t.Errorf("closeWithin() error = %v, want %v", err, errClose)
+	}
+}
+
+// A request that outlives the HTTP budget is cut off, and the later phases
+// still run within the overall budget
+func TestShutdownPhaseOverrunDoesNotBlockLaterPhases(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	started := make(chan struct{})
+
+	a, url := newShutdownTestApp(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+	}), config.ServerConfig{
+		ShutdownTimeout:       5 * time.Second,
+		ShutdownHTTPTimeout:   50 * time.Millisecond,
+		ShutdownWorkerTimeout: 50 * time.Millisecond,
+		ShutdownCloseTimeout:  50 * time.Millisecond,
+	})
+
+	go http.Get(url)
+	<-started
+
+	start := time.Now()
+	err := a.Shutdown(context.Background())
+
+	if err == nil || !strings.Contains(err.Error(), "http shutdown") {
+		t.Errorf("Shutdown() error = %v, want the http phase to fail", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Shutdown() took %v, want it bounded by the phase budgets", elapsed)
+	}
+	if !a.dbClosed {
+		t.Error("database phase did not run after the http phase overran")
+	}
+}
+
+// Shutdown drains in-flight requests before stopping the workers, and only
+// then closes the queue and databases
+func TestShutdownOrder(t *testing.T) {
+	workerCtx, workerCancel := context.WithCancel(context.Background())
+	started := make(chan struct{})
+	workerErrAtFinish := make(chan error, 1)
+
+	a, url := newShutdownTestApp(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		time.Sleep(100 * time.Millisecond)
+		workerErrAtFinish <- workerCtx.Err()
+		w.WriteHeader(http.StatusOK)
+	}), config.ServerConfig{
+		ShutdownTimeout:       5 * time.Second,
+		ShutdownHTTPTimeout:   2 * time.Second,
+		ShutdownWorkerTimeout: time.Second,
+		ShutdownCloseTimeout:  time.Second,
+	})
+	a.workerCtx = workerCtx
+	a.workerCancel = workerCancel
+	a.worker = worker.NewPostPublishWorker(nil, nil, a.logger, 1, nil, nil, nil, nil)
+
+	responses := make(chan int, 1)
+	go func() {
+		resp, err := http.Get(url)
+		if err != nil {
+			responses <- 0
+			return
+		}
+		resp.Body.Close()
+		responses <- resp.StatusCode
+	}()
+	<-started
+
+	if err := a.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+
+	if status := <-responses; status != http.StatusOK {
+		t.Errorf("in-flight request got status %d, want it drained with %d", status, http.StatusOK)
+	}
+	if err := <-workerErrAtFinish; err != nil {
+		t.Errorf("worker was stopped (%v) before the in-flight request finished", err)
+	}
+	if workerCtx.Err() == nil {
+		t.Error("worker was not stopped")
+	}
+	if !a.httpStopped || !a.workerStopped || !a.dbClosed {
+		t.Errorf("phases run (http, worker, database) = (%v, %v, %v), want all",
+			a.httpStopped, a.workerStopped, a.dbClosed)
+	}
+}
+
+// Shutdown can run more than once, and Close can follow it
+func TestShutdownIsIdempotent(t *testing.T) {
+	a, _ := newShutdownTestApp(t, http.NotFoundHandler(), config.ServerConfig{
+		ShutdownTimeout:       time.Second,
+		ShutdownHTTPTimeout:   time.Second,
+		ShutdownWorkerTimeout: time.Second,
+		ShutdownCloseTimeout:  time.Second,
+	})
+
+	if err := a.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+	if err := a.Shutdown(context.Background()); err != nil {
+		t.Errorf("second Shutdown() error = %v", err)
+	}
+	a.Close()
+}
@@ -0,0 +1,132 @@
+package cache
+
+import (
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/saimonsiddique/blog-api/internal/metrics"
+	"golang.org/x/sync/singleflight"
+)
+
+// maxEntries bounds the cache size. Expired entries are pruned once it is
+// reached, and the cache is emptied if that isn't enough.
+const maxEntries = 1024
+
+type entry[V any] struct {
+	value     V
+	expiresAt time.Time
+}
+
+// TTL is an in-memory cache whose entries expire a fixed time after being
+// stored. Concurrent misses for the same key share a single load, so an
+// expired hot key doesn't send a burst of identical queries to the database.
+//
+// A nil *TTL is a disabled cache: GetOrLoad always loads and Invalidate and
+// Delete do nothing.
+type TTL[V any] struct {
+	name       string
+	ttl        time.Duration
+	mu         sync.Mutex
+	entries    map[string]entry[V]
+	generation uint64
+	group      singleflight.Group
+}
+
+// NewTTL creates a cache named name, used as the metrics label, whose entries
+// live for ttl
+func NewTTL[V any](name string, ttl time.Duration) *TTL[V] {
+	return &TTL[V]{
+		name:    name,
+		ttl:     ttl,
+		entries: make(map[string]entry[V]),
+	}
+}
+
+// GetOrLoad returns the cached value for key, calling load on a miss. Values
+// are only cached when load succeeds.
+func (c *TTL[V]) GetOrLoad(key string, load func() (V, error)) (V, error) {
+	if c == nil {
+		return load()
+	}
+
+	c.mu.Lock()
+	e, ok := c.entries[key]
+	generation := c.generation
+	c.mu.Unlock()
+
+	if ok && time.Now().Before(e.expiresAt) {
+		metrics.CacheRequests.WithLabelValues(c.name, "hit").Inc()
+		return e.value, nil
+	}
+	metrics.CacheRequests.WithLabelValues(c.name, "miss").Inc()
+
+	// Loads started before an invalidation must not be shared with, or
+	// cached for, requests made after it
+	flightKey := strconv.FormatUint(generation, 10) + ":" + key
+
+	value, err, _ := c.group.Do(flightKey, func() (interface{}, error) {
+		value, err := load()
+		if err != nil {
+			return nil, err
+		}
+		c.store(key, value, generation)
+		return value, nil
+	})
+	if err != nil {
+		var zero V
+		return zero, err
+	}
+
+	return value.(V), nil
+}
+
+// Invalidate drops every cached entry
+func (c *TTL[V]) Invalidate() {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.generation++
+	clear(c.entries)
+}
+
+// Delete drops the cached entry for key. Loads already in flight for any key
+// are not cached, so a stale value for key can't be stored after it.
+func (c *TTL[V]) Delete(key string) {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.generation++
+	delete(c.entries, key)
+}
+
+func (c *TTL[V]) store(key string, value V, generation uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if generation != c.generation {
+		return
+	}
+
+	now := time.Now()
+	if len(c.entries) >= maxEntries {
+		for k, e := range c.entries {
+			if !now.Before(e.expiresAt) {
+				delete(c.entries, k)
+			}
+		}
+		if len(c.entries) >= maxEntries {
+			clear(c.entries)
+		}
+	}
+
+	c.entries[key] = entry[V]{value: value, expiresAt: now.Add(c.ttl)}
+}
@@ -2,44 +2,177 @@ package config
 
 import (
 	"fmt"
+	"net"
 	"os"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/joho/godotenv"
+	"github.com/saimonsiddique/blog-api/internal/pkg/captcha"
+	"github.com/saimonsiddique/blog-api/internal/pkg/excerpt"
+	"github.com/saimonsiddique/blog-api/internal/pkg/jsontime"
+	"github.com/saimonsiddique/blog-api/internal/pkg/password"
+	"github.com/saimonsiddique/blog-api/internal/pkg/requestid"
 )
 
 type Config struct {
-	Server   ServerConfig
-	Database DatabaseConfig
-	App      AppConfig
-	JWT      JWTConfig
-	RabbitMQ RabbitMQConfig
+	Server        ServerConfig
+	Database      DatabaseConfig
+	ReadReplica   *DatabaseConfig
+	App           AppConfig
+	JWT           JWTConfig
+	RabbitMQ      RabbitMQConfig
+	Worker        WorkerConfig
+	Posts         PostsConfig
+	Users         UsersConfig
+	CORS          CORSConfig
+	Features      FeaturesConfig
+	Notifications NotificationsConfig
+	Streams       StreamsConfig
+	Captcha       CaptchaConfig
 }
 
+// ServerConfig holds HTTP server settings.
+//
+// When TLSCertFile and TLSKeyFile are both set the server terminates TLS
+// itself and negotiates HTTP/2 with clients; otherwise it serves plain HTTP.
+// Redirecting HTTP to HTTPS is not handled here: run a small listener on
+// port 80 that responds with http.StatusMovedPermanently to the https URL,
+// or let the load balancer do it.
+//
+// BasePath prefixes every route, e.g. "/blog-api" when hosted behind a
+// path-based gateway. It is empty by default.
+//
+// PublicURL is the scheme and host clients reach the API on, e.g.
+// "https://api.example.com", used to build absolute links such as pagination
+// URLs. When empty, links are built from the incoming request's Host header,
+// or X-Forwarded-Proto and X-Forwarded-Host from a trusted proxy; set it in
+// production so clients can't choose the host.
+//
+// ShutdownTimeout caps the whole shutdown. Within it, each phase has its own
+// budget: ShutdownHTTPTimeout for draining in-flight requests,
+// ShutdownWorkerTimeout for background work to finish, and
+// ShutdownCloseTimeout for closing each connection (RabbitMQ, then the
+// databases).
+//
+// TrustedProxies lists the IPs or CIDRs of reverse proxies whose
+// X-Forwarded-For and X-Real-IP headers are believed when working out a
+// client's address, e.g. for session and last login records. When empty,
+// gin's default of trusting every proxy is kept for client addresses, but
+// X-Forwarded-Proto and X-Forwarded-Host are never believed.
 type ServerConfig struct {
-	Port string
-	Host string
+	Port                  string
+	Host                  string
+	TLSCertFile           string
+	TLSKeyFile            string
+	ShutdownTimeout       time.Duration
+	ShutdownHTTPTimeout   time.Duration
+	ShutdownWorkerTimeout time.Duration
+	ShutdownCloseTimeout  time.Duration
+	BasePath              string
+	PublicURL             string
+	TrustedProxies        []string
 }
 
+// TLSEnabled reports whether the server should serve HTTPS
+func (c *ServerConfig) TLSEnabled() bool {
+	return c.TLSCertFile != "" && c.TLSKeyFile != ""
+}
+
+// Query exec modes supported by pgx. See pgx.QueryExecMode for details.
+const (
+	QueryExecModeCacheStatement = "cache_statement"
+	QueryExecModeCacheDescribe  = "cache_describe"
+	QueryExecModeDescribeExec   = "describe_exec"
+	QueryExecModeExec           = "exec"
+	QueryExecModeSimpleProtocol = "simple_protocol"
+)
+
+// DatabaseConfig holds connection settings for a Postgres pool.
+//
+// QueryExecMode and StatementCacheCapacity control pgx's statement caching.
+// The default "cache_statement" mode prepares each query once per connection,
+// which saves parse overhead on hot queries but relies on server-side
+// prepared statements. PgBouncer in transaction mode can hand a later query
+// to a different backend where that statement doesn't exist, so with
+// PgBouncerTransactionMode set the mode must be "simple_protocol".
+//
+// StatementTimeout makes the server cancel any statement running longer,
+// bounding how long a runaway query holds a connection regardless of request
+// deadlines; zero leaves the server's setting in place. It is set per pool,
+// so the primary's applies to writes (and reads when there is no replica)
+// and the replica's to reads. It is sent as a connection startup parameter,
+// which PgBouncer rejects, so behind PgBouncer set statement_timeout on the
+// database role instead.
 type DatabaseConfig struct {
-	Host     string
-	Port     string
-	User     string
-	Password string
-	Name     string
-	SSLMode  string
+	Host                     string
+	Port                     string
+	User                     string
+	Password                 string
+	Name                     string
+	SSLMode                  string
+	QueryExecMode            string
+	StatementCacheCapacity   int
+	PgBouncerTransactionMode bool
+	StatementTimeout         time.Duration
 }
 
+// AppConfig holds general application settings.
+//
+// DebugBodies logs request and response bodies, with sensitive fields
+// redacted, for bodies up to DebugBodyMaxBytes. Like DebugQueries it is
+// refused in production.
+//
+// RequestIDHeader names the header request IDs are read from and echoed in,
+// e.g. "X-Correlation-ID" to match existing infrastructure. With
+// RequestIDFromTraceparent set, requests without that header take their ID
+// from the trace ID of a W3C traceparent header.
+//
+// TimeFormat is how timestamps are encoded in JSON responses: RFC3339
+// strings, or milliseconds since the Unix epoch for clients that prefer
+// numeric timestamps.
 type AppConfig struct {
-	Environment string
-	LogLevel    string
+	Environment              string
+	LogLevel                 string
+	DebugQueries             bool
+	DebugBodies              bool
+	DebugBodyMaxBytes        int
+	SlugMaxLength            int
+	RequestIDHeader          string
+	RequestIDFromTraceparent bool
+	TimeFormat               string
 }
 
+// JWTConfig holds token settings. ImpersonationTTL is the lifetime of the
+// access tokens admins are issued to act as another user. Cookies configures
+// the optional cookie-based auth mode.
 type JWTConfig struct {
-	Secret     string
-	Issuer     string
-	AccessTTL  time.Duration
-	RefreshTTL time.Duration
+	Secret           string
+	PreviousSecret   string
+	Issuer           string
+	AccessTTL        time.Duration
+	RefreshTTL       time.Duration
+	ImpersonationTTL time.Duration
+	Cookies          CookieAuthConfig
+}
+
+// CookieAuthConfig opts in to cookie-based auth for browser apps that can't
+// safely keep tokens in JavaScript. With Enabled set, login, registration and
+// refresh also set the tokens as HttpOnly cookies, leave them out of the
+// response body, and set a readable CSRF cookie. Requests authenticated by
+// cookie must echo that CSRF token in the X-CSRF-Token header on every
+// mutating request. Bearer tokens in the Authorization header keep working
+// and take precedence.
+//
+// Domain, Secure and SameSite ("lax", "strict" or "none") set the cookies'
+// attributes; SameSite "none" requires Secure, and cross-origin frontends
+// need CORS with credentials allowed.
+type CookieAuthConfig struct {
+	Enabled  bool
+	Domain   string
+	Secure   bool
+	SameSite string
 }
 
 type RabbitMQConfig struct {
@@ -50,14 +183,254 @@ type RabbitMQConfig struct {
 	Vhost    string
 }
 
+// PostsConfig holds post behaviour settings.
+//
+// With DefaultPublishedOnly set, listing posts without a status filter
+// returns only published posts. An explicit status filter still applies
+// as given, so this only changes the default, not who can see what.
+//
+// With ConcealUnpublished set, draft and archived posts are hidden from
+// everyone but their author: other users get POST_NOT_FOUND when reading,
+// updating or deleting them, and listings leave them out, so their existence
+// isn't revealed (admins can still read and list them). Published posts are public anyway, so non-authors get
+// FORBIDDEN when updating or deleting them. With it unset, unpublished posts
+// can be read by anyone and non-authors always get FORBIDDEN on writes.
+//
+// MaxConcurrentWrites caps how many create/update requests a single user can
+// have in flight at once.
+//
+// Content holds the length rules posts are checked against; see ContentPolicy.
+//
+// SanitizeHTML runs content and excerpts through an HTML sanitizer (the
+// bluemonday UGC policy) on create and update, for deployments whose clients
+// render posts as HTML. Leave it off for Markdown content, since the
+// sanitizer escapes characters such as "<" and "&".
+//
+// StaleDraftArchiveAfter opts in to a background job that archives drafts
+// not updated for that long, checked every StaleDraftCheckInterval. Zero
+// disables the job.
+//
+// With AutoExcerpt set, posts created without an excerpt get one generated
+// from their content using ExcerptStrategy ("chars", "words" or
+// "first-paragraph"). ExcerptLength is a word count for "words" and a
+// character count otherwise; either way, generated excerpts are cut to
+// Content.MaxExcerptLength characters. An excerpt given by the author always
+// wins.
+//
+// SlugMaxRetries bounds how many numbered variants ("-2", "-3", ...) are
+// tried when a new post's slug is taken before giving up with SLUG_TAKEN.
+//
+// ListCacheTTL opts in to caching anonymous, published-only post listings for
+// that long; zero disables the cache. Any post write empties it.
+// ListCacheBackend selects where entries live; only "memory" (per process) is
+// supported.
+//
+// DuplicateWindow opts in to rejecting a new post whose title and content
+// match a post the same author created within that window, catching double
+// submits that don't use idempotency keys. Zero disables the check.
+//
+// GroupedBucketSize caps how many of the most recently updated posts are
+// returned per status on the author's grouped posts view.
+//
+// MaxBodyBytes caps the request body of post create and update requests,
+// rejected before being read in full. It must leave room for content of
+// Content.MaxContentBytes plus the other fields and JSON escaping; both
+// limits are reported as PAYLOAD_TOO_LARGE.
+type PostsConfig struct {
+	DefaultPublishedOnly    bool
+	ConcealUnpublished      bool
+	MaxConcurrentWrites     int
+	Content                 ContentPolicy
+	SanitizeHTML            bool
+	StaleDraftArchiveAfter  time.Duration
+	StaleDraftCheckInterval time.Duration
+	AutoExcerpt             bool
+	ExcerptStrategy         string
+	ExcerptLength           int
+	SlugMaxRetries          int
+	ListCacheTTL            time.Duration
+	ListCacheBackend        string
+	DuplicateWindow         time.Duration
+	GroupedBucketSize       int
+	MaxBodyBytes            int
+}
+
+// ContentPolicy holds the length rules for post fields, checked by the post
+// service on create and update. Lengths count characters, not bytes, except
+// MaxContentBytes, which caps the stored size of content.
+//
+// MinContentLength applies whenever content is saved. With
+// DraftContentOptional set, drafts are exempt from it so they can be saved
+// with empty or short content for quick capture.
+//
+// PublishRequireExcerpt and PublishMinContentLength are quality gates checked
+// when a post is published; drafts are not affected. Both are off by
+// default so existing clients keep publishing as before. A minimum length of
+// 0 disables the content check, though published posts always need some
+// content.
+type ContentPolicy struct {
+	MinTitleLength          int
+	MaxTitleLength          int
+	MinContentLength        int
+	DraftContentOptional    bool
+	PublishMinContentLength int
+	PublishRequireExcerpt   bool
+	MaxExcerptLength        int
+	MaxContentBytes         int
+}
+
+// Validate checks the policy's limits are consistent
+func (p *ContentPolicy) Validate() error {
+	// Titles are stored in a VARCHAR(255) column
+	if p.MinTitleLength < 1 || p.MaxTitleLength > 255 || p.MinTitleLength > p.MaxTitleLength {
+		return fmt.Errorf("POSTS_MIN_TITLE_LENGTH and POSTS_MAX_TITLE_LENGTH must satisfy 1 <= min <= max <= 255")
+	}
+
+	if p.MinContentLength < 0 {
+		return fmt.Errorf("POSTS_MIN_CONTENT_LENGTH must not be negative")
+	}
+
+	if p.PublishMinContentLength < 0 {
+		return fmt.Errorf("POSTS_PUBLISH_MIN_CONTENT_LENGTH must not be negative")
+	}
+
+	if p.MaxExcerptLength < 1 {
+		return fmt.Errorf("POSTS_MAX_EXCERPT_LENGTH must be at least 1")
+	}
+
+	if p.MaxContentBytes < 1 {
+		return fmt.Errorf("POSTS_MAX_CONTENT_BYTES must be at least 1")
+	}
+
+	return nil
+}
+
+// UsersConfig holds user account settings.
+//
+// Emails are always matched case-insensitively. With NormalizeGmailAliases
+// set, dots and "+suffix" aliases in Gmail addresses are ignored as well, so
+// "J.Doe+blog@gmail.com" and "jdoe@gmail.com" are the same account.
+//
+// ReservedUsernames can't be registered or taken on profile update, so that
+// accounts don't shadow route segments or impersonate staff. Matching is
+// case-insensitive.
+//
+// With FirstUserIsAdmin set, the first account registered on an empty
+// database becomes an admin, so simple deployments don't need a seed step.
+//
+// PasswordAlgorithm ("bcrypt" or "argon2id") hashes new passwords. Existing
+// hashes made with the other algorithm, or older parameters, still verify
+// and are rehashed with the current settings on the user's next login.
+//
+// DeactivateInactiveAfter opts in to a background job that deactivates
+// accounts with no login for that long and deletes their refresh tokens,
+// checked every InactiveCheckInterval. Accounts that never logged in count
+// from registration. Admins are exempt unless DeactivateInactiveAdmins is
+// set. Zero disables the job.
+//
+// NewLoginAlerts opts in to warning users, through their notification inbox,
+// when their account is logged in to from a new device. A device is its user
+// agent plus the client IP's network, the first NewDeviceIPv4Prefix or
+// NewDeviceIPv6Prefix bits, so ordinary address churn within a network isn't
+// reported. The first device seen for a user is never reported.
+type UsersConfig struct {
+	NormalizeGmailAliases    bool
+	ReservedUsernames        []string
+	FirstUserIsAdmin         bool
+	PasswordAlgorithm        string
+	DeactivateInactiveAfter  time.Duration
+	InactiveCheckInterval    time.Duration
+	DeactivateInactiveAdmins bool
+	NewLoginAlerts           bool
+	NewDeviceIPv4Prefix      int
+	NewDeviceIPv6Prefix      int
+}
+
+// defaultReservedUsernames covers existing route segments and staff-like names
+var defaultReservedUsernames = []string{
+	"admin", "administrator", "api", "auth", "health", "me", "metrics",
+	"moderator", "posts", "root", "series", "staff", "support", "system",
+	"users", "utils", "version",
+}
+
+// IsReservedUsername reports whether username is on the reserved list
+func (c *UsersConfig) IsReservedUsername(username string) bool {
+	for _, reserved := range c.ReservedUsernames {
+		if strings.EqualFold(username, reserved) {
+			return true
+		}
+	}
+	return false
+}
+
+// CORSConfig holds the browser origins allowed per route group. Public read
+// routes use PublicOrigins; authenticated routes, which also allow
+// credentials, use ProtectedOrigins, which must list origins by name.
+// ProtectedOrigins defaults to PublicOrigins less any "*", and leaving both
+// empty disables CORS.
+type CORSConfig struct {
+	PublicOrigins    []string
+	ProtectedOrigins []string
+}
+
+// FeaturesConfig holds feature flag settings. Flags are cached in memory and
+// reloaded every RefreshInterval, so a change made on one instance reaches
+// the others within that time.
+type FeaturesConfig struct {
+	RefreshInterval time.Duration
+}
+
+// NotificationsConfig holds notification settings. UnreadCountCacheTTL
+// briefly caches each user's unread count, which clients poll frequently to
+// drive a notification badge; 0 disables the cache. A user's count is dropped
+// from the cache when they read notifications or are sent a new one on the
+// same instance, so other instances lag by at most the TTL.
+type NotificationsConfig struct {
+	UnreadCountCacheTTL time.Duration
+}
+
+// StreamsConfig caps open streaming connections, such as SSE streams.
+// MaxPerClient applies per user, or per IP address for anonymous clients, and
+// MaxConnections across all streams on the instance. Rejected clients are
+// told to retry after RetryAfter.
+type StreamsConfig struct {
+	MaxPerClient   int
+	MaxConnections int
+	RetryAfter     time.Duration
+}
+
+// CaptchaConfig opts in to requiring a solved CAPTCHA, sent as captchaToken,
+// to register and log in. Provider is "hcaptcha" or "recaptcha", or empty to
+// disable the check; Secret is the provider's server-side secret key.
+// Timeout bounds each verification request to the provider.
+type CaptchaConfig struct {
+	Provider string
+	Secret   string
+	Timeout  time.Duration
+}
+
+type WorkerConfig struct {
+	Concurrency int
+}
+
 func Load() (*Config, error) {
 	// Load .env file if exists (ignore error in production)
 	_ = godotenv.Load()
 
 	cfg := &Config{
 		Server: ServerConfig{
-			Port: getEnv("PORT", "8080"),
-			Host: getEnv("HOST", "0.0.0.0"),
+			Port:        getEnv("PORT", "8080"),
+			Host:        getEnv("HOST", "0.0.0.0"),
+			TLSCertFile: getEnv("TLS_CERT_FILE", ""),
+			TLSKeyFile:  getEnv("TLS_KEY_FILE", ""),
+
+			ShutdownTimeout:       getDuration("SHUTDOWN_TIMEOUT", 30*time.Second),
+			ShutdownHTTPTimeout:   getDuration("SHUTDOWN_HTTP_TIMEOUT", 15*time.Second),
+			ShutdownWorkerTimeout: getDuration("SHUTDOWN_WORKER_TIMEOUT", 10*time.Second),
+			ShutdownCloseTimeout:  getDuration("SHUTDOWN_CLOSE_TIMEOUT", 5*time.Second),
+			BasePath:              normalizeBasePath(getEnv("API_BASE_PATH", "")),
+			PublicURL:             strings.TrimRight(getEnv("PUBLIC_BASE_URL", ""), "/"),
+			TrustedProxies:        getList("TRUSTED_PROXIES", nil),
 		},
 		Database: DatabaseConfig{
 			Host:     getEnv("DB_HOST", "localhost"),
@@ -66,16 +439,40 @@ func Load() (*Config, error) {
 			Password: getEnv("DB_PASSWORD", ""),
 			Name:     getEnv("DB_NAME", "blog_api"),
 			SSLMode:  getEnv("DB_SSLMODE", "disable"),
+
+			QueryExecMode:            getEnv("DB_QUERY_EXEC_MODE", QueryExecModeCacheStatement),
+			StatementCacheCapacity:   getInt("DB_STATEMENT_CACHE_CAPACITY", 512),
+			PgBouncerTransactionMode: getBool("DB_PGBOUNCER_TRANSACTION_MODE", false),
+			StatementTimeout:         getDuration("DB_STATEMENT_TIMEOUT", 0),
 		},
 		App: AppConfig{
-			Environment: getEnv("APP_ENV", "development"),
-			LogLevel:    getEnv("LOG_LEVEL", "info"),
+			Environment:   getEnv("APP_ENV", "development"),
+			LogLevel:      getEnv("LOG_LEVEL", "info"),
+			DebugQueries:  getBool("DEBUG_QUERIES", false),
+			SlugMaxLength: getInt("SLUG_MAX_LENGTH", 100),
+
+			DebugBodies:       getBool("DEBUG_BODIES", false),
+			DebugBodyMaxBytes: getInt("DEBUG_BODY_MAX_BYTES", 8192),
+
+			RequestIDHeader:          getEnv("REQUEST_ID_HEADER", requestid.DefaultHeader),
+			RequestIDFromTraceparent: getBool("REQUEST_ID_FROM_TRACEPARENT", false),
+			TimeFormat:               getEnv("API_TIME_FORMAT", jsontime.FormatRFC3339),
 		},
 		JWT: JWTConfig{
-			Secret:     getEnv("JWT_SECRET", ""),
-			Issuer:     getEnv("JWT_ISSUER", "blog-api"),
-			AccessTTL:  getDuration("JWT_ACCESS_TTL", 15*time.Minute),
-			RefreshTTL: getDuration("JWT_REFRESH_TTL", 168*time.Hour),
+			Secret:         getEnv("JWT_SECRET", ""),
+			PreviousSecret: getEnv("JWT_SECRET_PREVIOUS", ""),
+			Issuer:         getEnv("JWT_ISSUER", "blog-api"),
+			AccessTTL:      getDuration("JWT_ACCESS_TTL", 15*time.Minute),
+			RefreshTTL:     getDuration("JWT_REFRESH_TTL", 168*time.Hour),
+
+			ImpersonationTTL: getDuration("JWT_IMPERSONATION_TTL", 15*time.Minute),
+
+			Cookies: CookieAuthConfig{
+				Enabled:  getBool("AUTH_COOKIE_MODE", false),
+				Domain:   getEnv("AUTH_COOKIE_DOMAIN", ""),
+				Secure:   getBool("AUTH_COOKIE_SECURE", true),
+				SameSite: strings.ToLower(getEnv("AUTH_COOKIE_SAMESITE", "lax")),
+			},
 		},
 		RabbitMQ: RabbitMQConfig{
 			Host:     getEnv("RABBITMQ_HOST", "localhost"),
@@ -84,6 +481,87 @@ func Load() (*Config, error) {
 			Password: getEnv("RABBITMQ_PASSWORD", "guest"),
 			Vhost:    getEnv("RABBITMQ_VHOST", "/"),
 		},
+		Worker: WorkerConfig{
+			Concurrency: getInt("WORKER_CONCURRENCY", 1),
+		},
+		Posts: PostsConfig{
+			DefaultPublishedOnly: getBool("POSTS_DEFAULT_PUBLISHED_ONLY", false),
+			ConcealUnpublished:   getBool("POSTS_CONCEAL_UNPUBLISHED", true),
+			MaxConcurrentWrites:  getInt("POSTS_MAX_CONCURRENT_WRITES", 3),
+
+			Content: ContentPolicy{
+				MinTitleLength:          getInt("POSTS_MIN_TITLE_LENGTH", 3),
+				MaxTitleLength:          getInt("POSTS_MAX_TITLE_LENGTH", 255),
+				MinContentLength:        getInt("POSTS_MIN_CONTENT_LENGTH", 10),
+				DraftContentOptional:    getBool("POSTS_DRAFT_CONTENT_OPTIONAL", false),
+				PublishMinContentLength: getInt("POSTS_PUBLISH_MIN_CONTENT_LENGTH", 0),
+				PublishRequireExcerpt:   getBool("POSTS_PUBLISH_REQUIRE_EXCERPT", false),
+				MaxExcerptLength:        getInt("POSTS_MAX_EXCERPT_LENGTH", 500),
+				MaxContentBytes:         getInt("POSTS_MAX_CONTENT_BYTES", 256*1024),
+			},
+			SanitizeHTML:            getBool("POSTS_SANITIZE_HTML", false),
+			StaleDraftArchiveAfter:  getDuration("POSTS_STALE_DRAFT_ARCHIVE_AFTER", 0),
+			StaleDraftCheckInterval: getDuration("POSTS_STALE_DRAFT_CHECK_INTERVAL", time.Hour),
+			AutoExcerpt:             getBool("POSTS_AUTO_EXCERPT", false),
+			ExcerptStrategy:         getEnv("POSTS_EXCERPT_STRATEGY", excerpt.StrategyChars),
+			ExcerptLength:           getInt("POSTS_EXCERPT_LENGTH", 200),
+			SlugMaxRetries:          getInt("POSTS_SLUG_MAX_RETRIES", 5),
+			ListCacheTTL:            getDuration("POSTS_LIST_CACHE_TTL", 0),
+			ListCacheBackend:        getEnv("POSTS_LIST_CACHE_BACKEND", "memory"),
+			DuplicateWindow:         getDuration("POSTS_DUPLICATE_WINDOW", 0),
+			GroupedBucketSize:       getInt("POSTS_GROUPED_BUCKET_SIZE", 20),
+			MaxBodyBytes:            getInt("POSTS_MAX_BODY_BYTES", 1024*1024),
+		},
+		Features: FeaturesConfig{
+			RefreshInterval: getDuration("FEATURE_FLAGS_REFRESH_INTERVAL", 30*time.Second),
+		},
+		Notifications: NotificationsConfig{
+			UnreadCountCacheTTL: getDuration("NOTIFICATIONS_UNREAD_COUNT_CACHE_TTL", 5*time.Second),
+		},
+		Streams: StreamsConfig{
+			MaxPerClient:   getInt("STREAMS_MAX_PER_CLIENT", 5),
+			MaxConnections: getInt("STREAMS_MAX_CONNECTIONS", 1000),
+			RetryAfter:     getDuration("STREAMS_RETRY_AFTER", 10*time.Second),
+		},
+		Captcha: CaptchaConfig{
+			Provider: getEnv("CAPTCHA_PROVIDER", captcha.ProviderNone),
+			Secret:   getEnv("CAPTCHA_SECRET", ""),
+			Timeout:  getDuration("CAPTCHA_TIMEOUT", 5*time.Second),
+		},
+		Users: UsersConfig{
+			NormalizeGmailAliases:    getBool("USERS_NORMALIZE_GMAIL_ALIASES", false),
+			ReservedUsernames:        getList("RESERVED_USERNAMES", defaultReservedUsernames),
+			FirstUserIsAdmin:         getBool("USERS_FIRST_USER_IS_ADMIN", false),
+			PasswordAlgorithm:        getEnv("PASSWORD_HASH_ALGORITHM", password.AlgorithmBcrypt),
+			DeactivateInactiveAfter:  getDuration("USERS_DEACTIVATE_INACTIVE_AFTER", 0),
+			InactiveCheckInterval:    getDuration("USERS_INACTIVE_CHECK_INTERVAL", time.Hour),
+			DeactivateInactiveAdmins: getBool("USERS_DEACTIVATE_INACTIVE_ADMINS", false),
+			NewLoginAlerts:           getBool("USERS_NEW_LOGIN_ALERTS", false),
+			NewDeviceIPv4Prefix:      getInt("USERS_NEW_DEVICE_IPV4_PREFIX", 24),
+			NewDeviceIPv6Prefix:      getInt("USERS_NEW_DEVICE_IPV6_PREFIX", 64),
+		},
+	}
+
+	cfg.CORS = CORSConfig{
+		PublicOrigins: getList("CORS_PUBLIC_ORIGINS", nil),
+	}
+	cfg.CORS.ProtectedOrigins = getList("CORS_PROTECTED_ORIGINS", withoutWildcard(cfg.CORS.PublicOrigins))
+
+	// Optional read replica; unset fields fall back to the primary's values
+	if replicaHost := getEnv("DB_REPLICA_HOST", ""); replicaHost != "" {
+		cfg.ReadReplica = &DatabaseConfig{
+			Host:     replicaHost,
+			Port:     getEnv("DB_REPLICA_PORT", cfg.Database.Port),
+			User:     getEnv("DB_REPLICA_USER", cfg.Database.User),
+			Password: getEnv("DB_REPLICA_PASSWORD", cfg.Database.Password),
+			Name:     getEnv("DB_REPLICA_NAME", cfg.Database.Name),
+			SSLMode:  getEnv("DB_REPLICA_SSLMODE", cfg.Database.SSLMode),
+
+			QueryExecMode:            cfg.Database.QueryExecMode,
+			StatementCacheCapacity:   cfg.Database.StatementCacheCapacity,
+			PgBouncerTransactionMode: cfg.Database.PgBouncerTransactionMode,
+			StatementTimeout:         getDuration("DB_REPLICA_STATEMENT_TIMEOUT", cfg.Database.StatementTimeout),
+		}
 	}
 
 	if err := cfg.Validate(); err != nil {
@@ -94,10 +572,56 @@ func Load() (*Config, error) {
 }
 
 func (c *Config) Validate() error {
+	if c.Server.ShutdownTimeout <= 0 {
+		return fmt.Errorf("SHUTDOWN_TIMEOUT must be positive")
+	}
+
+	if c.Server.ShutdownHTTPTimeout <= 0 || c.Server.ShutdownWorkerTimeout <= 0 || c.Server.ShutdownCloseTimeout <= 0 {
+		return fmt.Errorf("SHUTDOWN_HTTP_TIMEOUT, SHUTDOWN_WORKER_TIMEOUT and SHUTDOWN_CLOSE_TIMEOUT must be positive")
+	}
+
+	if (c.Server.TLSCertFile == "") != (c.Server.TLSKeyFile == "") {
+		return fmt.Errorf("TLS_CERT_FILE and TLS_KEY_FILE must be provided together")
+	}
+
+	switch c.JWT.Cookies.SameSite {
+	case "lax", "strict":
+	case "none":
+		if !c.JWT.Cookies.Secure {
+			return fmt.Errorf("AUTH_COOKIE_SAMESITE=none requires AUTH_COOKIE_SECURE")
+		}
+	default:
+		return fmt.Errorf("AUTH_COOKIE_SAMESITE must be \"lax\", \"strict\" or \"none\"")
+	}
+
+	for _, origin := range c.CORS.ProtectedOrigins {
+		if origin == "*" {
+			return fmt.Errorf("CORS_PROTECTED_ORIGINS can't contain \"*\", since authenticated routes allow credentials")
+		}
+	}
+
+	for _, proxy := range c.Server.TrustedProxies {
+		if net.ParseIP(proxy) == nil {
+			if _, _, err := net.ParseCIDR(proxy); err != nil {
+				return fmt.Errorf("TRUSTED_PROXIES entry %q is not an IP or CIDR", proxy)
+			}
+		}
+	}
+
 	if c.Database.Password == "" {
 		return fmt.Errorf("DB_PASSWORD is required")
 	}
 
+	if err := c.Database.validatePool(); err != nil {
+		return err
+	}
+
+	if c.ReadReplica != nil {
+		if err := c.ReadReplica.validatePool(); err != nil {
+			return err
+		}
+	}
+
 	if c.JWT.Secret == "" {
 		return fmt.Errorf("JWT_SECRET is required")
 	}
@@ -106,6 +630,170 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("JWT_SECRET must be at least 32 characters")
 	}
 
+	if c.JWT.PreviousSecret != "" && len(c.JWT.PreviousSecret) < 32 {
+		return fmt.Errorf("JWT_SECRET_PREVIOUS must be at least 32 characters")
+	}
+
+	if c.JWT.ImpersonationTTL <= 0 {
+		return fmt.Errorf("JWT_IMPERSONATION_TTL must be positive")
+	}
+
+	if c.JWT.PreviousSecret == c.JWT.Secret {
+		c.JWT.PreviousSecret = ""
+	}
+
+	if c.App.DebugQueries && c.App.Environment == "production" {
+		return fmt.Errorf("DEBUG_QUERIES cannot be enabled in production")
+	}
+
+	if c.App.DebugBodies && c.App.Environment == "production" {
+		return fmt.Errorf("DEBUG_BODIES cannot be enabled in production")
+	}
+
+	if strings.TrimSpace(c.App.RequestIDHeader) == "" {
+		return fmt.Errorf("REQUEST_ID_HEADER must not be empty")
+	}
+
+	if !jsontime.IsValidFormat(c.App.TimeFormat) {
+		return fmt.Errorf("API_TIME_FORMAT must be %q or %q", jsontime.FormatRFC3339, jsontime.FormatUnixMillis)
+	}
+
+	if c.App.DebugBodyMaxBytes < 1 {
+		return fmt.Errorf("DEBUG_BODY_MAX_BYTES must be at least 1")
+	}
+
+	// Slugs are stored in VARCHAR(255) columns
+	if c.App.SlugMaxLength < 1 || c.App.SlugMaxLength > 255 {
+		return fmt.Errorf("SLUG_MAX_LENGTH must be between 1 and 255")
+	}
+
+	if c.Features.RefreshInterval <= 0 {
+		return fmt.Errorf("FEATURE_FLAGS_REFRESH_INTERVAL must be positive")
+	}
+
+	if !password.IsValidAlgorithm(c.Users.PasswordAlgorithm) {
+		return fmt.Errorf("PASSWORD_HASH_ALGORITHM must be %q or %q", password.AlgorithmBcrypt, password.AlgorithmArgon2id)
+	}
+
+	if c.Users.DeactivateInactiveAfter < 0 {
+		return fmt.Errorf("USERS_DEACTIVATE_INACTIVE_AFTER must not be negative")
+	}
+
+	if c.Users.DeactivateInactiveAfter > 0 && c.Users.InactiveCheckInterval <= 0 {
+		return fmt.Errorf("USERS_INACTIVE_CHECK_INTERVAL must be positive")
+	}
+
+	if c.Users.NewDeviceIPv4Prefix < 0 || c.Users.NewDeviceIPv4Prefix > 32 {
+		return fmt.Errorf("USERS_NEW_DEVICE_IPV4_PREFIX must be between 0 and 32")
+	}
+
+	if c.Users.NewDeviceIPv6Prefix < 0 || c.Users.NewDeviceIPv6Prefix > 128 {
+		return fmt.Errorf("USERS_NEW_DEVICE_IPV6_PREFIX must be between 0 and 128")
+	}
+
+	if c.Notifications.UnreadCountCacheTTL < 0 {
+		return fmt.Errorf("NOTIFICATIONS_UNREAD_COUNT_CACHE_TTL must not be negative")
+	}
+
+	if c.Streams.MaxPerClient < 1 || c.Streams.MaxConnections < 1 {
+		return fmt.Errorf("STREAMS_MAX_PER_CLIENT and STREAMS_MAX_CONNECTIONS must be at least 1")
+	}
+
+	if c.Streams.RetryAfter < time.Second {
+		return fmt.Errorf("STREAMS_RETRY_AFTER must be at least 1s")
+	}
+
+	if !captcha.IsValidProvider(c.Captcha.Provider) {
+		return fmt.Errorf("CAPTCHA_PROVIDER %q is not supported", c.Captcha.Provider)
+	}
+
+	if c.Captcha.Provider != captcha.ProviderNone && (c.Captcha.Secret == "" || c.Captcha.Timeout <= 0) {
+		return fmt.Errorf("CAPTCHA_SECRET and a positive CAPTCHA_TIMEOUT are required when CAPTCHA_PROVIDER is set")
+	}
+
+	if c.Posts.MaxConcurrentWrites < 1 {
+		return fmt.Errorf("POSTS_MAX_CONCURRENT_WRITES must be at least 1")
+	}
+
+	if err := c.Posts.Content.Validate(); err != nil {
+		return err
+	}
+
+	if c.Posts.StaleDraftArchiveAfter < 0 {
+		return fmt.Errorf("POSTS_STALE_DRAFT_ARCHIVE_AFTER must not be negative")
+	}
+
+	if c.Posts.StaleDraftArchiveAfter > 0 && c.Posts.StaleDraftCheckInterval <= 0 {
+		return fmt.Errorf("POSTS_STALE_DRAFT_CHECK_INTERVAL must be positive")
+	}
+
+	if !excerpt.ValidStrategy(c.Posts.ExcerptStrategy) {
+		return fmt.Errorf("POSTS_EXCERPT_STRATEGY %q is not supported", c.Posts.ExcerptStrategy)
+	}
+
+	if c.Posts.ExcerptLength < 1 {
+		return fmt.Errorf("POSTS_EXCERPT_LENGTH must be at least 1")
+	}
+
+	if c.Posts.SlugMaxRetries < 0 {
+		return fmt.Errorf("POSTS_SLUG_MAX_RETRIES must not be negative")
+	}
+
+	if c.Posts.ListCacheTTL < 0 {
+		return fmt.Errorf("POSTS_LIST_CACHE_TTL must not be negative")
+	}
+
+	if c.Posts.ListCacheBackend != "memory" {
+		return fmt.Errorf("POSTS_LIST_CACHE_BACKEND %q is not supported; use \"memory\"", c.Posts.ListCacheBackend)
+	}
+
+	if c.Posts.DuplicateWindow < 0 {
+		return fmt.Errorf("POSTS_DUPLICATE_WINDOW must not be negative")
+	}
+
+	if c.Posts.GroupedBucketSize < 1 || c.Posts.GroupedBucketSize > 100 {
+		return fmt.Errorf("POSTS_GROUPED_BUCKET_SIZE must be between 1 and 100")
+	}
+
+	if c.Posts.MaxBodyBytes <= c.Posts.Content.MaxContentBytes {
+		return fmt.Errorf("POSTS_MAX_BODY_BYTES must be greater than POSTS_MAX_CONTENT_BYTES (%d)", c.Posts.Content.MaxContentBytes)
+	}
+
+	if c.Worker.Concurrency < 1 {
+		return fmt.Errorf("WORKER_CONCURRENCY must be at least 1")
+	}
+
+	return nil
+}
+
+func (c *DatabaseConfig) validatePool() error {
+	switch c.QueryExecMode {
+	case QueryExecModeCacheStatement, QueryExecModeCacheDescribe, QueryExecModeDescribeExec,
+		QueryExecModeExec, QueryExecModeSimpleProtocol:
+	default:
+		return fmt.Errorf("DB_QUERY_EXEC_MODE %q is not supported", c.QueryExecMode)
+	}
+
+	if c.StatementCacheCapacity < 0 {
+		return fmt.Errorf("DB_STATEMENT_CACHE_CAPACITY must not be negative")
+	}
+
+	if c.QueryExecMode == QueryExecModeCacheStatement && c.StatementCacheCapacity == 0 {
+		return fmt.Errorf("DB_QUERY_EXEC_MODE %q requires a non-zero DB_STATEMENT_CACHE_CAPACITY", c.QueryExecMode)
+	}
+
+	if c.PgBouncerTransactionMode && c.QueryExecMode != QueryExecModeSimpleProtocol {
+		return fmt.Errorf("DB_PGBOUNCER_TRANSACTION_MODE requires DB_QUERY_EXEC_MODE %q", QueryExecModeSimpleProtocol)
+	}
+
+	if c.StatementTimeout < 0 {
+		return fmt.Errorf("DB_STATEMENT_TIMEOUT and DB_REPLICA_STATEMENT_TIMEOUT must not be negative")
+	}
+
+	if c.PgBouncerTransactionMode && c.StatementTimeout > 0 {
+		return fmt.Errorf("DB_STATEMENT_TIMEOUT is not supported with DB_PGBOUNCER_TRANSACTION_MODE; set statement_timeout on the database role instead")
+	}
+
 	return nil
 }
 
@@ -116,6 +804,16 @@ func (c *DatabaseConfig) DSN() string {
 	)
 }
 
+// normalizeBasePath returns the path with a leading slash and no trailing
+// slash, or an empty string for the root
+func normalizeBasePath(path string) string {
+	path = strings.Trim(path, "/")
+	if path == "" {
+		return ""
+	}
+	return "/" + path
+}
+
 func getEnv(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
 		return value
@@ -136,3 +834,59 @@ func getDuration(key string, defaultValue time.Duration) time.Duration {
 
 	return duration
 }
+
+func getInt(key string, defaultValue int) int {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return defaultValue
+	}
+
+	return n
+}
+
+func getBool(key string, defaultValue bool) bool {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return defaultValue
+	}
+
+	return b
+}
+
+// getList reads a comma-separated list, dropping empty entries
+// withoutWildcard returns the origins other than "*"
+func withoutWildcard(origins []string) []string {
+	var listed []string
+	for _, origin := range origins {
+		if origin != "*" {
+			listed = append(listed, origin)
+		}
+	}
+	return listed
+}
+
+func getList(key string, defaultValue []string) []string {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	var list []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+
+	return list
+}
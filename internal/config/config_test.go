@@ -0,0 +1,174 @@
+package config
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestLoadStatementTimeouts(t *testing.T) {
+	tests := []struct {
+		name        string
+		env         map[string]string
+		wantPrimary time.Duration
+		wantReplica time.Duration
+		wantErr     bool
+	}{
+		{name: "unset", env: map[string]string{}},
+		{
+			name:        "primary only",
+			env:         map[string]string{"DB_STATEMENT_TIMEOUT": "5s"},
+			wantPrimary: 5 * time.Second,
+		},
+		{
+			name:        "replica inherits the primary's",
+			env:         map[string]string{"DB_STATEMENT_TIMEOUT": "5s", "DB_REPLICA_HOST": "replica"},
+			wantPrimary: 5 * time.Second,
+			wantReplica: 5 * time.Second,
+		},
+		{
+			name: "separate read timeout",
+			env: map[string]string{
+				"DB_STATEMENT_TIMEOUT":         "5s",
+				"DB_REPLICA_HOST":              "replica",
+				"DB_REPLICA_STATEMENT_TIMEOUT": "30s",
+			},
+			wantPrimary: 5 * time.Second,
+			wantReplica: 30 * time.Second,
+		},
+		{
+			name: "unparseable value ignored",
+			env:  map[string]string{"DB_STATEMENT_TIMEOUT": "soon"},
+		},
+		{
+			name:    "negative",
+			env:     map[string]string{"DB_STATEMENT_TIMEOUT": "-1s"},
+			wantErr: true,
+		},
+		{
+			name:    "negative replica",
+			env:     map[string]string{"DB_REPLICA_HOST": "replica", "DB_REPLICA_STATEMENT_TIMEOUT": "-1s"},
+			wantErr: true,
+		},
+		{
+			name: "behind pgbouncer",
+			env: map[string]string{
+				"DB_STATEMENT_TIMEOUT":          "5s",
+				"DB_PGBOUNCER_TRANSACTION_MODE": "true",
+				"DB_QUERY_EXEC_MODE":            QueryExecModeSimpleProtocol,
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("DB_PASSWORD", "secret")
+			t.Setenv("JWT_SECRET", "0123456789abcdef0123456789abcdef")
+			for _, key := range []string{
+				"DB_STATEMENT_TIMEOUT", "DB_REPLICA_HOST", "DB_REPLICA_STATEMENT_TIMEOUT",
+				"DB_PGBOUNCER_TRANSACTION_MODE", "DB_QUERY_EXEC_MODE",
+			} {
+				t.Setenv(key, tt.env[key])
+			}
+
+			cfg, err := Load()
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("Load() error = nil, want an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+
+			if cfg.Database.StatementTimeout != tt.wantPrimary {
+				t.Errorf("primary StatementTimeout = %v, want %v", cfg.Database.StatementTimeout, tt.wantPrimary)
+			}
+
+			var replica time.Duration
+			if cfg.ReadReplica != nil {
+				replica = cfg.ReadReplica.StatementTimeout
+			}
+			if replica != tt.wantReplica {
+				t.Errorf("replica StatementTimeout = %v, want %v", replica, tt.wantReplica)
+			}
+		})
+	}
+}
+
+func TestLoadCORSOrigins(t *testing.T) {
+	tests := []struct {
+		name          string
+		public        string
+		protected     string
+		wantProtected []string
+		wantErr       bool
+	}{
+		{name: "unset"},
+		{
+			name:          "protected inherits public",
+			public:        "https://a.example.com,https://b.example.com",
+			wantProtected: []string{"https://a.example.com", "https://b.example.com"},
+		},
+		{
+			name:          "protected doesn't inherit a wildcard",
+			public:        "*,https://a.example.com",
+			wantProtected: []string{"https://a.example.com"},
+		},
+		{name: "public wildcard only", public: "*"},
+		{
+			name:          "separate protected origins",
+			public:        "*",
+			protected:     "https://app.example.com",
+			wantProtected: []string{"https://app.example.com"},
+		},
+		{name: "protected wildcard", protected: "*", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("DB_PASSWORD", "secret")
+			t.Setenv("JWT_SECRET", "0123456789abcdef0123456789abcdef")
+			t.Setenv("CORS_PUBLIC_ORIGINS", tt.public)
+			t.Setenv("CORS_PROTECTED_ORIGINS", tt.protected)
+
+			cfg, err := Load()
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("Load() error = nil, want an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+
+			if !slices.Equal(cfg.CORS.ProtectedOrigins, tt.wantProtected) {
+				t.Errorf("ProtectedOrigins = %q, want %q", cfg.CORS.ProtectedOrigins, tt.wantProtected)
+			}
+		})
+	}
+}
+
+// The publish gates are opt-in so upgrading doesn't start rejecting posts
+// that published before
+func TestLoadPublishGatesOffByDefault(t *testing.T) {
+	t.Setenv("DB_PASSWORD", "secret")
+	t.Setenv("JWT_SECRET", "0123456789abcdef0123456789abcdef")
+	t.Setenv("POSTS_PUBLISH_MIN_CONTENT_LENGTH", "")
+	t.Setenv("POSTS_PUBLISH_REQUIRE_EXCERPT", "")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	if got := cfg.Posts.Content.PublishMinContentLength; got != 0 {
+		t.Errorf("PublishMinContentLength = %d, want 0", got)
+	}
+	if cfg.Posts.Content.PublishRequireExcerpt {
+		t.Error("PublishRequireExcerpt = true, want false")
+	}
+}
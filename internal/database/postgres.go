@@ -3,8 +3,10 @@ package database
 import (
 	"context"
 	"fmt"
+	"strconv"
 	"time"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/saimonsiddique/blog-api/internal/config"
 )
@@ -18,6 +20,14 @@ const (
 	connectionTimeout = 5 * time.Second
 )
 
+var queryExecModes = map[string]pgx.QueryExecMode{
+	config.QueryExecModeCacheStatement: pgx.QueryExecModeCacheStatement,
+	config.QueryExecModeCacheDescribe:  pgx.QueryExecModeCacheDescribe,
+	config.QueryExecModeDescribeExec:   pgx.QueryExecModeDescribeExec,
+	config.QueryExecModeExec:           pgx.QueryExecModeExec,
+	config.QueryExecModeSimpleProtocol: pgx.QueryExecModeSimpleProtocol,
+}
+
 func NewPostgresPool(cfg *config.DatabaseConfig) (*pgxpool.Pool, error) {
 	dsn := fmt.Sprintf(
 		"postgresql://%s:%s@%s:%s/%s?sslmode=%s",
@@ -44,6 +54,19 @@ func NewPostgresPool(cfg *config.DatabaseConfig) (*pgxpool.Pool, error) {
 	poolConfig.MaxConnIdleTime = maxConnIdleTime
 	poolConfig.HealthCheckPeriod = healthCheckPeriod
 
+	// Statement caching settings
+	execMode, ok := queryExecModes[cfg.QueryExecMode]
+	if !ok {
+		return nil, fmt.Errorf("unsupported query exec mode: %s", cfg.QueryExecMode)
+	}
+	poolConfig.ConnConfig.DefaultQueryExecMode = execMode
+	poolConfig.ConnConfig.StatementCacheCapacity = cfg.StatementCacheCapacity
+
+	setStatementTimeout(poolConfig.ConnConfig, cfg.StatementTimeout)
+
+	// Query tracing is a no-op unless the request context collects stats
+	poolConfig.ConnConfig.Tracer = &QueryTracer{}
+
 	// Create connection pool with timeout
 	ctx, cancel := context.WithTimeout(context.Background(), connectionTimeout)
 	defer cancel()
@@ -61,3 +84,20 @@ func NewPostgresPool(cfg *config.DatabaseConfig) (*pgxpool.Pool, error) {
 
 	return pool, nil
 }
+
+// setStatementTimeout has the server cancel statements running longer than
+// timeout on connections made with cc. Zero leaves the server's setting.
+func setStatementTimeout(cc *pgx.ConnConfig, timeout time.Duration) {
+	if timeout > 0 {
+		cc.RuntimeParams["statement_timeout"] = strconv.FormatInt(timeout.Milliseconds(), 10)
+	}
+}
+
+// ServerVersion returns the version string reported by the database server
+func ServerVersion(ctx context.Context, pool *pgxpool.Pool) (string, error) {
+	var version string
+	if err := pool.QueryRow(ctx, `SELECT version()`).Scan(&version); err != nil {
+		return "", err
+	}
+	return version, nil
+}
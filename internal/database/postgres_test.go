@@ -0,0 +1,62 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/saimonsiddique/blog-api/internal/database/dbtest"
+)
+
+func TestSetStatementTimeout(t *testing.T) {
+	tests := []struct {
+		name    string
+		timeout time.Duration
+		want    string
+		wantSet bool
+	}{
+		{name: "zero leaves the server setting", timeout: 0},
+		{name: "milliseconds", timeout: 1500 * time.Millisecond, want: "1500", wantSet: true},
+		{name: "seconds", timeout: 30 * time.Second, want: "30000", wantSet: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cc, err := pgx.ParseConfig("postgres://user@localhost/db")
+			if err != nil {
+				t.Fatalf("failed to parse config: %v", err)
+			}
+
+			setStatementTimeout(cc, tt.timeout)
+
+			got, ok := cc.RuntimeParams["statement_timeout"]
+			if ok != tt.wantSet || got != tt.want {
+				t.Errorf("statement_timeout = (%q, %v), want (%q, %v)", got, ok, tt.want, tt.wantSet)
+			}
+		})
+	}
+}
+
+func TestStatementTimeoutCancelsSlowQuery(t *testing.T) {
+	cfg := dbtest.Config(t)
+	setStatementTimeout(cfg.ConnConfig, 100*time.Millisecond)
+	pool := dbtest.Open(t, cfg)
+
+	start := time.Now()
+	_, err := pool.Exec(context.Background(), `SELECT pg_sleep(5)`)
+
+	// 57014 is query_canceled
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) || pgErr.Code != "57014" {
+		t.Fatalf("slow query error = %v, want a statement timeout", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("slow query ran for %v, want it cancelled after about 100ms", elapsed)
+	}
+	if IsConnectionError(err) {
+		t.Error("statement timeout reported as a connection error")
+	}
+}
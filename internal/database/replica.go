@@ -0,0 +1,30 @@
+package database
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type usePrimaryKey struct{}
+
+// WithPrimary marks the context so reads are served by the primary, e.g. to
+// read back a row right after writing it without hitting replication lag.
+func WithPrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, usePrimaryKey{}, true)
+}
+
+// UsePrimary reports whether reads for the context must go to the primary
+func UsePrimary(ctx context.Context) bool {
+	usePrimary, _ := ctx.Value(usePrimaryKey{}).(bool)
+	return usePrimary
+}
+
+// ReadPool returns the pool to use for a read-only query: the replica when one
+// is configured and the context doesn't require the primary.
+func ReadPool(ctx context.Context, primary, replica *pgxpool.Pool) *pgxpool.Pool {
+	if replica == nil || UsePrimary(ctx) {
+		return primary
+	}
+	return replica
+}
@@ -0,0 +1,64 @@
+package database
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+type queryStatsKey struct{}
+
+type queryStartKey struct{}
+
+// QueryStats accumulates the queries executed within a single context
+type QueryStats struct {
+	mu       sync.Mutex
+	count    int
+	duration time.Duration
+}
+
+// WithQueryStats returns a context that collects query statistics
+func WithQueryStats(ctx context.Context) (context.Context, *QueryStats) {
+	stats := &QueryStats{}
+	return context.WithValue(ctx, queryStatsKey{}, stats), stats
+}
+
+// Snapshot returns the number of queries executed and their total duration
+func (s *QueryStats) Snapshot() (int, time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.count, s.duration
+}
+
+func (s *QueryStats) record(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.count++
+	s.duration += d
+}
+
+// QueryTracer records query timings into the QueryStats attached to the context, if any
+type QueryTracer struct{}
+
+func (t *QueryTracer) TraceQueryStart(ctx context.Context, _ *pgx.Conn, _ pgx.TraceQueryStartData) context.Context {
+	if _, ok := ctx.Value(queryStatsKey{}).(*QueryStats); !ok {
+		return ctx
+	}
+	return context.WithValue(ctx, queryStartKey{}, time.Now())
+}
+
+func (t *QueryTracer) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, _ pgx.TraceQueryEndData) {
+	stats, ok := ctx.Value(queryStatsKey{}).(*QueryStats)
+	if !ok {
+		return
+	}
+
+	start, ok := ctx.Value(queryStartKey{}).(time.Time)
+	if !ok {
+		return
+	}
+
+	stats.record(time.Since(start))
+}
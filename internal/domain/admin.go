@@ -0,0 +1,35 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// UserCounts represents platform user totals
+type UserCounts struct {
+	Total  int `json:"total"`
+	Active int `json:"active"`
+}
+
+// PublishedCounts represents the number of posts published in recent windows
+type PublishedCounts struct {
+	Last7Days  int `json:"last7Days"`
+	Last30Days int `json:"last30Days"`
+}
+
+// TopAuthor represents an author ranked by post count
+type TopAuthor struct {
+	UUID      uuid.UUID `json:"uuid"`
+	Username  string    `json:"username"`
+	PostCount int       `json:"postCount"`
+}
+
+// AdminStatsResponse represents platform-wide statistics for admins
+type AdminStatsResponse struct {
+	Users       UserCounts       `json:"users"`
+	Posts       PostStatusCounts `json:"posts"`
+	Published   PublishedCounts  `json:"published"`
+	TopAuthors  []TopAuthor      `json:"topAuthors"`
+	GeneratedAt time.Time        `json:"generatedAt"`
+}
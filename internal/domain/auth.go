@@ -14,18 +14,93 @@ type RefreshToken struct {
 	CreatedAt time.Time `json:"createdAt"`
 }
 
+// AuthResponse carries a new token pair. In cookie auth mode the tokens are
+// set as cookies instead, and CSRFToken is the value to send in the
+// X-CSRF-Token header.
 type AuthResponse struct {
-	AccessToken  string        `json:"accessToken"`
-	RefreshToken string        `json:"refreshToken"`
+	AccessToken  string        `json:"accessToken,omitempty"`
+	RefreshToken string        `json:"refreshToken,omitempty"`
+	CSRFToken    string        `json:"csrfToken,omitempty"`
 	ExpiresIn    int           `json:"expiresIn"`
 	User         *UserResponse `json:"user"`
 }
 
+// ImpersonationResponse carries a short-lived access token for acting as
+// another user. No refresh token is issued.
+type ImpersonationResponse struct {
+	AccessToken    string        `json:"accessToken"`
+	ExpiresIn      int           `json:"expiresIn"`
+	User           *UserResponse `json:"user"`
+	ImpersonatedBy uuid.UUID     `json:"impersonatedBy"`
+}
+
+// RefreshRequest represents the request to refresh a token pair. In cookie
+// auth mode the refresh token cookie is used instead when present.
 type RefreshRequest struct {
 	RefreshToken string `json:"refreshToken" validate:"required"`
 }
 
+// LogoutRequest represents the request to end a session. In cookie auth
+// mode the refresh token cookie is used instead when present.
+type LogoutRequest struct {
+	RefreshToken string `json:"refreshToken" validate:"required"`
+}
+
 type TokenClaims struct {
 	UserUUID uuid.UUID `json:"sub"`
 	Role     UserRole  `json:"role"`
 }
+
+// Invite lets one person register while registration is closed. An invite
+// with an Email can only be used to register that address.
+type Invite struct {
+	Code      string     `json:"code"`
+	Email     *string    `json:"email,omitempty"`
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+	CreatedAt time.Time  `json:"createdAt"`
+}
+
+// CreateInviteRequest represents the request to create an invite. Invites
+// without an expiry stay valid until used.
+type CreateInviteRequest struct {
+	Email     *string    `json:"email" validate:"omitempty,email"`
+	ExpiresAt *time.Time `json:"expiresAt"`
+}
+
+// ClientInfo describes the client a session was created from
+type ClientInfo struct {
+	UserAgent string
+	IPAddress string
+}
+
+// Session is an active refresh-token session. The token hash is never exposed.
+type Session struct {
+	UUID      uuid.UUID   `json:"id"`
+	User      SessionUser `json:"user"`
+	UserAgent *string     `json:"userAgent,omitempty"`
+	IPAddress *string     `json:"ipAddress,omitempty"`
+	CreatedAt time.Time   `json:"createdAt"`
+	ExpiresAt time.Time   `json:"expiresAt"`
+}
+
+// SessionUser identifies the owner of a session
+type SessionUser struct {
+	UUID     uuid.UUID `json:"id"`
+	Username string    `json:"username"`
+}
+
+// ListSessionsRequest represents query parameters for listing sessions
+type ListSessionsRequest struct {
+	UserID *uuid.UUID `form:"userId"`
+	Page   int        `form:"page" validate:"omitempty,min=1"`
+	Limit  int        `form:"limit" validate:"omitempty,min=1,max=100"`
+}
+
+// ListSessionsResponse represents a page of active sessions
+type ListSessionsResponse struct {
+	Sessions   []Session       `json:"sessions"`
+	TotalCount int             `json:"totalCount"`
+	Page       int             `json:"page"`
+	Limit      int             `json:"limit"`
+	Links      PaginationLinks `json:"links"`
+}
@@ -5,15 +5,32 @@ import "errors"
 var (
 	ErrInvalidCredentials   = errors.New("invalid credentials")
 	ErrUserNotFound         = errors.New("user not found")
+	ErrUserInactive         = errors.New("user is not active")
 	ErrEmailTaken           = errors.New("email already taken")
 	ErrUsernameTaken        = errors.New("username already taken")
+	ErrUsernameReserved     = errors.New("username is reserved")
 	ErrPostNotFound         = errors.New("post not found")
 	ErrSlugTaken            = errors.New("slug already taken")
+	ErrDuplicatePost        = errors.New("duplicate post")
 	ErrForbidden            = errors.New("forbidden")
 	ErrUnauthorized         = errors.New("unauthorized")
 	ErrTokenExpired         = errors.New("token expired")
 	ErrInvalidToken         = errors.New("invalid token")
+	ErrSessionNotFound      = errors.New("session not found")
 	ErrConflict             = errors.New("conflict")
 	ErrPostAlreadyPublished = errors.New("post already published")
 	ErrInvalidStatusChange  = errors.New("invalid status change")
+	ErrPostNotReady         = errors.New("post is not ready to publish")
+	ErrInvalidPostContent   = errors.New("post content does not meet the content policy")
+	ErrContentTooLarge      = errors.New("content is too large")
+	ErrSeriesNotFound       = errors.New("series not found")
+	ErrPostInSeries         = errors.New("post already belongs to a series")
+	ErrFeatureFlagNotFound  = errors.New("feature flag not found")
+	ErrRegistrationClosed   = errors.New("registration is closed")
+	ErrInvalidInvite        = errors.New("invite code is invalid")
+	ErrInviteUsed           = errors.New("invite code has already been used")
+	ErrInviteExpired        = errors.New("invite code has expired")
+	ErrCaptchaFailed        = errors.New("captcha verification failed")
+	ErrNotificationNotFound = errors.New("notification not found")
+	ErrInvalidSeriesOrder   = errors.New("series order must list every post in the series exactly once")
 )
@@ -0,0 +1,32 @@
+package domain
+
+import "time"
+
+// Feature flag keys
+const (
+	FlagRegistrationOpen = "registration_open"
+)
+
+// DefaultFeatureFlags lists every known flag with the value it has when not
+// set, chosen so an empty feature_flags table behaves as before flags existed
+var DefaultFeatureFlags = map[string]bool{
+	FlagRegistrationOpen: true,
+}
+
+// FeatureFlag is a runtime toggle. UpdatedAt is nil when the flag has never
+// been set and holds its default.
+type FeatureFlag struct {
+	Key       string     `json:"key"`
+	Enabled   bool       `json:"enabled"`
+	UpdatedAt *time.Time `json:"updatedAt,omitempty"`
+}
+
+// SetFeatureFlagRequest represents the request to set a feature flag
+type SetFeatureFlagRequest struct {
+	Enabled *bool `json:"enabled" validate:"required"`
+}
+
+// ListFeatureFlagsResponse represents every known feature flag
+type ListFeatureFlagsResponse struct {
+	Flags []FeatureFlag `json:"flags"`
+}
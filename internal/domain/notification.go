@@ -0,0 +1,69 @@
+package domain
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// NotificationType identifies what a notification is about and the shape of
+// its payload
+type NotificationType string
+
+const (
+	// NotificationPostPublished confirms to an author that their post was
+	// published. Its payload is a PostPublishedNotification.
+	NotificationPostPublished NotificationType = "post.published"
+
+	// NotificationNewLogin warns a user that their account was logged in to
+	// from a device it hadn't been used on before. Its payload is a
+	// NewLoginNotification.
+	NotificationNewLogin NotificationType = "security.new_login"
+)
+
+// Notification is an entry in a user's notification inbox
+type Notification struct {
+	ID        int              `json:"-"`
+	UUID      uuid.UUID        `json:"uuid"`
+	UserID    int              `json:"-"`
+	Type      NotificationType `json:"type"`
+	Payload   json.RawMessage  `json:"payload"`
+	ReadAt    *time.Time       `json:"readAt,omitempty"`
+	CreatedAt time.Time        `json:"createdAt"`
+}
+
+// NewLoginNotification describes a login from a new device
+type NewLoginNotification struct {
+	IPAddress  string    `json:"ipAddress,omitempty"`
+	UserAgent  string    `json:"userAgent,omitempty"`
+	LoggedInAt time.Time `json:"loggedInAt"`
+}
+
+// ListNotificationsRequest represents query parameters for listing
+// notifications. With Unread set, only unread notifications are listed.
+type ListNotificationsRequest struct {
+	Unread bool `form:"unread"`
+	Page   int  `form:"page" validate:"omitempty,min=1"`
+	Limit  int  `form:"limit" validate:"omitempty,min=1,max=100"`
+}
+
+// ListNotificationsResponse represents the response for listing notifications
+type ListNotificationsResponse struct {
+	Notifications []Notification  `json:"notifications"`
+	TotalCount    int             `json:"totalCount"`
+	Page          int             `json:"page"`
+	Limit         int             `json:"limit"`
+	Links         PaginationLinks `json:"links"`
+}
+
+// UnreadNotificationsResponse represents the number of unread notifications
+type UnreadNotificationsResponse struct {
+	Count int `json:"count"`
+}
+
+// MarkAllNotificationsReadResponse represents the number of notifications
+// marked as read
+type MarkAllNotificationsReadResponse struct {
+	Updated int `json:"updated"`
+}
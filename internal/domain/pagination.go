@@ -0,0 +1,10 @@
+package domain
+
+// PaginationLinks holds absolute URLs for paging through a list. Prev is
+// omitted on the first page and Next on the last.
+type PaginationLinks struct {
+	First string `json:"firstUrl" xml:"firstUrl"`
+	Prev  string `json:"prevUrl,omitempty" xml:"prevUrl,omitempty"`
+	Next  string `json:"nextUrl,omitempty" xml:"nextUrl,omitempty"`
+	Last  string `json:"lastUrl" xml:"lastUrl"`
+}
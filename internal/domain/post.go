@@ -15,25 +15,49 @@ const (
 	PostStatusArchived  PostStatus = "archived"
 )
 
+// PostFormat is the markup the post content is authored in
+type PostFormat string
+
+const (
+	PostFormatMarkdown PostFormat = "markdown"
+	PostFormatHTML     PostFormat = "html"
+	PostFormatPlain    PostFormat = "plain"
+)
+
+// PostVisibility controls who can find a post, independently of its status
+type PostVisibility string
+
+const (
+	// PostVisibilityPublic posts appear in listings
+	PostVisibilityPublic PostVisibility = "public"
+	// PostVisibilityUnlisted posts are left out of listings but can be read
+	// by anyone with a direct link
+	PostVisibilityUnlisted PostVisibility = "unlisted"
+	// PostVisibilityPrivate posts can only be read by their author and admins
+	PostVisibilityPrivate PostVisibility = "private"
+)
+
 // Post represents a blog post
 type Post struct {
-	ID          int        `json:"id"`
-	UUID        uuid.UUID  `json:"uuid"`
-	AuthorID    int        `json:"authorId"`
-	Title       string     `json:"title"`
-	Slug        string     `json:"slug"`
-	Content     string     `json:"content"`
-	Excerpt     *string    `json:"excerpt,omitempty"`
-	Status      PostStatus `json:"status"`
-	PublishedAt *time.Time `json:"publishedAt,omitempty"`
-	CreatedAt   time.Time  `json:"createdAt"`
-	UpdatedAt   time.Time  `json:"updatedAt"`
+	ID          int            `json:"id"`
+	UUID        uuid.UUID      `json:"uuid"`
+	AuthorID    int            `json:"authorId"`
+	Title       string         `json:"title"`
+	Slug        string         `json:"slug"`
+	Content     string         `json:"content"`
+	Excerpt     *string        `json:"excerpt,omitempty"`
+	Format      PostFormat     `json:"format"`
+	Visibility  PostVisibility `json:"visibility"`
+	Status      PostStatus     `json:"status"`
+	PublishedAt *time.Time     `json:"publishedAt,omitempty"`
+	CreatedAt   time.Time      `json:"createdAt"`
+	UpdatedAt   time.Time      `json:"updatedAt"`
 }
 
 // PostAuthor represents minimal author information for a post
 type PostAuthor struct {
-	UUID     uuid.UUID `json:"uuid"`
-	Username string    `json:"username"`
+	UUID     uuid.UUID `json:"uuid" xml:"uuid"`
+	Username string    `json:"username" xml:"username"`
 }
 
 // PostWithAuthor represents a post with author information
@@ -42,21 +66,40 @@ type PostWithAuthor struct {
 	Author PostAuthor `json:"author"`
 }
 
-// CreatePostRequest represents the request to create a post
+// ReadPost represents a post read by a user
+type ReadPost struct {
+	PostWithAuthor
+	ReadAt   time.Time
+	Progress *int
+}
+
+// BookmarkedPost represents a post bookmarked by a user
+type BookmarkedPost struct {
+	PostWithAuthor
+	BookmarkedAt time.Time
+}
+
+// CreatePostRequest represents the request to create a post. Field lengths
+// are checked by PostService against the configured content policy.
 type CreatePostRequest struct {
-	Title   string     `json:"title" validate:"required,min=3,max=255"`
-	Content string     `json:"content" validate:"required,min=10"`
-	Excerpt *string    `json:"excerpt" validate:"omitempty,max=500"`
-	Status  PostStatus `json:"status" validate:"omitempty,oneof=draft published"`
+	Title      string         `json:"title" validate:"required"`
+	Content    string         `json:"content"`
+	Excerpt    *string        `json:"excerpt"`
+	Format     PostFormat     `json:"format" validate:"omitempty,oneof=markdown html plain"`
+	Visibility PostVisibility `json:"visibility" validate:"omitempty,oneof=public unlisted private"`
+	Status     PostStatus     `json:"status" validate:"omitempty,oneof=draft published"`
 }
 
-// UpdatePostRequest represents the request to update a post
+// UpdatePostRequest represents the request to update a post. Field lengths
+// are checked by PostService against the configured content policy.
 type UpdatePostRequest struct {
-	Title        *string     `json:"title" validate:"omitempty,min=3,max=255"`
-	Content      *string     `json:"content" validate:"omitempty,min=10"`
-	Excerpt      *string     `json:"excerpt" validate:"omitempty,max=500"`
-	Status       *PostStatus `json:"status" validate:"omitempty,oneof=draft published archived"`
-	ScheduledFor *time.Time  `json:"scheduledFor" validate:"omitempty"`
+	Title        *string         `json:"title"`
+	Content      *string         `json:"content"`
+	Excerpt      *string         `json:"excerpt"`
+	Format       *PostFormat     `json:"format" validate:"omitempty,oneof=markdown html plain"`
+	Visibility   *PostVisibility `json:"visibility" validate:"omitempty,oneof=public unlisted private"`
+	Status       *PostStatus     `json:"status" validate:"omitempty,oneof=draft published archived"`
+	ScheduledFor *time.Time      `json:"scheduledFor" validate:"omitempty"`
 }
 
 // ListPostsRequest represents query parameters for listing posts
@@ -65,26 +108,176 @@ type ListPostsRequest struct {
 	AuthorID *uuid.UUID  `form:"authorId"`
 	Page     int         `form:"page" validate:"omitempty,min=1"`
 	Limit    int         `form:"limit" validate:"omitempty,min=1,max=100"`
+
+	// UnpublishedAuthor, when set, limits draft and archived posts to this
+	// author's; with uuid.Nil only published posts are listed. It is set by
+	// the service, never from the query string.
+	UnpublishedAuthor *uuid.UUID `form:"-"`
 }
 
 // PostResponse represents a single post response
 type PostResponse struct {
-	UUID        uuid.UUID  `json:"uuid"`
-	Title       string     `json:"title"`
-	Slug        string     `json:"slug"`
-	Content     string     `json:"content"`
-	Excerpt     *string    `json:"excerpt,omitempty"`
-	Status      PostStatus `json:"status"`
-	PublishedAt *time.Time `json:"publishedAt,omitempty"`
-	CreatedAt   time.Time  `json:"createdAt"`
-	UpdatedAt   time.Time  `json:"updatedAt"`
-	Author      PostAuthor `json:"author"`
+	UUID           uuid.UUID       `json:"uuid" xml:"uuid"`
+	Title          string          `json:"title" xml:"title"`
+	Slug           string          `json:"slug" xml:"slug"`
+	Content        string          `json:"content" xml:"content"`
+	Excerpt        *string         `json:"excerpt,omitempty" xml:"excerpt,omitempty"`
+	Format         PostFormat      `json:"format" xml:"format"`
+	Visibility     PostVisibility  `json:"visibility" xml:"visibility"`
+	Status         PostStatus      `json:"status" xml:"status"`
+	PublishedAt    *time.Time      `json:"publishedAt,omitempty" xml:"publishedAt,omitempty"`
+	CreatedAt      time.Time       `json:"createdAt" xml:"createdAt"`
+	UpdatedAt      time.Time       `json:"updatedAt" xml:"updatedAt"`
+	Author         PostAuthor      `json:"author" xml:"author"`
+	ReadByMe       bool            `json:"readByMe" xml:"readByMe"`
+	BookmarkedByMe bool            `json:"bookmarkedByMe" xml:"bookmarkedByMe"`
+	Series         *PostSeriesInfo `json:"series,omitempty" xml:"series,omitempty"`
+	TOC            []TOCEntry      `json:"toc,omitempty" xml:"toc>entry,omitempty"`
+}
+
+// TOCEntry is a heading in a post's table of contents
+type TOCEntry struct {
+	Level  int    `json:"level" xml:"level"`
+	Text   string `json:"text" xml:"text"`
+	Anchor string `json:"anchor" xml:"anchor"`
+}
+
+// ListEditablePostsRequest represents query parameters for listing the posts
+// the caller can edit
+type ListEditablePostsRequest struct {
+	Status *PostStatus `form:"status" validate:"omitempty,oneof=draft published archived"`
+	Page   int         `form:"page" validate:"omitempty,min=1"`
+	Limit  int         `form:"limit" validate:"omitempty,min=1,max=100"`
 }
 
 // ListPostsResponse represents the response for listing posts
 type ListPostsResponse struct {
+	Posts      []PostResponse  `json:"posts" xml:"posts>post"`
+	TotalCount int             `json:"totalCount" xml:"totalCount"`
+	Page       int             `json:"page" xml:"page"`
+	Limit      int             `json:"limit" xml:"limit"`
+	Links      PaginationLinks `json:"links" xml:"links"`
+}
+
+// MarkReadResponse represents the response for marking a post as read
+type MarkReadResponse struct {
+	PostUUID uuid.UUID `json:"postUuid"`
+	ReadAt   time.Time `json:"readAt"`
+}
+
+// ReadHistoryRequest represents query parameters for listing read history
+type ReadHistoryRequest struct {
+	Page  int `form:"page" validate:"omitempty,min=1"`
+	Limit int `form:"limit" validate:"omitempty,min=1,max=100"`
+}
+
+// ReadHistoryEntry represents a post the user has read. Progress is the
+// last reading progress saved for the post, if any.
+type ReadHistoryEntry struct {
+	Post     PostResponse `json:"post"`
+	ReadAt   time.Time    `json:"readAt"`
+	Progress *int         `json:"progress,omitempty"`
+}
+
+// UpdateReadProgressRequest represents the request to save reading progress.
+// Percent is clamped to 0-100.
+type UpdateReadProgressRequest struct {
+	Percent *int `json:"percent" validate:"required"`
+}
+
+// ReadProgressResponse represents the saved reading progress for a post
+type ReadProgressResponse struct {
+	PostUUID  uuid.UUID `json:"postUuid"`
+	Percent   int       `json:"percent"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// ReadHistoryResponse represents the response for listing read history
+type ReadHistoryResponse struct {
+	Entries    []ReadHistoryEntry `json:"entries"`
+	TotalCount int                `json:"totalCount"`
+	Page       int                `json:"page"`
+	Limit      int                `json:"limit"`
+	Links      PaginationLinks    `json:"links"`
+}
+
+// ListBookmarksRequest represents query parameters for listing bookmarks
+type ListBookmarksRequest struct {
+	Page  int `form:"page" validate:"omitempty,min=1"`
+	Limit int `form:"limit" validate:"omitempty,min=1,max=100"`
+}
+
+// BookmarkResponse represents a bookmarked post
+type BookmarkResponse struct {
+	Post         PostResponse `json:"post"`
+	BookmarkedAt time.Time    `json:"bookmarkedAt"`
+}
+
+// ListBookmarksResponse represents the response for listing bookmarks
+type ListBookmarksResponse struct {
+	Bookmarks  []BookmarkResponse `json:"bookmarks"`
+	TotalCount int                `json:"totalCount"`
+	Page       int                `json:"page"`
+	Limit      int                `json:"limit"`
+	Links      PaginationLinks    `json:"links"`
+}
+
+// PostBucket holds the most recently updated posts in one status, along with
+// how many posts have that status in total
+type PostBucket struct {
 	Posts      []PostResponse `json:"posts"`
 	TotalCount int            `json:"totalCount"`
-	Page       int            `json:"page"`
-	Limit      int            `json:"limit"`
+}
+
+// GroupedPostsResponse represents an author's posts grouped by status
+type GroupedPostsResponse struct {
+	Draft     PostBucket `json:"draft"`
+	Published PostBucket `json:"published"`
+	Archived  PostBucket `json:"archived"`
+}
+
+// TransferPostRequest represents the request to move a post to another author
+type TransferPostRequest struct {
+	NewAuthorID uuid.UUID `json:"newAuthorId" validate:"required"`
+}
+
+// TransferPostResponse represents a post after an ownership transfer
+type TransferPostResponse struct {
+	Post           PostResponse `json:"post"`
+	PreviousAuthor PostAuthor   `json:"previousAuthor"`
+}
+
+// PostInteractionsRequest represents the request to look up the caller's
+// interactions with a set of posts
+type PostInteractionsRequest struct {
+	PostIDs []uuid.UUID `json:"postIds" validate:"required,min=1,max=100"`
+}
+
+// PostInteraction holds the caller's interaction flags for one post. Posts
+// that don't exist or aren't published have every flag false.
+type PostInteraction struct {
+	PostUUID       uuid.UUID `json:"postUuid"`
+	ReadByMe       bool      `json:"readByMe"`
+	BookmarkedByMe bool      `json:"bookmarkedByMe"`
+}
+
+// PostInteractionsResponse represents the caller's interactions with each
+// requested post, in request order
+type PostInteractionsResponse struct {
+	Interactions []PostInteraction `json:"interactions"`
+}
+
+// PostStatusCounts represents the number of posts in each status
+type PostStatusCounts struct {
+	Draft     int `json:"draft"`
+	Published int `json:"published"`
+	Archived  int `json:"archived"`
+	Total     int `json:"total"`
+}
+
+// AuthorStatsResponse represents dashboard statistics for an author's posts
+type AuthorStatsResponse struct {
+	Posts          PostStatusCounts `json:"posts"`
+	TotalReads     int              `json:"totalReads"`
+	TotalBookmarks int              `json:"totalBookmarks"`
 }
@@ -1,16 +1,66 @@
 package domain
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// EventEnvelope wraps every queue message with its type and schema version.
+// CorrelationID is the ID of the request that produced the event, if any.
+type EventEnvelope struct {
+	Type          string          `json:"type"`
+	Version       int             `json:"version"`
+	OccurredAt    time.Time       `json:"occurredAt"`
+	CorrelationID string          `json:"correlationId,omitempty"`
+	Payload       json.RawMessage `json:"payload"`
+}
+
+// Event type and version constants
+const (
+	EventTypePostPublish    = "post.publish"
+	PostPublishEventVersion = 1
+)
 
 // PostPublishEvent represents a post publish event to be queued
 type PostPublishEvent struct {
-	PostUUID      string    `json:"postUuid"`
-	AuthorUUID    string    `json:"authorUuid"`
-	RequestedAt   time.Time `json:"requestedAt"`
-	ScheduledFor  *time.Time `json:"scheduledFor,omitempty"`
+	EventID      string     `json:"eventId"`
+	PostUUID     string     `json:"postUuid"`
+	AuthorUUID   string     `json:"authorUuid"`
+	RequestedAt  time.Time  `json:"requestedAt"`
+	ScheduledFor *time.Time `json:"scheduledFor,omitempty"`
+}
+
+// PostPublishedNotification is pushed to live subscribers once a post has
+// been published
+type PostPublishedNotification struct {
+	PostUUID    uuid.UUID `json:"postId"`
+	AuthorUUID  uuid.UUID `json:"authorId"`
+	Title       string    `json:"title"`
+	Slug        string    `json:"slug"`
+	PublishedAt time.Time `json:"publishedAt"`
 }
 
-// QueueName constants
+// QueueName constants.
+//
+// Publish events moved from "post.publish" to "post.publish.v2" when the
+// queue gained dead-lettering: RabbitMQ refuses to redeclare an existing
+// queue with different arguments. Workers keep draining the legacy queue
+// while it exists, so events queued before the upgrade, including ones in
+// the pre-envelope format, are still processed; malformed ones are dropped,
+// since the legacy queue has no dead-letter queue. Once it stays empty after
+// every instance is upgraded, delete it (e.g. rabbitmqctl delete_queue
+// post.publish).
+const (
+	QueuePostPublish       = "post.publish.v2"
+	QueuePostPublishDLQ    = "post.publish.dlq"
+	QueuePostPublishLegacy = "post.publish"
+)
+
+// Exchange name constants. Live notifications are fanned out to every
+// instance so SSE streams see events produced anywhere.
 const (
-	QueuePostPublish = "post.publish"
+	ExchangePostPublished = "post.published"
+	ExchangeNotifications = "notifications"
 )
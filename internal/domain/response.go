@@ -1,25 +1,45 @@
 package domain
 
+import (
+	"encoding/xml"
+
+	"github.com/saimonsiddique/blog-api/internal/pkg/version"
+)
+
 type APIResponse struct {
-	Status           string      `json:"status"`
-	StatusCode       int         `json:"statusCode"`
-	TrackingID       string      `json:"trackingId"`
-	Data             interface{} `json:"data,omitempty"`
-	Error            *APIError   `json:"error,omitempty"`
-	DocumentationURL string      `json:"documentationUrl"`
+	XMLName          xml.Name    `json:"-" xml:"response"`
+	Status           string      `json:"status" xml:"status"`
+	StatusCode       int         `json:"statusCode" xml:"statusCode"`
+	TrackingID       string      `json:"trackingId" xml:"trackingId"`
+	Data             interface{} `json:"data,omitempty" xml:"data,omitempty"`
+	Error            *APIError   `json:"error,omitempty" xml:"error,omitempty"`
+	DocumentationURL string      `json:"documentationUrl" xml:"documentationUrl"`
 }
 
 type APIError struct {
-	Code       string `json:"code"`
-	Message    string `json:"message"`
-	Details    string `json:"details"`
-	Timestamp  string `json:"timestamp"`
-	Path       string `json:"path"`
-	Suggestion string `json:"suggestion"`
+	Code       string `json:"code" xml:"code"`
+	Message    string `json:"message" xml:"message"`
+	Details    string `json:"details" xml:"details"`
+	Timestamp  string `json:"timestamp" xml:"timestamp"`
+	Path       string `json:"path" xml:"path"`
+	Suggestion string `json:"suggestion" xml:"suggestion"`
 }
-
 type HealthResponse struct {
 	Status    string `json:"status"`
 	Timestamp string `json:"timestamp"`
 	Database  string `json:"database"`
 }
+
+// DependencyVersions represents the server versions of external dependencies
+type DependencyVersions struct {
+	Database string `json:"database"`
+	RabbitMQ string `json:"rabbitmq"`
+}
+
+type ReadinessResponse struct {
+	Status       string             `json:"status"`
+	Timestamp    string             `json:"timestamp"`
+	Database     string             `json:"database"`
+	Build        version.Info       `json:"build"`
+	Dependencies DependencyVersions `json:"dependencies"`
+}
@@ -0,0 +1,82 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Series represents an ordered collection of posts
+type Series struct {
+	ID          int       `json:"id"`
+	UUID        uuid.UUID `json:"uuid"`
+	AuthorID    int       `json:"authorId"`
+	Title       string    `json:"title"`
+	Slug        string    `json:"slug"`
+	Description *string   `json:"description,omitempty"`
+	CreatedAt   time.Time `json:"createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
+
+// SeriesWithAuthor represents a series with author information
+type SeriesWithAuthor struct {
+	Series
+	Author PostAuthor `json:"author"`
+}
+
+// SeriesPost represents a post at a position within a series
+type SeriesPost struct {
+	PostWithAuthor
+	Position int
+}
+
+// CreateSeriesRequest represents the request to create a series
+type CreateSeriesRequest struct {
+	Title       string  `json:"title" validate:"required,min=3,max=255"`
+	Description *string `json:"description" validate:"omitempty,max=1000"`
+}
+
+// AddSeriesPostRequest represents the request to append a post to a series
+type AddSeriesPostRequest struct {
+	PostUUID uuid.UUID `json:"postId" validate:"required"`
+}
+
+// ReorderSeriesRequest represents the request to reorder the posts of a series
+type ReorderSeriesRequest struct {
+	PostUUIDs []uuid.UUID `json:"postIds" validate:"required,min=1"`
+}
+
+// SeriesPostResponse represents a post within a series response
+type SeriesPostResponse struct {
+	Position int          `json:"position"`
+	Post     PostResponse `json:"post"`
+}
+
+// SeriesResponse represents a series with its ordered posts
+type SeriesResponse struct {
+	UUID        uuid.UUID            `json:"uuid"`
+	Title       string               `json:"title"`
+	Slug        string               `json:"slug"`
+	Description *string              `json:"description,omitempty"`
+	CreatedAt   time.Time            `json:"createdAt"`
+	UpdatedAt   time.Time            `json:"updatedAt"`
+	Author      PostAuthor           `json:"author"`
+	Posts       []SeriesPostResponse `json:"posts"`
+}
+
+// SeriesLink represents minimal information about a neighbouring post in a series
+type SeriesLink struct {
+	UUID  uuid.UUID `json:"uuid" xml:"uuid"`
+	Title string    `json:"title" xml:"title"`
+	Slug  string    `json:"slug" xml:"slug"`
+}
+
+// PostSeriesInfo describes where a post sits within its series
+type PostSeriesInfo struct {
+	UUID     uuid.UUID   `json:"uuid" xml:"uuid"`
+	Title    string      `json:"title" xml:"title"`
+	Slug     string      `json:"slug" xml:"slug"`
+	Position int         `json:"position" xml:"position"`
+	Prev     *SeriesLink `json:"prev,omitempty" xml:"prev,omitempty"`
+	Next     *SeriesLink `json:"next,omitempty" xml:"next,omitempty"`
+}
@@ -13,27 +13,45 @@ const (
 	RoleAdmin UserRole = "admin"
 )
 
+// IsValid reports whether r is a known role
+func (r UserRole) IsValid() bool {
+	switch r {
+	case RoleUser, RoleAdmin:
+		return true
+	}
+	return false
+}
+
 type User struct {
-	ID        int       `json:"-"`
-	UUID      uuid.UUID `json:"id"`
-	Username  string    `json:"username"`
-	Email     string    `json:"email"`
-	Password  string    `json:"-"`
-	Role      UserRole  `json:"role"`
-	IsActive  bool      `json:"isActive"`
-	CreatedAt time.Time `json:"createdAt"`
-	UpdatedAt time.Time `json:"updatedAt"`
+	ID       int       `json:"-"`
+	UUID     uuid.UUID `json:"id"`
+	Username string    `json:"username"`
+	Email    string    `json:"email"`
+	// EmailNormalized is the form of Email used for matching accounts
+	EmailNormalized string     `json:"-"`
+	Password        string     `json:"-"`
+	Role            UserRole   `json:"role"`
+	IsActive        bool       `json:"isActive"`
+	LastLoginAt     *time.Time `json:"lastLoginAt,omitempty"`
+	CreatedAt       time.Time  `json:"createdAt"`
+	UpdatedAt       time.Time  `json:"updatedAt"`
 }
 
+// RegisterRequest represents the request to register. InviteCode is only
+// needed while registration is closed, and CaptchaToken while a CAPTCHA
+// provider is configured.
 type RegisterRequest struct {
-	Username string `json:"username" validate:"required,min=3,max=30,alphanum"`
-	Email    string `json:"email" validate:"required,email"`
-	Password string `json:"password" validate:"required,min=8"`
+	Username     string `json:"username" validate:"required,min=3,max=30,alphanum"`
+	Email        string `json:"email" validate:"required,email"`
+	Password     string `json:"password" validate:"required,min=8"`
+	InviteCode   string `json:"inviteCode" validate:"omitempty,max=64"`
+	CaptchaToken string `json:"captchaToken" validate:"omitempty,max=8192"`
 }
 
 type LoginRequest struct {
-	Email    string `json:"email" validate:"required,email"`
-	Password string `json:"password" validate:"required"`
+	Email        string `json:"email" validate:"required,email"`
+	Password     string `json:"password" validate:"required"`
+	CaptchaToken string `json:"captchaToken" validate:"omitempty,max=8192"`
 }
 
 type UpdateProfileRequest struct {
@@ -42,23 +60,52 @@ type UpdateProfileRequest struct {
 }
 
 type UserResponse struct {
+	ID          uuid.UUID  `json:"id"`
+	Username    string     `json:"username"`
+	Email       string     `json:"email"`
+	Role        UserRole   `json:"role"`
+	IsActive    bool       `json:"isActive"`
+	LastLoginAt *time.Time `json:"lastLoginAt,omitempty"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	UpdatedAt   time.Time  `json:"updatedAt"`
+}
+
+func (u *User) ToResponse() *UserResponse {
+	return &UserResponse{
+		ID:          u.UUID,
+		Username:    u.Username,
+		Email:       u.Email,
+		Role:        u.Role,
+		IsActive:    u.IsActive,
+		LastLoginAt: u.LastLoginAt,
+		CreatedAt:   u.CreatedAt,
+		UpdatedAt:   u.UpdatedAt,
+	}
+}
+
+// PublicProfile is the part of a user's profile anyone can see
+type PublicProfile struct {
 	ID        uuid.UUID `json:"id"`
 	Username  string    `json:"username"`
-	Email     string    `json:"email"`
-	Role      UserRole  `json:"role"`
-	IsActive  bool      `json:"isActive"`
 	CreatedAt time.Time `json:"createdAt"`
-	UpdatedAt time.Time `json:"updatedAt"`
 }
 
-func (u *User) ToResponse() *UserResponse {
-	return &UserResponse{
+// ToPublicProfile returns the user's public profile
+func (u *User) ToPublicProfile() PublicProfile {
+	return PublicProfile{
 		ID:        u.UUID,
 		Username:  u.Username,
-		Email:     u.Email,
-		Role:      u.Role,
-		IsActive:  u.IsActive,
 		CreatedAt: u.CreatedAt,
-		UpdatedAt: u.UpdatedAt,
 	}
 }
+
+// UsersBatchRequest represents the request to look up several users at once
+type UsersBatchRequest struct {
+	UserIDs []uuid.UUID `json:"userIds" validate:"required,min=1,max=100"`
+}
+
+// UsersBatchResponse holds the public profiles of the requested users, in
+// request order. Users that don't exist or are deactivated are left out.
+type UsersBatchResponse struct {
+	Users []PublicProfile `json:"users"`
+}
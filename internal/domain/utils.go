@@ -0,0 +1,11 @@
+package domain
+
+// SlugifyRequest represents query parameters for previewing a slug
+type SlugifyRequest struct {
+	Title string `form:"title" validate:"required,max=255"`
+}
+
+// SlugifyResponse represents the slug a title would produce
+type SlugifyResponse struct {
+	Slug string `json:"slug"`
+}
@@ -0,0 +1,86 @@
+package events
+
+import "sync"
+
+// subscriberBuffer is how many notifications a subscriber can fall behind
+// before further ones are dropped for it
+const subscriberBuffer = 16
+
+type subscriber[T any] struct {
+	ch    chan T
+	match func(T) bool
+}
+
+// Broker fans notifications out to in-process subscribers such as SSE
+// streams. Slow subscribers miss notifications rather than blocking the
+// publisher.
+type Broker[T any] struct {
+	mu          sync.Mutex
+	subscribers map[*subscriber[T]]struct{}
+	closed      bool
+}
+
+func NewBroker[T any]() *Broker[T] {
+	return &Broker[T]{
+		subscribers: make(map[*subscriber[T]]struct{}),
+	}
+}
+
+// Subscribe returns a channel of notifications, limited to those match
+// accepts when match is set, and a function that cancels the subscription.
+// The channel is closed when the subscription is cancelled or the broker
+// closes.
+func (b *Broker[T]) Subscribe(match func(T) bool) (<-chan T, func()) {
+	sub := &subscriber[T]{
+		ch:    make(chan T, subscriberBuffer),
+		match: match,
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		close(sub.ch)
+		return sub.ch, func() {}
+	}
+	b.subscribers[sub] = struct{}{}
+
+	return sub.ch, func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+
+		if _, ok := b.subscribers[sub]; ok {
+			delete(b.subscribers, sub)
+			close(sub.ch)
+		}
+	}
+}
+
+// Publish sends a notification to every matching subscriber without blocking
+func (b *Broker[T]) Publish(notification T) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for sub := range b.subscribers {
+		if sub.match != nil && !sub.match(notification) {
+			continue
+		}
+
+		select {
+		case sub.ch <- notification:
+		default:
+		}
+	}
+}
+
+// Close ends every subscription so long-lived streams return during shutdown
+func (b *Broker[T]) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for sub := range b.subscribers {
+		delete(b.subscribers, sub)
+		close(sub.ch)
+	}
+	b.closed = true
+}
@@ -0,0 +1,29 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/saimonsiddique/blog-api/internal/service"
+)
+
+type AdminHandler struct {
+	service *service.AdminService
+}
+
+func NewAdminHandler(service *service.AdminService) *AdminHandler {
+	return &AdminHandler{
+		service: service,
+	}
+}
+
+// GetStats returns platform-wide statistics
+func (h *AdminHandler) GetStats(c *gin.Context) {
+	stats, err := h.service.Stats(c.Request.Context())
+	if err != nil {
+		ServiceError(c, err)
+		return
+	}
+
+	Success(c, http.StatusOK, stats)
+}
@@ -0,0 +1,81 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// auditEntryKey holds the action a handler recorded for AuditMiddleware
+const auditEntryKey = "auditEntry"
+
+type auditEntry struct {
+	action string
+	fields logrus.Fields
+}
+
+// setAuditEntry records an action for AuditMiddleware to log once the
+// request completes
+func setAuditEntry(c *gin.Context, action string, fields logrus.Fields) {
+	c.Set(auditEntryKey, auditEntry{action: action, fields: fields})
+}
+
+// AuditMiddleware logs actions recorded with setAuditEntry along with the
+// user who performed them. It must run after the auth middleware.
+func AuditMiddleware(logger *logrus.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		value, ok := c.Get(auditEntryKey)
+		if !ok {
+			return
+		}
+		entry := value.(auditEntry)
+
+		actorUUID, _ := GetUserUUID(c)
+		logger.WithFields(entry.fields).WithFields(logrus.Fields{
+			"audit":     true,
+			"action":    entry.action,
+			"actorUuid": actorUUID,
+		}).Info("Audited action performed")
+	}
+}
+
+// impersonationStartedKey holds the target user when an admin was issued an
+// impersonation token during the request
+const impersonationStartedKey = "impersonationStarted"
+
+// ImpersonationAuditMiddleware logs impersonation tokens being issued and
+// every request made with one, recording both the admin and the impersonated
+// user. It must run after the auth middleware.
+func ImpersonationAuditMiddleware(logger *logrus.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if targetUUID, ok := c.Get(impersonationStartedKey); ok {
+			adminUUID, _ := GetUserUUID(c)
+			logger.WithFields(logrus.Fields{
+				"audit":     true,
+				"action":    "impersonation_started",
+				"adminUuid": adminUUID,
+				"userUuid":  targetUUID,
+			}).Info("Impersonation token issued")
+		}
+
+		impersonatorUUID, ok := GetImpersonatorUUID(c)
+		if !ok {
+			return
+		}
+
+		userUUID, _ := GetUserUUID(c)
+
+		logger.WithFields(logrus.Fields{
+			"audit":     true,
+			"action":    "impersonated_request",
+			"adminUuid": impersonatorUUID,
+			"userUuid":  userUUID,
+			"method":    c.Request.Method,
+			"path":      c.FullPath(),
+			"status":    c.Writer.Status(),
+		}).Info("Request made while impersonating")
+	}
+}
@@ -1,11 +1,16 @@
 package handler
 
 import (
+	"errors"
+	"io"
 	"log"
 	"net/http"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/saimonsiddique/blog-api/internal/config"
 	"github.com/saimonsiddique/blog-api/internal/domain"
 	"github.com/saimonsiddique/blog-api/internal/service"
 )
@@ -13,12 +18,16 @@ import (
 type AuthHandler struct {
 	authService *service.AuthService
 	validate    *validator.Validate
+	publicURL   string
+	jwtCfg      *config.JWTConfig
 }
 
-func NewAuthHandler(authService *service.AuthService) *AuthHandler {
+func NewAuthHandler(authService *service.AuthService, publicURL string, jwtCfg *config.JWTConfig) *AuthHandler {
 	return &AuthHandler{
 		authService: authService,
 		validate:    validator.New(),
+		publicURL:   publicURL,
+		jwtCfg:      jwtCfg,
 	}
 }
 
@@ -26,7 +35,7 @@ func (h *AuthHandler) Register(c *gin.Context) {
 	var req domain.RegisterRequest
 	log.Printf("AuthHandler: h=%+v", h)
 	if err := c.ShouldBindJSON(&req); err != nil {
-		ValidationError(c, err)
+		BindError(c, err)
 		return
 	}
 
@@ -35,19 +44,19 @@ func (h *AuthHandler) Register(c *gin.Context) {
 		return
 	}
 
-	resp, err := h.authService.Register(c.Request.Context(), req)
+	resp, err := h.authService.Register(c.Request.Context(), req, clientInfo(c))
 	if err != nil {
 		ServiceError(c, err)
 		return
 	}
 
-	Success(c, http.StatusCreated, resp)
+	h.respondWithTokens(c, http.StatusCreated, resp)
 }
 
 func (h *AuthHandler) Login(c *gin.Context) {
 	var req domain.LoginRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		ValidationError(c, err)
+		BindError(c, err)
 		return
 	}
 
@@ -56,18 +65,155 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		return
 	}
 
-	resp, err := h.authService.Login(c.Request.Context(), req)
+	resp, err := h.authService.Login(c.Request.Context(), req, clientInfo(c))
 	if err != nil {
 		ServiceError(c, err)
 		return
 	}
 
-	Success(c, http.StatusOK, resp)
+	h.respondWithTokens(c, http.StatusOK, resp)
 }
 
 func (h *AuthHandler) RefreshToken(c *gin.Context) {
 	var req domain.RefreshRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
+	if token, ok := cookieToken(c, h.jwtCfg, refreshTokenCookie); ok {
+		req.RefreshToken = token
+	} else {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			BindError(c, err)
+			return
+		}
+
+		if err := h.validate.Struct(req); err != nil {
+			ValidationError(c, err)
+			return
+		}
+	}
+
+	resp, err := h.authService.RefreshToken(c.Request.Context(), req, clientInfo(c))
+	if err != nil {
+		ServiceError(c, err)
+		return
+	}
+
+	h.respondWithTokens(c, http.StatusOK, resp)
+}
+
+// Logout ends the current session and, in cookie auth mode, clears the auth
+// cookies
+func (h *AuthHandler) Logout(c *gin.Context) {
+	var req domain.LogoutRequest
+	if token, ok := cookieToken(c, h.jwtCfg, refreshTokenCookie); ok {
+		req.RefreshToken = token
+	} else {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			BindError(c, err)
+			return
+		}
+
+		if err := h.validate.Struct(req); err != nil {
+			ValidationError(c, err)
+			return
+		}
+	}
+
+	if err := h.authService.Logout(c.Request.Context(), req); err != nil {
+		ServiceError(c, err)
+		return
+	}
+
+	if h.jwtCfg.Cookies.Enabled {
+		clearAuthCookies(c, h.jwtCfg)
+	}
+
+	Success(c, http.StatusOK, nil)
+}
+
+// respondWithTokens sends a new token pair, moving it into cookies in cookie
+// auth mode
+func (h *AuthHandler) respondWithTokens(c *gin.Context, statusCode int, resp *domain.AuthResponse) {
+	if h.jwtCfg.Cookies.Enabled {
+		if err := setAuthCookies(c, h.jwtCfg, resp); err != nil {
+			ServiceError(c, err)
+			return
+		}
+	}
+
+	Success(c, statusCode, resp)
+}
+
+// Impersonate issues a short-lived token for acting as another user (admin only)
+func (h *AuthHandler) Impersonate(c *gin.Context) {
+	adminUUID, exists := GetUserUUID(c)
+	if !exists {
+		Error(c, http.StatusUnauthorized, ErrCodeUnauthorized,
+			"Unauthorized", "User not authenticated",
+			"Please login to impersonate a user")
+		return
+	}
+
+	// Impersonation tokens can't be used to start another impersonation
+	if _, impersonating := GetImpersonatorUUID(c); impersonating {
+		Error(c, http.StatusForbidden, ErrCodeForbidden,
+			"Forbidden", "Cannot impersonate while impersonating",
+			"Use your own admin token")
+		return
+	}
+
+	targetUUID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		Error(c, http.StatusBadRequest, ErrCodeValidationFailed,
+			"Invalid user ID", "User ID must be a valid UUID",
+			"Provide a valid user UUID")
+		return
+	}
+
+	resp, err := h.authService.Impersonate(c.Request.Context(), adminUUID, targetUUID)
+	if err != nil {
+		ServiceError(c, err)
+		return
+	}
+
+	c.Set(impersonationStartedKey, targetUUID)
+
+	Success(c, http.StatusOK, resp)
+}
+
+// CreateInvite creates an invite code for registering while registration is
+// closed (admin only)
+func (h *AuthHandler) CreateInvite(c *gin.Context) {
+	adminUUID, exists := GetUserUUID(c)
+	if !exists {
+		Error(c, http.StatusUnauthorized, ErrCodeUnauthorized,
+			"Unauthorized", "User not authenticated",
+			"Please login to create an invite")
+		return
+	}
+
+	var req domain.CreateInviteRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		BindError(c, err)
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		ValidationError(c, err)
+		return
+	}
+
+	invite, err := h.authService.CreateInvite(c.Request.Context(), adminUUID, req)
+	if err != nil {
+		ServiceError(c, err)
+		return
+	}
+
+	Success(c, http.StatusCreated, invite)
+}
+
+// ListSessions lists active sessions across users (admin only)
+func (h *AuthHandler) ListSessions(c *gin.Context) {
+	var req domain.ListSessionsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
 		ValidationError(c, err)
 		return
 	}
@@ -77,11 +223,46 @@ func (h *AuthHandler) RefreshToken(c *gin.Context) {
 		return
 	}
 
-	resp, err := h.authService.RefreshToken(c.Request.Context(), req)
+	resp, err := h.authService.ListSessions(c.Request.Context(), req)
 	if err != nil {
 		ServiceError(c, err)
 		return
 	}
 
+	resp.Links = paginationLinks(c, h.publicURL, resp.Page, resp.Limit, resp.TotalCount)
 	Success(c, http.StatusOK, resp)
 }
+
+// RevokeSession force-revokes a session (admin only)
+func (h *AuthHandler) RevokeSession(c *gin.Context) {
+	sessionUUID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		Error(c, http.StatusBadRequest, ErrCodeValidationFailed,
+			"Invalid session ID", "Session ID must be a valid UUID",
+			"Provide a valid session UUID")
+		return
+	}
+
+	if err := h.authService.RevokeSession(c.Request.Context(), sessionUUID); err != nil {
+		ServiceError(c, err)
+		return
+	}
+
+	Success(c, http.StatusOK, gin.H{"message": "Session revoked successfully"})
+}
+
+// maxUserAgentLength bounds the user agent stored with a session
+const maxUserAgentLength = 512
+
+// clientInfo describes the client making the request, for session records
+func clientInfo(c *gin.Context) domain.ClientInfo {
+	userAgent := c.Request.UserAgent()
+	if len(userAgent) > maxUserAgentLength {
+		userAgent = strings.ToValidUTF8(userAgent[:maxUserAgentLength], "")
+	}
+
+	return domain.ClientInfo{
+		UserAgent: userAgent,
+		IPAddress: c.ClientIP(),
+	}
+}
@@ -0,0 +1,134 @@
+package handler
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/saimonsiddique/blog-api/internal/config"
+	"github.com/saimonsiddique/blog-api/internal/domain"
+)
+
+// Cookies and header used by the cookie-based auth mode
+const (
+	accessTokenCookie  = "access_token"
+	refreshTokenCookie = "refresh_token"
+	csrfTokenCookie    = "csrf_token"
+	csrfTokenHeader    = "X-CSRF-Token"
+)
+
+// csrfTokenBytes is the amount of randomness in a CSRF token
+const csrfTokenBytes = 32
+
+// cookieToken returns the token stored in the named cookie when cookie auth
+// is enabled
+func cookieToken(c *gin.Context, cfg *config.JWTConfig, name string) (string, bool) {
+	if !cfg.Cookies.Enabled {
+		return "", false
+	}
+
+	value, err := c.Cookie(name)
+	if err != nil || value == "" {
+		return "", false
+	}
+	return value, true
+}
+
+// setAuthCookies stores a freshly issued token pair in HttpOnly cookies,
+// along with a new CSRF token, and removes the tokens from the response body
+// so scripts never see them
+func setAuthCookies(c *gin.Context, cfg *config.JWTConfig, resp *domain.AuthResponse) error {
+	csrfToken, err := newCSRFToken()
+	if err != nil {
+		return err
+	}
+
+	refreshMaxAge := int(cfg.RefreshTTL.Seconds())
+	setCookie(c, cfg, accessTokenCookie, resp.AccessToken, resp.ExpiresIn, true)
+	setCookie(c, cfg, refreshTokenCookie, resp.RefreshToken, refreshMaxAge, true)
+	setCookie(c, cfg, csrfTokenCookie, csrfToken, refreshMaxAge, false)
+
+	resp.AccessToken = ""
+	resp.RefreshToken = ""
+	resp.CSRFToken = csrfToken
+	return nil
+}
+
+// clearAuthCookies removes the auth and CSRF cookies
+func clearAuthCookies(c *gin.Context, cfg *config.JWTConfig) {
+	for _, name := range []string{accessTokenCookie, refreshTokenCookie, csrfTokenCookie} {
+		setCookie(c, cfg, name, "", -1, name != csrfTokenCookie)
+	}
+}
+
+func setCookie(c *gin.Context, cfg *config.JWTConfig, name, value string, maxAge int, httpOnly bool) {
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     "/",
+		Domain:   cfg.Cookies.Domain,
+		MaxAge:   maxAge,
+		Secure:   cfg.Cookies.Secure,
+		HttpOnly: httpOnly,
+		SameSite: sameSite(cfg.Cookies.SameSite),
+	})
+}
+
+func sameSite(mode string) http.SameSite {
+	switch mode {
+	case "strict":
+		return http.SameSiteStrictMode
+	case "none":
+		return http.SameSiteNoneMode
+	default:
+		return http.SameSiteLaxMode
+	}
+}
+
+func newCSRFToken() (string, error) {
+	b := make([]byte, csrfTokenBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// CSRFMiddleware enforces the double-submit CSRF check for cookie-based auth.
+// A mutating request that carries an auth cookie and no Authorization header
+// must send the CSRF cookie's value in the X-CSRF-Token header; a cross-site
+// page can make the browser send the cookies but can't read them to do so.
+func CSRFMiddleware(cfg *config.JWTConfig) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			c.Next()
+			return
+		}
+
+		if c.GetHeader("Authorization") != "" {
+			c.Next()
+			return
+		}
+
+		_, hasAccess := cookieToken(c, cfg, accessTokenCookie)
+		_, hasRefresh := cookieToken(c, cfg, refreshTokenCookie)
+		if !hasAccess && !hasRefresh {
+			c.Next()
+			return
+		}
+
+		expected, _ := c.Cookie(csrfTokenCookie)
+		provided := c.GetHeader(csrfTokenHeader)
+		if expected == "" || subtle.ConstantTimeCompare([]byte(expected), []byte(provided)) != 1 {
+			Error(c, http.StatusForbidden, ErrCodeCSRFFailed,
+				"CSRF check failed", "Missing or mismatched "+csrfTokenHeader+" header",
+				"Send the value of the "+csrfTokenCookie+" cookie in the "+csrfTokenHeader+" header")
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,16 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// BodyLimitMiddleware caps the request body at maxBytes. Reading past the cap
+// fails, and BindError reports it as PAYLOAD_TOO_LARGE.
+func BodyLimitMiddleware(maxBytes int64) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxBytes)
+		c.Next()
+	}
+}
@@ -0,0 +1,143 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+const redactedValue = "[REDACTED]"
+
+// sensitiveFields are JSON keys whose values are never logged. Keys are
+// compared lowercased with underscores removed, so "token_hash" and
+// "tokenHash" both match.
+var sensitiveFields = map[string]bool{
+	"password":     true,
+	"newpassword":  true,
+	"refreshtoken": true,
+	"accesstoken":  true,
+	"tokenhash":    true,
+	"csrftoken":    true,
+	"invitecode":   true,
+	"captchatoken": true,
+}
+
+// bodyLogWriter keeps a copy of the response body, up to maxBytes
+type bodyLogWriter struct {
+	gin.ResponseWriter
+	body     bytes.Buffer
+	maxBytes int
+	size     int
+}
+
+func (w *bodyLogWriter) Write(b []byte) (int, error) {
+	w.capture(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *bodyLogWriter) WriteString(s string) (int, error) {
+	w.capture([]byte(s))
+	return w.ResponseWriter.WriteString(s)
+}
+
+func (w *bodyLogWriter) capture(b []byte) {
+	w.size += len(b)
+	if remaining := w.maxBytes - w.body.Len(); remaining > 0 {
+		if len(b) > remaining {
+			b = b[:remaining]
+		}
+		w.body.Write(b)
+	}
+}
+
+// BodyLogMiddleware logs request and response bodies at debug level, with
+// sensitive JSON fields redacted. Bodies larger than maxBytes are not logged.
+// It must only be registered outside production.
+func BodyLogMiddleware(logger *logrus.Logger, maxBytes int) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var requestBody string
+		if c.Request.Body != nil {
+			// Read one byte past the limit to detect oversized bodies, then
+			// put everything back for the handler
+			read, err := io.ReadAll(io.LimitReader(c.Request.Body, int64(maxBytes)+1))
+			if err != nil {
+				logger.WithError(err).Debug("Could not read request body for logging")
+			}
+			c.Request.Body = struct {
+				io.Reader
+				io.Closer
+			}{io.MultiReader(bytes.NewReader(read), c.Request.Body), c.Request.Body}
+
+			if len(read) > maxBytes {
+				requestBody = fmt.Sprintf("[body exceeds %d bytes]", maxBytes)
+			} else {
+				requestBody = redactBody(read)
+			}
+		}
+
+		writer := &bodyLogWriter{ResponseWriter: c.Writer, maxBytes: maxBytes}
+		c.Writer = writer
+
+		c.Next()
+
+		responseBody := redactBody(writer.body.Bytes())
+		if writer.size > maxBytes {
+			responseBody = fmt.Sprintf("[body exceeds %d bytes]", maxBytes)
+		}
+
+		logger.WithFields(logrus.Fields{
+			"method":        c.Request.Method,
+			"path":          c.Request.URL.Path,
+			"status":        writer.Status(),
+			"request_body":  requestBody,
+			"response_body": responseBody,
+		}).Debug("HTTP body")
+	}
+}
+
+// redactBody returns the body as a string with sensitive JSON fields
+// replaced. Bodies that aren't JSON are omitted, since their fields can't be
+// redacted reliably.
+func redactBody(body []byte) string {
+	if len(bytes.TrimSpace(body)) == 0 {
+		return ""
+	}
+
+	var value interface{}
+	if err := json.Unmarshal(body, &value); err != nil {
+		return "[non-JSON body omitted]"
+	}
+
+	redacted, err := json.Marshal(redactValue(value))
+	if err != nil {
+		return "[body could not be encoded]"
+	}
+
+	return string(redacted)
+}
+
+func redactValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, field := range v {
+			if sensitiveFields[strings.ReplaceAll(strings.ToLower(key), "_", "")] {
+				v[key] = redactedValue
+				continue
+			}
+			v[key] = redactValue(field)
+		}
+		return v
+	case []interface{}:
+		for i, item := range v {
+			v[i] = redactValue(item)
+		}
+		return v
+	default:
+		return v
+	}
+}
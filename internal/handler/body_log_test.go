@@ -0,0 +1,38 @@
+package handler
+
+import "testing"
+
+func TestRedactBody(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{name: "empty", body: "", want: ""},
+		{name: "not JSON", body: "password=secret", want: "[non-JSON body omitted]"},
+		{
+			name: "registration",
+			body: `{"email":"a@example.com","password":"secret","inviteCode":"abc","captchaToken":"xyz"}`,
+			want: `{"captchaToken":"[REDACTED]","email":"a@example.com","inviteCode":"[REDACTED]","password":"[REDACTED]"}`,
+		},
+		{
+			name: "login response",
+			body: `{"data":{"accessToken":"a","refreshToken":"r","csrfToken":"c","user":{"username":"u"}}}`,
+			want: `{"data":{"accessToken":"[REDACTED]","csrfToken":"[REDACTED]","refreshToken":"[REDACTED]","user":{"username":"u"}}}`,
+		},
+		{
+			name: "snake case and nested lists",
+			body: `{"items":[{"token_hash":"h","new_password":"p","csrf_token":"c","invite_code":"i"}]}`,
+			want: `{"items":[{"csrf_token":"[REDACTED]","invite_code":"[REDACTED]","new_password":"[REDACTED]","token_hash":"[REDACTED]"}]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := redactBody([]byte(tt.body))
+			if got != tt.want {
+				t.Errorf("redactBody() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
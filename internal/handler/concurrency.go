@@ -0,0 +1,69 @@
+package handler
+
+import (
+	"net/http"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// UserConcurrencyLimiter caps the number of requests a single user can have
+// in flight at once. Unlike a rate limit it says nothing about requests over
+// time; a slot is freed as soon as a request completes.
+type UserConcurrencyLimiter struct {
+	limit    int
+	mu       sync.Mutex
+	inFlight map[uuid.UUID]int
+}
+
+func NewUserConcurrencyLimiter(limit int) *UserConcurrencyLimiter {
+	return &UserConcurrencyLimiter{
+		limit:    limit,
+		inFlight: make(map[uuid.UUID]int),
+	}
+}
+
+// Middleware rejects requests with 429 when the user already has the maximum
+// number of requests in flight. It must run after AuthMiddleware.
+func (l *UserConcurrencyLimiter) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userUUID, exists := GetUserUUID(c)
+		if !exists {
+			c.Next()
+			return
+		}
+
+		if !l.acquire(userUUID) {
+			Error(c, http.StatusTooManyRequests, ErrCodeTooManyRequests,
+				"Too many concurrent requests", "You have too many requests in progress",
+				"Wait for your in-progress requests to complete and try again")
+			c.Abort()
+			return
+		}
+		defer l.release(userUUID)
+
+		c.Next()
+	}
+}
+
+func (l *UserConcurrencyLimiter) acquire(userUUID uuid.UUID) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.inFlight[userUUID] >= l.limit {
+		return false
+	}
+	l.inFlight[userUUID]++
+	return true
+}
+
+func (l *UserConcurrencyLimiter) release(userUUID uuid.UUID) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.inFlight[userUUID]--
+	if l.inFlight[userUUID] <= 0 {
+		delete(l.inFlight, userUUID)
+	}
+}
@@ -0,0 +1,101 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	corsAllowedMethods = "GET, POST, PUT, DELETE, OPTIONS"
+	corsAllowedHeaders = "Authorization, Content-Type, Accept, X-CSRF-Token"
+	corsMaxAge         = 600
+)
+
+// CORSPolicy describes which browser origins may call a group of routes.
+// An empty origin list disables CORS headers; "*" allows any origin, but
+// never with credentials.
+type CORSPolicy struct {
+	AllowedOrigins   []string
+	AllowCredentials bool
+}
+
+// allows reports whether the origin is allowed, and whether it is listed by
+// name rather than only matched by "*"
+func (p CORSPolicy) allows(origin string) (allowed, listed bool) {
+	for _, allowed := range p.AllowedOrigins {
+		if strings.EqualFold(allowed, origin) {
+			return true, true
+		}
+	}
+	for _, allowed := range p.AllowedOrigins {
+		if allowed == "*" {
+			return true, false
+		}
+	}
+	return false, false
+}
+
+// setHeaders echoes a listed origin back, with credentials allowed if the
+// policy allows them. An origin only matched by "*" gets "*" and never
+// credentials, so a wildcard can't expose authenticated responses to any
+// site.
+func (p CORSPolicy) setHeaders(c *gin.Context, origin string, listed bool) {
+	if !listed {
+		c.Header("Access-Control-Allow-Origin", "*")
+		return
+	}
+
+	c.Header("Access-Control-Allow-Origin", origin)
+	if p.AllowCredentials {
+		c.Header("Access-Control-Allow-Credentials", "true")
+	}
+}
+
+// CORSMiddleware adds CORS headers to responses for allowed origins. It is
+// applied per route group so public and authenticated routes can differ.
+func CORSMiddleware(policy CORSPolicy) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Writer.Header().Add("Vary", "Origin")
+
+		if origin := c.GetHeader("Origin"); origin != "" {
+			if allowed, listed := policy.allows(origin); allowed {
+				policy.setHeaders(c, origin, listed)
+			}
+		}
+
+		c.Next()
+	}
+}
+
+// CORSPreflightMiddleware answers preflight requests before routing, since
+// routes don't register OPTIONS handlers. Preflights for safe methods are
+// checked against the public policy and all others against the protected
+// policy; the route group's own policy still governs the actual response.
+func CORSPreflightMiddleware(public, protected CORSPolicy) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		origin := c.GetHeader("Origin")
+		method := c.GetHeader("Access-Control-Request-Method")
+		if c.Request.Method != http.MethodOptions || origin == "" || method == "" {
+			c.Next()
+			return
+		}
+
+		policy := protected
+		if method == http.MethodGet || method == http.MethodHead {
+			policy = public
+		}
+
+		c.Writer.Header().Add("Vary", "Origin")
+		if allowed, listed := policy.allows(origin); allowed {
+			policy.setHeaders(c, origin, listed)
+			c.Header("Access-Control-Allow-Methods", corsAllowedMethods)
+			c.Header("Access-Control-Allow-Headers", corsAllowedHeaders)
+			c.Header("Access-Control-Max-Age", strconv.Itoa(corsMaxAge))
+		}
+
+		c.AbortWithStatus(http.StatusNoContent)
+	}
+}
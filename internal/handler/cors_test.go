@@ -0,0 +1,79 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestCORSMiddleware(t *testing.T) {
+	tests := []struct {
+		name            string
+		policy          CORSPolicy
+		origin          string
+		wantOrigin      string
+		wantCredentials bool
+	}{
+		{
+			name:            "listed origin with credentials",
+			policy:          CORSPolicy{AllowedOrigins: []string{"https://app.example.com"}, AllowCredentials: true},
+			origin:          "https://app.example.com",
+			wantOrigin:      "https://app.example.com",
+			wantCredentials: true,
+		},
+		{
+			name:       "listed origin without credentials",
+			policy:     CORSPolicy{AllowedOrigins: []string{"https://app.example.com"}},
+			origin:     "https://app.example.com",
+			wantOrigin: "https://app.example.com",
+		},
+		{
+			name:       "wildcard never allows credentials",
+			policy:     CORSPolicy{AllowedOrigins: []string{"*"}, AllowCredentials: true},
+			origin:     "https://evil.example.com",
+			wantOrigin: "*",
+		},
+		{
+			name:            "listed origin alongside a wildcard",
+			policy:          CORSPolicy{AllowedOrigins: []string{"*", "https://app.example.com"}, AllowCredentials: true},
+			origin:          "https://app.example.com",
+			wantOrigin:      "https://app.example.com",
+			wantCredentials: true,
+		},
+		{
+			name:   "unlisted origin",
+			policy: CORSPolicy{AllowedOrigins: []string{"https://app.example.com"}, AllowCredentials: true},
+			origin: "https://evil.example.com",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, preflight := range []bool{false, true} {
+				req := httptest.NewRequest(http.MethodGet, "/", nil)
+				req.Header.Set("Origin", tt.origin)
+				handlers := []gin.HandlerFunc{CORSMiddleware(tt.policy), func(c *gin.Context) { c.Status(http.StatusOK) }}
+				if preflight {
+					req.Method = http.MethodOptions
+					req.Header.Set("Access-Control-Request-Method", http.MethodPost)
+					handlers = []gin.HandlerFunc{CORSPreflightMiddleware(CORSPolicy{}, tt.policy)}
+				}
+
+				gin.SetMode(gin.TestMode)
+				router := gin.New()
+				router.Handle(req.Method, "/", handlers...)
+				w := httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+
+				if got := w.Header().Get("Access-Control-Allow-Origin"); got != tt.wantOrigin {
+					t.Errorf("preflight=%v: Allow-Origin = %q, want %q", preflight, got, tt.wantOrigin)
+				}
+				if got := w.Header().Get("Access-Control-Allow-Credentials") == "true"; got != tt.wantCredentials {
+					t.Errorf("preflight=%v: Allow-Credentials = %v, want %v", preflight, got, tt.wantCredentials)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package handler
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/saimonsiddique/blog-api/internal/database"
+)
+
+const (
+	debugHeader        = "X-Debug"
+	debugQueriesHeader = "X-Debug-DB-Queries"
+	debugDBTimeHeader  = "X-Debug-DB-Time-Ms"
+)
+
+// debugWriter adds the collected query stats as headers before the response is written
+type debugWriter struct {
+	gin.ResponseWriter
+	stats *database.QueryStats
+}
+
+func (w *debugWriter) WriteHeader(code int) {
+	count, duration := w.stats.Snapshot()
+	w.Header().Set(debugQueriesHeader, strconv.Itoa(count))
+	w.Header().Set(debugDBTimeHeader, strconv.FormatFloat(float64(duration.Microseconds())/1000, 'f', 3, 64))
+	w.ResponseWriter.WriteHeader(code)
+}
+
+// QueryDebugMiddleware reports the number of DB queries and total DB time for
+// requests sent with "X-Debug: true". It must only be registered outside production.
+func QueryDebugMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetHeader(debugHeader) != "true" {
+			c.Next()
+			return
+		}
+
+		ctx, stats := database.WithQueryStats(c.Request.Context())
+		c.Request = c.Request.WithContext(ctx)
+		c.Writer = &debugWriter{ResponseWriter: c.Writer, stats: stats}
+
+		c.Next()
+	}
+}
@@ -4,14 +4,35 @@ const (
 	ErrCodeUnauthorized         = "UNAUTHORIZED"
 	ErrCodeInvalidCredentials   = "INVALID_CREDENTIALS"
 	ErrCodeUserNotFound         = "USER_NOT_FOUND"
+	ErrCodeUserInactive         = "USER_INACTIVE"
+	ErrCodeSessionNotFound      = "SESSION_NOT_FOUND"
 	ErrCodeEmailTaken           = "EMAIL_TAKEN"
 	ErrCodeUsernameTaken        = "USERNAME_TAKEN"
+	ErrCodeUsernameReserved     = "USERNAME_RESERVED"
 	ErrCodePostNotFound         = "POST_NOT_FOUND"
 	ErrCodeSlugTaken            = "SLUG_TAKEN"
+	ErrCodeDuplicatePost        = "DUPLICATE_POST"
 	ErrCodePostAlreadyPublished = "POST_ALREADY_PUBLISHED"
 	ErrCodeInvalidStatusChange  = "INVALID_STATUS_CHANGE"
+	ErrCodePostNotReady         = "POST_NOT_READY"
 	ErrCodeForbidden            = "FORBIDDEN"
 	ErrCodeValidationFailed     = "VALIDATION_FAILED"
+	ErrCodeInvalidRequestBody   = "INVALID_REQUEST_BODY"
+	ErrCodePayloadTooLarge      = "PAYLOAD_TOO_LARGE"
+	ErrCodeTooManyRequests      = "TOO_MANY_REQUESTS"
+	ErrCodePreconditionFailed   = "PRECONDITION_FAILED"
 	ErrCodeInternalServer       = "INTERNAL_SERVER_ERROR"
+	ErrCodeServiceUnavailable   = "SERVICE_UNAVAILABLE"
 	ErrCodeConflict             = "CONFLICT"
+	ErrCodeSeriesNotFound       = "SERIES_NOT_FOUND"
+	ErrCodePostInSeries         = "POST_IN_SERIES"
+	ErrCodeInvalidSeriesOrder   = "INVALID_SERIES_ORDER"
+	ErrCodeFeatureFlagNotFound  = "FEATURE_FLAG_NOT_FOUND"
+	ErrCodeRegistrationClosed   = "REGISTRATION_CLOSED"
+	ErrCodeInvalidInvite        = "INVALID_INVITE"
+	ErrCodeInviteUsed           = "INVITE_USED"
+	ErrCodeInviteExpired        = "INVITE_EXPIRED"
+	ErrCodeCaptchaFailed        = "CAPTCHA_FAILED"
+	ErrCodeCSRFFailed           = "CSRF_FAILED"
+	ErrCodeNotificationNotFound = "NOTIFICATION_NOT_FOUND"
 )
@@ -0,0 +1,91 @@
+package handler
+
+import (
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/saimonsiddique/blog-api/internal/domain"
+	"github.com/saimonsiddique/blog-api/internal/events"
+)
+
+const (
+	eventPostPublished      = "post.published"
+	eventsHeartbeatInterval = 15 * time.Second
+)
+
+type EventsHandler struct {
+	broker *events.Broker[domain.PostPublishedNotification]
+}
+
+func NewEventsHandler(broker *events.Broker[domain.PostPublishedNotification]) *EventsHandler {
+	return &EventsHandler{
+		broker: broker,
+	}
+}
+
+// Stream sends post publish notifications as Server-Sent Events, optionally
+// filtered to one author with ?author=<uuid>. Comment pings are sent
+// periodically to keep idle connections open through proxies.
+func (h *EventsHandler) Stream(c *gin.Context) {
+	var authorUUID *uuid.UUID
+	if author := c.Query("author"); author != "" {
+		parsed, err := uuid.Parse(author)
+		if err != nil {
+			Error(c, http.StatusBadRequest, ErrCodeValidationFailed,
+				"Invalid author ID", "Author ID must be a valid UUID",
+				"Provide a valid author UUID")
+			return
+		}
+		authorUUID = &parsed
+	}
+
+	var match func(domain.PostPublishedNotification) bool
+	if authorUUID != nil {
+		match = func(notification domain.PostPublishedNotification) bool {
+			return notification.AuthorUUID == *authorUUID
+		}
+	}
+
+	notifications, unsubscribe := h.broker.Subscribe(match)
+	defer unsubscribe()
+
+	openEventStream(c)
+
+	heartbeat := time.NewTicker(eventsHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case notification, ok := <-notifications:
+			if !ok {
+				return
+			}
+			c.SSEvent(eventPostPublished, notification)
+			c.Writer.Flush()
+		case <-heartbeat.C:
+			if _, err := io.WriteString(c.Writer, ": ping\n\n"); err != nil {
+				return
+			}
+			c.Writer.Flush()
+		}
+	}
+}
+
+// openEventStream starts a Server-Sent Events response
+func openEventStream(c *gin.Context) {
+	// The stream outlives the server's write timeout; clear the deadline
+	// where the writer supports it
+	_ = http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{})
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	c.Writer.Flush()
+}
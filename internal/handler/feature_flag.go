@@ -0,0 +1,59 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/saimonsiddique/blog-api/internal/domain"
+	"github.com/saimonsiddique/blog-api/internal/service"
+	"github.com/sirupsen/logrus"
+)
+
+type FeatureFlagHandler struct {
+	service  *service.FeatureFlagService
+	validate *validator.Validate
+}
+
+func NewFeatureFlagHandler(service *service.FeatureFlagService) *FeatureFlagHandler {
+	return &FeatureFlagHandler{
+		service:  service,
+		validate: validator.New(),
+	}
+}
+
+// ListFlags returns every feature flag with its current value (admin only)
+func (h *FeatureFlagHandler) ListFlags(c *gin.Context) {
+	Success(c, http.StatusOK, h.service.List())
+}
+
+// SetFlag turns a feature flag on or off (admin only)
+func (h *FeatureFlagHandler) SetFlag(c *gin.Context) {
+	key := c.Param("key")
+
+	// Parse request
+	var req domain.SetFeatureFlagRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		BindError(c, err)
+		return
+	}
+
+	// Validate
+	if err := h.validate.Struct(req); err != nil {
+		ValidationError(c, err)
+		return
+	}
+
+	flag, err := h.service.Set(c.Request.Context(), key, *req.Enabled)
+	if err != nil {
+		ServiceError(c, err)
+		return
+	}
+
+	setAuditEntry(c, "feature_flag_set", logrus.Fields{
+		"flag":    flag.Key,
+		"enabled": flag.Enabled,
+	})
+
+	Success(c, http.StatusOK, flag)
+}
@@ -0,0 +1,107 @@
+package handler
+
+import (
+	"net"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// forwardedTrustedKey holds whether the request came straight from a trusted
+// proxy
+const forwardedTrustedKey = "forwardedTrusted"
+
+// TrustedProxyMiddleware records whether the request came straight from one
+// of the trusted proxies, given as IPs or CIDRs. Only then are its
+// X-Forwarded-Proto and X-Forwarded-Host headers believed; any client can set
+// them. With no trusted proxies they are never believed.
+func TrustedProxyMiddleware(trustedProxies []string) gin.HandlerFunc {
+	var networks []*net.IPNet
+	for _, proxy := range trustedProxies {
+		if !strings.Contains(proxy, "/") {
+			if ip := net.ParseIP(proxy); ip != nil && ip.To4() != nil {
+				proxy += "/32"
+			} else {
+				proxy += "/128"
+			}
+		}
+		if _, network, err := net.ParseCIDR(proxy); err == nil {
+			networks = append(networks, network)
+		}
+	}
+
+	return func(c *gin.Context) {
+		if peer := net.ParseIP(c.RemoteIP()); peer != nil {
+			for _, network := range networks {
+				if network.Contains(peer) {
+					c.Set(forwardedTrustedKey, true)
+					break
+				}
+			}
+		}
+		c.Next()
+	}
+}
+
+// forwardedHeader returns a forwarded header's first value if the request came
+// from a trusted proxy, and "" otherwise
+func forwardedHeader(c *gin.Context, name string) string {
+	if !c.GetBool(forwardedTrustedKey) {
+		return ""
+	}
+	value, _, _ := strings.Cut(c.GetHeader(name), ",")
+	return strings.TrimSpace(value)
+}
+
+// requestBaseURL returns the scheme and host the request was made to, or ""
+// if the host isn't a plain host name or address with an optional port.
+// Forwarded headers are only used from trusted proxies.
+func requestBaseURL(c *gin.Context) string {
+	scheme := "http"
+	if c.Request.TLS != nil {
+		scheme = "https"
+	}
+	if proto := forwardedHeader(c, "X-Forwarded-Proto"); proto == "http" || proto == "https" {
+		scheme = proto
+	}
+
+	host := c.Request.Host
+	if forwarded := forwardedHeader(c, "X-Forwarded-Host"); forwarded != "" {
+		host = forwarded
+	}
+	if !validHost(host) {
+		return ""
+	}
+
+	return scheme + "://" + host
+}
+
+// validHost reports whether host is a host name, IPv4 address or bracketed
+// IPv6 address, with an optional port
+func validHost(host string) bool {
+	if host == "" {
+		return false
+	}
+
+	name := host
+	if h, port, err := net.SplitHostPort(host); err == nil {
+		if port == "" || strings.Trim(port, "0123456789") != "" {
+			return false
+		}
+		name = h
+		if strings.Contains(name, ":") {
+			return net.ParseIP(name) != nil
+		}
+	} else if strings.HasPrefix(host, "[") && strings.HasSuffix(host, "]") {
+		return net.ParseIP(host[1:len(host)-1]) != nil
+	}
+
+	for _, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '.':
+		default:
+			return false
+		}
+	}
+	return name != ""
+}
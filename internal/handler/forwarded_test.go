@@ -0,0 +1,154 @@
+package handler
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/saimonsiddique/blog-api/internal/domain"
+)
+
+func TestPaginationLinksBaseURL(t *testing.T) {
+	tests := []struct {
+		name      string
+		publicURL string
+		trusted   []string
+		peer      string
+		host      string
+		tls       bool
+		headers   map[string]string
+		wantFirst string
+	}{
+		{
+			name:      "configured public URL",
+			publicURL: "https://api.example.com",
+			host:      "evil.example.com",
+			headers:   map[string]string{"X-Forwarded-Proto": "http"},
+			wantFirst: "https://api.example.com/posts?limit=10&page=1",
+		},
+		{
+			name:      "request host",
+			host:      "api.example.com:8080",
+			wantFirst: "http://api.example.com:8080/posts?limit=10&page=1",
+		},
+		{
+			name:      "TLS",
+			host:      "api.example.com",
+			tls:       true,
+			wantFirst: "https://api.example.com/posts?limit=10&page=1",
+		},
+		{
+			name:      "forwarded headers from an untrusted peer",
+			trusted:   []string{"10.0.0.0/8"},
+			peer:      "192.0.2.1:1234",
+			host:      "api.example.com",
+			headers:   map[string]string{"X-Forwarded-Proto": "https", "X-Forwarded-Host": "evil.example.com"},
+			wantFirst: "http://api.example.com/posts?limit=10&page=1",
+		},
+		{
+			name:      "forwarded headers with no trusted proxies",
+			peer:      "10.1.2.3:1234",
+			host:      "api.example.com",
+			headers:   map[string]string{"X-Forwarded-Proto": "https", "X-Forwarded-Host": "evil.example.com"},
+			wantFirst: "http://api.example.com/posts?limit=10&page=1",
+		},
+		{
+			name:      "forwarded headers from a trusted network",
+			trusted:   []string{"10.0.0.0/8"},
+			peer:      "10.1.2.3:1234",
+			host:      "internal:8080",
+			headers:   map[string]string{"X-Forwarded-Proto": "https", "X-Forwarded-Host": "api.example.com, internal"},
+			wantFirst: "https://api.example.com/posts?limit=10&page=1",
+		},
+		{
+			name:      "forwarded headers from a trusted address",
+			trusted:   []string{"10.1.2.3"},
+			peer:      "10.1.2.3:1234",
+			host:      "internal:8080",
+			headers:   map[string]string{"X-Forwarded-Proto": "https"},
+			wantFirst: "https://internal:8080/posts?limit=10&page=1",
+		},
+		{
+			name:      "unsupported forwarded scheme",
+			trusted:   []string{"10.1.2.3"},
+			peer:      "10.1.2.3:1234",
+			host:      "api.example.com",
+			headers:   map[string]string{"X-Forwarded-Proto": "javascript"},
+			wantFirst: "http://api.example.com/posts?limit=10&page=1",
+		},
+		{
+			name:      "IPv6 host",
+			host:      "[::1]:8080",
+			wantFirst: "http://[::1]:8080/posts?limit=10&page=1",
+		},
+		{
+			name:      "malformed host gives relative links",
+			host:      "evil.example.com/phish?",
+			wantFirst: "/posts?limit=10&page=1",
+		},
+		{
+			name:      "host with credentials gives relative links",
+			host:      "user@evil.example.com",
+			wantFirst: "/posts?limit=10&page=1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/posts", nil)
+			req.Host = tt.host
+			if tt.peer != "" {
+				req.RemoteAddr = tt.peer
+			}
+			if tt.tls {
+				req.TLS = &tls.ConnectionState{}
+			}
+			for name, value := range tt.headers {
+				req.Header.Set(name, value)
+			}
+
+			var links domain.PaginationLinks
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.Use(TrustedProxyMiddleware(tt.trusted))
+			router.GET("/posts", func(c *gin.Context) {
+				links = paginationLinks(c, tt.publicURL, 1, 10, 25)
+			})
+			router.ServeHTTP(httptest.NewRecorder(), req)
+
+			if links.First != tt.wantFirst {
+				t.Errorf("first link = %q, want %q", links.First, tt.wantFirst)
+			}
+		})
+	}
+}
+
+func TestValidHost(t *testing.T) {
+	tests := []struct {
+		host string
+		want bool
+	}{
+		{host: "api.example.com", want: true},
+		{host: "api.example.com:443", want: true},
+		{host: "localhost", want: true},
+		{host: "127.0.0.1:8080", want: true},
+		{host: "[2001:db8::1]", want: true},
+		{host: "[2001:db8::1]:443", want: true},
+		{host: "", want: false},
+		{host: "api.example.com:", want: false},
+		{host: "api.example.com:http", want: false},
+		{host: "evil.com/path", want: false},
+		{host: "user@evil.com", want: false},
+		{host: "evil.com\\@good.com", want: false},
+		{host: "[not-an-ip]", want: false},
+		{host: "2001:db8::1", want: false},
+	}
+
+	for _, tt := range tests {
+		if got := validHost(tt.host); got != tt.want {
+			t.Errorf("validHost(%q) = %v, want %v", tt.host, got, tt.want)
+		}
+	}
+}
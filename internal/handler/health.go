@@ -8,18 +8,27 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/saimonsiddique/blog-api/internal/domain"
+	"github.com/saimonsiddique/blog-api/internal/pkg/version"
 )
 
 type HealthHandler struct {
-	db *pgxpool.Pool
+	db           *pgxpool.Pool
+	dependencies domain.DependencyVersions
 }
 
-func NewHealthHandler(db *pgxpool.Pool) *HealthHandler {
+func NewHealthHandler(db *pgxpool.Pool, dependencies domain.DependencyVersions) *HealthHandler {
 	return &HealthHandler{
-		db: db,
+		db:           db,
+		dependencies: dependencies,
 	}
 }
 
+// Ping reports that the process is up without checking any dependencies, for
+// cheap, frequent liveness probes. Use HealthCheck for deeper checks.
+func (h *HealthHandler) Ping(c *gin.Context) {
+	Raw(c, http.StatusOK, "text/plain; charset=utf-8", []byte("pong"))
+}
+
 func (h *HealthHandler) HealthCheck(c *gin.Context) {
 	dbStatus := "connected"
 
@@ -39,3 +48,33 @@ func (h *HealthHandler) HealthCheck(c *gin.Context) {
 
 	Success(c, http.StatusOK, response)
 }
+
+// ReadinessCheck reports whether the service can serve traffic, along with
+// build and dependency versions for quick inspection
+func (h *HealthHandler) ReadinessCheck(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 2*time.Second)
+	defer cancel()
+
+	response := domain.ReadinessResponse{
+		Status:       "ready",
+		Timestamp:    time.Now().Format(time.RFC3339),
+		Database:     "connected",
+		Build:        version.Get(),
+		Dependencies: h.dependencies,
+	}
+
+	statusCode := http.StatusOK
+	if err := h.db.Ping(ctx); err != nil {
+		response.Status = "not ready"
+		response.Database = "disconnected"
+		statusCode = http.StatusServiceUnavailable
+	}
+
+	Success(c, statusCode, response)
+}
+
+// Version returns the build metadata of the running binary. It never touches
+// the database so it stays cheap to call.
+func (h *HealthHandler) Version(c *gin.Context) {
+	Success(c, http.StatusOK, version.Get())
+}
@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 	"strings"
 
@@ -12,39 +13,44 @@ import (
 )
 
 const (
-	userUUIDKey = "userUUID"
-	userRoleKey = "userRole"
+	userUUIDKey         = "userUUID"
+	userRoleKey         = "userRole"
+	impersonatorUUIDKey = "impersonatorUUID"
 )
 
 func AuthMiddleware(cfg *config.JWTConfig) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
+		var tokenString string
 		if authHeader == "" {
-			Error(c, http.StatusUnauthorized, ErrCodeUnauthorized,
-				"Missing authorization header", "No authorization token provided",
-				"Include 'Authorization: Bearer <token>' header")
-			c.Abort()
-			return
+			// In cookie auth mode, fall back to the access token cookie
+			cookie, ok := cookieToken(c, cfg, accessTokenCookie)
+			if !ok {
+				Error(c, http.StatusUnauthorized, ErrCodeUnauthorized,
+					"Missing authorization header", "No authorization token provided",
+					"Include 'Authorization: Bearer <token>' header")
+				c.Abort()
+				return
+			}
+			tokenString = cookie
+		} else {
+			parts := strings.Split(authHeader, " ")
+			if len(parts) != 2 || parts[0] != "Bearer" {
+				Error(c, http.StatusUnauthorized, ErrCodeUnauthorized,
+					"Invalid authorization header", "Authorization header must be 'Bearer <token>'",
+					"Use format 'Authorization: Bearer <token>'")
+				c.Abort()
+				return
+			}
+			tokenString = parts[1]
 		}
 
-		parts := strings.Split(authHeader, " ")
-		if len(parts) != 2 || parts[0] != "Bearer" {
-			Error(c, http.StatusUnauthorized, ErrCodeUnauthorized,
-				"Invalid authorization header", "Authorization header must be 'Bearer <token>'",
-				"Use format 'Authorization: Bearer <token>'")
-			c.Abort()
-			return
+		// Try the current secret first, then the previous one during rotation
+		token, err := parseToken(tokenString, cfg.Secret)
+		if err != nil && errors.Is(err, jwt.ErrTokenSignatureInvalid) && cfg.PreviousSecret != "" {
+			token, err = parseToken(tokenString, cfg.PreviousSecret)
 		}
 
-		tokenString := parts[1]
-
-		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, domain.ErrInvalidToken
-			}
-			return []byte(cfg.Secret), nil
-		})
-
 		if err != nil || !token.Valid {
 			Error(c, http.StatusUnauthorized, ErrCodeUnauthorized,
 				"Invalid token", err.Error(),
@@ -80,15 +86,116 @@ func AuthMiddleware(cfg *config.JWTConfig) gin.HandlerFunc {
 			return
 		}
 
-		role, _ := claims["role"].(string)
+		role, ok := roleFromClaims(claims)
+		if !ok {
+			Error(c, http.StatusUnauthorized, ErrCodeUnauthorized,
+				"Invalid token claims", "Missing or unrecognized role in token",
+				"Please login again")
+			c.Abort()
+			return
+		}
+
+		c.Set(userUUIDKey, userUUID)
+		c.Set(userRoleKey, string(role))
+		setImpersonator(c, claims)
+
+		c.Next()
+	}
+}
+
+// OptionalAuthMiddleware sets the user in the context when a valid bearer
+// token (or, in cookie auth mode, access token cookie) is provided, and lets
+// anonymous requests through otherwise.
+func OptionalAuthMiddleware(cfg *config.JWTConfig) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var tokenString string
+		if authHeader := c.GetHeader("Authorization"); authHeader != "" {
+			parts := strings.Split(authHeader, " ")
+			if len(parts) != 2 || parts[0] != "Bearer" {
+				c.Next()
+				return
+			}
+			tokenString = parts[1]
+		} else if cookie, ok := cookieToken(c, cfg, accessTokenCookie); ok {
+			tokenString = cookie
+		} else {
+			c.Next()
+			return
+		}
+
+		token, err := parseToken(tokenString, cfg.Secret)
+		if err != nil && errors.Is(err, jwt.ErrTokenSignatureInvalid) && cfg.PreviousSecret != "" {
+			token, err = parseToken(tokenString, cfg.PreviousSecret)
+		}
+		if err != nil || !token.Valid {
+			c.Next()
+			return
+		}
+
+		claims, ok := token.Claims.(jwt.MapClaims)
+		if !ok {
+			c.Next()
+			return
+		}
+
+		userUUIDStr, _ := claims["sub"].(string)
+		userUUID, err := uuid.Parse(userUUIDStr)
+		if err != nil {
+			c.Next()
+			return
+		}
+
+		role, ok := roleFromClaims(claims)
+		if !ok {
+			c.Next()
+			return
+		}
 
 		c.Set(userUUIDKey, userUUID)
-		c.Set(userRoleKey, role)
+		c.Set(userRoleKey, string(role))
+		setImpersonator(c, claims)
 
 		c.Next()
 	}
 }
 
+// roleFromClaims reads the role claim, reporting false when it is missing,
+// not a string or not a known role
+func roleFromClaims(claims jwt.MapClaims) (domain.UserRole, bool) {
+	roleStr, ok := claims["role"].(string)
+	if !ok {
+		return "", false
+	}
+
+	role := domain.UserRole(roleStr)
+	if !role.IsValid() {
+		return "", false
+	}
+
+	return role, true
+}
+
+// setImpersonator records the admin behind an impersonation token
+func setImpersonator(c *gin.Context, claims jwt.MapClaims) {
+	imp, ok := claims["imp"].(string)
+	if !ok {
+		return
+	}
+
+	if impersonatorUUID, err := uuid.Parse(imp); err == nil {
+		c.Set(impersonatorUUIDKey, impersonatorUUID)
+	}
+}
+
+func parseToken(tokenString, secret string) (*jwt.Token, error) {
+	return jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, domain.ErrInvalidToken
+		}
+		return []byte(secret), nil
+	})
+}
+
 func RequireRole(allowedRoles ...domain.UserRole) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		role, exists := c.Get(userRoleKey)
@@ -124,6 +231,25 @@ func GetUserUUID(c *gin.Context) (uuid.UUID, bool) {
 	return userUUID.(uuid.UUID), true
 }
 
+// GetImpersonatorUUID returns the admin's UUID when the request uses an
+// impersonation token
+func GetImpersonatorUUID(c *gin.Context) (uuid.UUID, bool) {
+	impersonatorUUID, exists := c.Get(impersonatorUUIDKey)
+	if !exists {
+		return uuid.UUID{}, false
+	}
+	return impersonatorUUID.(uuid.UUID), true
+}
+
+// GetViewerUUID returns the authenticated user's UUID, or nil for anonymous requests
+func GetViewerUUID(c *gin.Context) *uuid.UUID {
+	userUUID, exists := GetUserUUID(c)
+	if !exists {
+		return nil
+	}
+	return &userUUID
+}
+
 func GetUserRole(c *gin.Context) (domain.UserRole, bool) {
 	role, exists := c.Get(userRoleKey)
 	if !exists {
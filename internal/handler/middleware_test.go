@@ -0,0 +1,95 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/saimonsiddique/blog-api/internal/config"
+	"github.com/saimonsiddique/blog-api/internal/domain"
+)
+
+const testJWTSecret = "test-secret"
+
+func TestRoleFromClaims(t *testing.T) {
+	tests := []struct {
+		name   string
+		claims jwt.MapClaims
+		want   domain.UserRole
+		wantOK bool
+	}{
+		{name: "user", claims: jwt.MapClaims{"role": "user"}, want: domain.RoleUser, wantOK: true},
+		{name: "admin", claims: jwt.MapClaims{"role": "admin"}, want: domain.RoleAdmin, wantOK: true},
+		{name: "missing", claims: jwt.MapClaims{}},
+		{name: "null", claims: jwt.MapClaims{"role": nil}},
+		{name: "number", claims: jwt.MapClaims{"role": float64(1)}},
+		{name: "list", claims: jwt.MapClaims{"role": []interface{}{"admin"}}},
+		{name: "empty", claims: jwt.MapClaims{"role": ""}},
+		{name: "unknown", claims: jwt.MapClaims{"role": "superuser"}},
+		{name: "wrong case", claims: jwt.MapClaims{"role": "Admin"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := roleFromClaims(tt.claims)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("roleFromClaims() = (%q, %v), want (%q, %v)", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestAuthMiddlewareRoleClaim(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name       string
+		role       interface{}
+		omitRole   bool
+		wantStatus int
+	}{
+		{name: "known role", role: "admin", wantStatus: http.StatusOK},
+		{name: "missing role", omitRole: true, wantStatus: http.StatusUnauthorized},
+		{name: "non-string role", role: 42, wantStatus: http.StatusUnauthorized},
+		{name: "unknown role", role: "superuser", wantStatus: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims := jwt.MapClaims{
+				"sub": uuid.New().String(),
+				"exp": time.Now().Add(time.Minute).Unix(),
+			}
+			if !tt.omitRole {
+				claims["role"] = tt.role
+			}
+			token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(testJWTSecret))
+			if err != nil {
+				t.Fatalf("failed to sign token: %v", err)
+			}
+
+			var gotRole domain.UserRole
+			router := gin.New()
+			router.GET("/", AuthMiddleware(&config.JWTConfig{Secret: testJWTSecret}), func(c *gin.Context) {
+				gotRole, _ = GetUserRole(c)
+				c.Status(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if tt.wantStatus == http.StatusOK && gotRole != domain.UserRole(tt.role.(string)) {
+				t.Errorf("role = %q, want %q", gotRole, tt.role)
+			}
+		})
+	}
+}
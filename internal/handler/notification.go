@@ -0,0 +1,217 @@
+package handler
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-contrib/sse"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/saimonsiddique/blog-api/internal/domain"
+	"github.com/saimonsiddique/blog-api/internal/service"
+)
+
+const (
+	eventNotification          = "notification"
+	lastEventIDHeader          = "Last-Event-ID"
+	notificationsRetryInterval = 3 * time.Second
+)
+
+type NotificationHandler struct {
+	service   *service.NotificationService
+	validate  *validator.Validate
+	publicURL string
+}
+
+func NewNotificationHandler(service *service.NotificationService, publicURL string) *NotificationHandler {
+	return &NotificationHandler{
+		service:   service,
+		validate:  validator.New(),
+		publicURL: publicURL,
+	}
+}
+
+// ListNotifications lists the current user's notifications, optionally only
+// unread ones with ?unread=true
+func (h *NotificationHandler) ListNotifications(c *gin.Context) {
+	// Get user UUID from context
+	userUUID, exists := GetUserUUID(c)
+	if !exists {
+		Error(c, http.StatusUnauthorized, ErrCodeUnauthorized,
+			"Unauthorized", "User not authenticated",
+			"Please login to view your notifications")
+		return
+	}
+
+	// Parse query parameters
+	var req domain.ListNotificationsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		ValidationError(c, err)
+		return
+	}
+
+	// Validate
+	if err := h.validate.Struct(req); err != nil {
+		ValidationError(c, err)
+		return
+	}
+
+	notifications, err := h.service.List(c.Request.Context(), userUUID, req)
+	if err != nil {
+		ServiceError(c, err)
+		return
+	}
+
+	notifications.Links = paginationLinks(c, h.publicURL, notifications.Page, notifications.Limit, notifications.TotalCount)
+	Success(c, http.StatusOK, notifications)
+}
+
+// UnreadCount returns how many unread notifications the current user has
+func (h *NotificationHandler) UnreadCount(c *gin.Context) {
+	// Get user UUID from context
+	userUUID, exists := GetUserUUID(c)
+	if !exists {
+		Error(c, http.StatusUnauthorized, ErrCodeUnauthorized,
+			"Unauthorized", "User not authenticated",
+			"Please login to view your notifications")
+		return
+	}
+
+	count, err := h.service.UnreadCount(c.Request.Context(), userUUID)
+	if err != nil {
+		ServiceError(c, err)
+		return
+	}
+
+	Success(c, http.StatusOK, count)
+}
+
+// MarkRead marks one of the current user's notifications as read
+func (h *NotificationHandler) MarkRead(c *gin.Context) {
+	// Get user UUID from context
+	userUUID, exists := GetUserUUID(c)
+	if !exists {
+		Error(c, http.StatusUnauthorized, ErrCodeUnauthorized,
+			"Unauthorized", "User not authenticated",
+			"Please login to update your notifications")
+		return
+	}
+
+	// Parse notification UUID
+	notificationUUID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		Error(c, http.StatusBadRequest, ErrCodeValidationFailed,
+			"Invalid notification ID", "Notification ID must be a valid UUID",
+			"Provide a valid notification UUID")
+		return
+	}
+
+	notification, err := h.service.MarkRead(c.Request.Context(), userUUID, notificationUUID)
+	if err != nil {
+		ServiceError(c, err)
+		return
+	}
+
+	Success(c, http.StatusOK, notification)
+}
+
+// MarkAllRead marks all of the current user's notifications as read
+func (h *NotificationHandler) MarkAllRead(c *gin.Context) {
+	// Get user UUID from context
+	userUUID, exists := GetUserUUID(c)
+	if !exists {
+		Error(c, http.StatusUnauthorized, ErrCodeUnauthorized,
+			"Unauthorized", "User not authenticated",
+			"Please login to update your notifications")
+		return
+	}
+
+	result, err := h.service.MarkAllRead(c.Request.Context(), userUUID)
+	if err != nil {
+		ServiceError(c, err)
+		return
+	}
+
+	Success(c, http.StatusOK, result)
+}
+
+// Stream sends the current user's new notifications as Server-Sent Events,
+// with the notification UUID as the event ID. A client reconnecting with a
+// Last-Event-ID header is first sent the notifications it missed.
+func (h *NotificationHandler) Stream(c *gin.Context) {
+	// Get user UUID from context
+	userUUID, exists := GetUserUUID(c)
+	if !exists {
+		Error(c, http.StatusUnauthorized, ErrCodeUnauthorized,
+			"Unauthorized", "User not authenticated",
+			"Please login to receive your notifications")
+		return
+	}
+
+	// Subscribe before catching up so nothing produced in between is lost
+	notifications, unsubscribe, err := h.service.Subscribe(c.Request.Context(), userUUID)
+	if err != nil {
+		ServiceError(c, err)
+		return
+	}
+	defer unsubscribe()
+
+	var missed []domain.Notification
+	if lastUUID, err := uuid.Parse(c.GetHeader(lastEventIDHeader)); err == nil {
+		missed, err = h.service.Missed(c.Request.Context(), userUUID, lastUUID)
+		if err != nil {
+			ServiceError(c, err)
+			return
+		}
+	}
+
+	openEventStream(c)
+
+	// Tell clients how long to wait before reconnecting
+	if _, err := fmt.Fprintf(c.Writer, "retry: %d\n\n", notificationsRetryInterval.Milliseconds()); err != nil {
+		return
+	}
+
+	lastSentID := 0
+	for _, notification := range missed {
+		h.sendNotification(c, notification)
+		lastSentID = notification.ID
+	}
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(eventsHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case notification, ok := <-notifications:
+			if !ok {
+				return
+			}
+			// Already sent while catching up
+			if notification.ID <= lastSentID {
+				continue
+			}
+			h.sendNotification(c, notification)
+			c.Writer.Flush()
+		case <-heartbeat.C:
+			if _, err := io.WriteString(c.Writer, ": ping\n\n"); err != nil {
+				return
+			}
+			c.Writer.Flush()
+		}
+	}
+}
+
+func (h *NotificationHandler) sendNotification(c *gin.Context, notification domain.Notification) {
+	c.Render(-1, sse.Event{
+		Id:    notification.UUID.String(),
+		Event: eventNotification,
+		Data:  jsonBody(c, notification),
+	})
+}
@@ -0,0 +1,44 @@
+package handler
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/saimonsiddique/blog-api/internal/domain"
+)
+
+// paginationLinks builds page URLs for the current request, keeping its path
+// and query filters and replacing page and limit. publicBaseURL is the scheme
+// and host clients use; when empty it is derived from the request (see
+// requestBaseURL), and links are relative if that fails.
+func paginationLinks(c *gin.Context, publicBaseURL string, page, limit, totalCount int) domain.PaginationLinks {
+	baseURL := publicBaseURL
+	if baseURL == "" {
+		baseURL = requestBaseURL(c)
+	}
+
+	lastPage := 1
+	if limit > 0 && totalCount > 0 {
+		lastPage = (totalCount + limit - 1) / limit
+	}
+
+	pageURL := func(p int) string {
+		query := c.Request.URL.Query()
+		query.Set("page", strconv.Itoa(p))
+		query.Set("limit", strconv.Itoa(limit))
+		return baseURL + c.Request.URL.Path + "?" + query.Encode()
+	}
+
+	links := domain.PaginationLinks{
+		First: pageURL(1),
+		Last:  pageURL(lastPage),
+	}
+	if page > 1 {
+		links.Prev = pageURL(min(page-1, lastPage))
+	}
+	if page < lastPage {
+		links.Next = pageURL(page + 1)
+	}
+
+	return links
+}
@@ -1,24 +1,30 @@
 package handler
 
 import (
+	"context"
+	"errors"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
 	"github.com/google/uuid"
 	"github.com/saimonsiddique/blog-api/internal/domain"
 	"github.com/saimonsiddique/blog-api/internal/service"
+	"github.com/sirupsen/logrus"
 )
 
 type PostHandler struct {
-	service  *service.PostService
-	validate *validator.Validate
+	service   *service.PostService
+	validate  *validator.Validate
+	publicURL string
 }
 
-func NewPostHandler(service *service.PostService) *PostHandler {
+func NewPostHandler(service *service.PostService, publicURL string) *PostHandler {
 	return &PostHandler{
-		service:  service,
-		validate: validator.New(),
+		service:   service,
+		validate:  validator.New(),
+		publicURL: publicURL,
 	}
 }
 
@@ -36,7 +42,7 @@ func (h *PostHandler) CreatePost(c *gin.Context) {
 	// Parse request
 	var req domain.CreatePostRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		ValidationError(c, err)
+		BindError(c, err)
 		return
 	}
 
@@ -58,32 +64,70 @@ func (h *PostHandler) CreatePost(c *gin.Context) {
 
 // GetPost retrieves a post by UUID or slug
 func (h *PostHandler) GetPost(c *gin.Context) {
-	id := c.Param("id")
+	ctx := c.Request.Context()
+	viewerUUID := GetViewerUUID(c)
 
-	// Try to parse as UUID first
-	postUUID, err := uuid.Parse(id)
+	post, err := findPost(c.Param("id"),
+		func(postUUID uuid.UUID) (*domain.PostResponse, error) {
+			return h.service.GetByUUID(ctx, postUUID, viewerUUID)
+		},
+		func(slug string) (*domain.PostResponse, error) {
+			return h.service.GetBySlug(ctx, slug, viewerUUID)
+		},
+	)
 	if err != nil {
-		// If not a valid UUID, treat as slug
-		post, err := h.service.GetBySlug(c.Request.Context(), id)
-		if err != nil {
-			ServiceError(c, err)
-			return
-		}
-
-		Success(c, http.StatusOK, post)
+		ServiceError(c, err)
 		return
 	}
 
-	// Get by UUID
-	post, err := h.service.GetByUUID(c.Request.Context(), postUUID)
-	if err != nil {
-		ServiceError(c, err)
-		return
+	h.respondWithPost(c, post)
+}
+
+// findPost looks a post up by id, which is either a UUID or a slug
+func findPost(
+	id string,
+	byUUID func(uuid.UUID) (*domain.PostResponse, error),
+	bySlug func(string) (*domain.PostResponse, error),
+) (*domain.PostResponse, error) {
+	// Only the canonical UUID form is treated as an ID; uuid.Parse also
+	// accepts forms like 32 bare hex digits, which are valid slugs
+	postUUID, ok := parseCanonicalUUID(id)
+	if !ok {
+		return bySlug(id)
+	}
+
+	// Get by UUID, falling back to the slug in case a slug looks like a UUID
+	post, err := byUUID(postUUID)
+	if errors.Is(err, domain.ErrPostNotFound) {
+		return bySlug(id)
+	}
+	return post, err
+}
+
+// respondWithPost sends a single post, with its table of contents when
+// requested with ?toc=true
+func (h *PostHandler) respondWithPost(c *gin.Context, post *domain.PostResponse) {
+	if c.Query("toc") == "true" {
+		h.service.AttachTOC(post)
 	}
 
 	Success(c, http.StatusOK, post)
 }
 
+// parseCanonicalUUID parses only the 36-character hyphenated UUID form
+func parseCanonicalUUID(s string) (uuid.UUID, bool) {
+	if len(s) != 36 {
+		return uuid.UUID{}, false
+	}
+
+	id, err := uuid.Parse(s)
+	if err != nil {
+		return uuid.UUID{}, false
+	}
+
+	return id, true
+}
+
 // ListPosts retrieves posts with filters and pagination
 func (h *PostHandler) ListPosts(c *gin.Context) {
 	// Parse query parameters
@@ -100,12 +144,13 @@ func (h *PostHandler) ListPosts(c *gin.Context) {
 	}
 
 	// List posts
-	posts, err := h.service.List(c.Request.Context(), req)
+	posts, err := h.service.List(c.Request.Context(), req, GetViewerUUID(c))
 	if err != nil {
 		ServiceError(c, err)
 		return
 	}
 
+	posts.Links = paginationLinks(c, h.publicURL, posts.Page, posts.Limit, posts.TotalCount)
 	Success(c, http.StatusOK, posts)
 }
 
@@ -133,7 +178,7 @@ func (h *PostHandler) UpdatePost(c *gin.Context) {
 	// Parse request
 	var req domain.UpdatePostRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		ValidationError(c, err)
+		BindError(c, err)
 		return
 	}
 
@@ -143,6 +188,10 @@ func (h *PostHandler) UpdatePost(c *gin.Context) {
 		return
 	}
 
+	if !h.checkUnmodifiedSince(c, userUUID, postUUID) {
+		return
+	}
+
 	// Update post
 	post, err := h.service.Update(c.Request.Context(), userUUID, postUUID, req)
 	if err != nil {
@@ -174,6 +223,10 @@ func (h *PostHandler) DeletePost(c *gin.Context) {
 		return
 	}
 
+	if !h.checkUnmodifiedSince(c, userUUID, postUUID) {
+		return
+	}
+
 	// Delete post
 	if err := h.service.Delete(c.Request.Context(), userUUID, postUUID); err != nil {
 		ServiceError(c, err)
@@ -182,3 +235,436 @@ func (h *PostHandler) DeletePost(c *gin.Context) {
 
 	Success(c, http.StatusOK, gin.H{"message": "Post deleted successfully"})
 }
+
+// ForcePublishPost publishes any post immediately (admin only)
+func (h *PostHandler) ForcePublishPost(c *gin.Context) {
+	h.forceStatus(c, "post_force_published", h.service.ForcePublish)
+}
+
+// ForceUnpublishPost moves any published post back to draft (admin only)
+func (h *PostHandler) ForceUnpublishPost(c *gin.Context) {
+	h.forceStatus(c, "post_force_unpublished", h.service.ForceUnpublish)
+}
+
+func (h *PostHandler) forceStatus(c *gin.Context, action string, change func(context.Context, uuid.UUID) (*domain.PostResponse, domain.PostStatus, error)) {
+	// Parse post UUID
+	id := c.Param("id")
+	postUUID, err := uuid.Parse(id)
+	if err != nil {
+		Error(c, http.StatusBadRequest, ErrCodeValidationFailed,
+			"Invalid post ID", "Post ID must be a valid UUID",
+			"Provide a valid post UUID")
+		return
+	}
+
+	post, previousStatus, err := change(c.Request.Context(), postUUID)
+	if err != nil {
+		ServiceError(c, err)
+		return
+	}
+
+	setAuditEntry(c, action, logrus.Fields{
+		"postUuid":       postUUID,
+		"previousStatus": previousStatus,
+		"newStatus":      post.Status,
+	})
+
+	Success(c, http.StatusOK, post)
+}
+
+// TransferPost makes another user the author of a post (admin only)
+func (h *PostHandler) TransferPost(c *gin.Context) {
+	// Parse post UUID
+	id := c.Param("id")
+	postUUID, err := uuid.Parse(id)
+	if err != nil {
+		Error(c, http.StatusBadRequest, ErrCodeValidationFailed,
+			"Invalid post ID", "Post ID must be a valid UUID",
+			"Provide a valid post UUID")
+		return
+	}
+
+	// Parse request
+	var req domain.TransferPostRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		BindError(c, err)
+		return
+	}
+
+	// Validate
+	if err := h.validate.Struct(req); err != nil {
+		ValidationError(c, err)
+		return
+	}
+
+	resp, err := h.service.TransferOwnership(c.Request.Context(), postUUID, req.NewAuthorID)
+	if err != nil {
+		ServiceError(c, err)
+		return
+	}
+
+	setAuditEntry(c, "post_transferred", logrus.Fields{
+		"postUuid":           postUUID,
+		"previousAuthorUuid": resp.PreviousAuthor.UUID,
+		"newAuthorUuid":      resp.Post.Author.UUID,
+	})
+
+	Success(c, http.StatusOK, resp)
+}
+
+// checkUnmodifiedSince enforces an If-Unmodified-Since precondition, so a
+// client can't overwrite or delete a post changed since it last read it.
+// It reports whether the request may proceed; a missing or unparseable
+// header is ignored. Only the post's author gets a precondition check.
+func (h *PostHandler) checkUnmodifiedSince(c *gin.Context, userUUID, postUUID uuid.UUID) bool {
+	return unmodifiedSince(c, func() (time.Time, error) {
+		return h.service.LastModified(c.Request.Context(), userUUID, postUUID)
+	})
+}
+
+// unmodifiedSince checks the If-Unmodified-Since header against the time
+// returned by lastModified, which is only called when the header is usable
+func unmodifiedSince(c *gin.Context, lastModified func() (time.Time, error)) bool {
+	header := c.GetHeader("If-Unmodified-Since")
+	if header == "" {
+		return true
+	}
+
+	since, err := http.ParseTime(header)
+	if err != nil {
+		return true
+	}
+
+	modified, err := lastModified()
+	if err != nil {
+		ServiceError(c, err)
+		return false
+	}
+
+	// HTTP dates have one-second resolution
+	if modified.Truncate(time.Second).After(since) {
+		c.Header("Last-Modified", modified.UTC().Format(http.TimeFormat))
+		Error(c, http.StatusPreconditionFailed, ErrCodePreconditionFailed,
+			"Precondition failed", "The post has been modified since "+header,
+			"Fetch the latest version of the post and try again")
+		return false
+	}
+
+	return true
+}
+
+// MarkRead marks a post as read by the current user
+func (h *PostHandler) MarkRead(c *gin.Context) {
+	// Get user UUID from context
+	userUUID, exists := GetUserUUID(c)
+	if !exists {
+		Error(c, http.StatusUnauthorized, ErrCodeUnauthorized,
+			"Unauthorized", "User not authenticated",
+			"Please login to mark this post as read")
+		return
+	}
+
+	// Parse post UUID
+	id := c.Param("id")
+	postUUID, err := uuid.Parse(id)
+	if err != nil {
+		Error(c, http.StatusBadRequest, ErrCodeValidationFailed,
+			"Invalid post ID", "Post ID must be a valid UUID",
+			"Provide a valid post UUID")
+		return
+	}
+
+	// Mark as read
+	resp, err := h.service.MarkRead(c.Request.Context(), userUUID, postUUID)
+	if err != nil {
+		ServiceError(c, err)
+		return
+	}
+
+	Success(c, http.StatusOK, resp)
+}
+
+// SaveReadProgress stores the current user's reading progress for a post
+func (h *PostHandler) SaveReadProgress(c *gin.Context) {
+	// Get user UUID from context
+	userUUID, exists := GetUserUUID(c)
+	if !exists {
+		Error(c, http.StatusUnauthorized, ErrCodeUnauthorized,
+			"Unauthorized", "User not authenticated",
+			"Please login to save your reading progress")
+		return
+	}
+
+	// Parse post UUID
+	id := c.Param("id")
+	postUUID, err := uuid.Parse(id)
+	if err != nil {
+		Error(c, http.StatusBadRequest, ErrCodeValidationFailed,
+			"Invalid post ID", "Post ID must be a valid UUID",
+			"Provide a valid post UUID")
+		return
+	}
+
+	// Parse request
+	var req domain.UpdateReadProgressRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		BindError(c, err)
+		return
+	}
+
+	// Validate
+	if err := h.validate.Struct(req); err != nil {
+		ValidationError(c, err)
+		return
+	}
+
+	// Save progress
+	resp, err := h.service.SaveReadProgress(c.Request.Context(), userUUID, postUUID, *req.Percent)
+	if err != nil {
+		ServiceError(c, err)
+		return
+	}
+
+	Success(c, http.StatusOK, resp)
+}
+
+// ReadHistory lists the posts the current user has recently read
+func (h *PostHandler) ReadHistory(c *gin.Context) {
+	// Get user UUID from context
+	userUUID, exists := GetUserUUID(c)
+	if !exists {
+		Error(c, http.StatusUnauthorized, ErrCodeUnauthorized,
+			"Unauthorized", "User not authenticated",
+			"Please login to view your reading history")
+		return
+	}
+
+	// Parse query parameters
+	var req domain.ReadHistoryRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		ValidationError(c, err)
+		return
+	}
+
+	// Validate
+	if err := h.validate.Struct(req); err != nil {
+		ValidationError(c, err)
+		return
+	}
+
+	// List history
+	history, err := h.service.ReadHistory(c.Request.Context(), userUUID, req)
+	if err != nil {
+		ServiceError(c, err)
+		return
+	}
+
+	history.Links = paginationLinks(c, h.publicURL, history.Page, history.Limit, history.TotalCount)
+	Success(c, http.StatusOK, history)
+}
+
+// BookmarkPost saves a post to the current user's bookmarks
+func (h *PostHandler) BookmarkPost(c *gin.Context) {
+	// Get user UUID from context
+	userUUID, exists := GetUserUUID(c)
+	if !exists {
+		Error(c, http.StatusUnauthorized, ErrCodeUnauthorized,
+			"Unauthorized", "User not authenticated",
+			"Please login to bookmark this post")
+		return
+	}
+
+	// Parse post UUID
+	id := c.Param("id")
+	postUUID, err := uuid.Parse(id)
+	if err != nil {
+		Error(c, http.StatusBadRequest, ErrCodeValidationFailed,
+			"Invalid post ID", "Post ID must be a valid UUID",
+			"Provide a valid post UUID")
+		return
+	}
+
+	// Bookmark post
+	if err := h.service.Bookmark(c.Request.Context(), userUUID, postUUID); err != nil {
+		ServiceError(c, err)
+		return
+	}
+
+	Success(c, http.StatusOK, gin.H{"message": "Post bookmarked successfully"})
+}
+
+// RemoveBookmark removes a post from the current user's bookmarks
+func (h *PostHandler) RemoveBookmark(c *gin.Context) {
+	// Get user UUID from context
+	userUUID, exists := GetUserUUID(c)
+	if !exists {
+		Error(c, http.StatusUnauthorized, ErrCodeUnauthorized,
+			"Unauthorized", "User not authenticated",
+			"Please login to remove this bookmark")
+		return
+	}
+
+	// Parse post UUID
+	id := c.Param("id")
+	postUUID, err := uuid.Parse(id)
+	if err != nil {
+		Error(c, http.StatusBadRequest, ErrCodeValidationFailed,
+			"Invalid post ID", "Post ID must be a valid UUID",
+			"Provide a valid post UUID")
+		return
+	}
+
+	// Remove bookmark
+	if err := h.service.RemoveBookmark(c.Request.Context(), userUUID, postUUID); err != nil {
+		ServiceError(c, err)
+		return
+	}
+
+	Success(c, http.StatusOK, gin.H{"message": "Bookmark removed successfully"})
+}
+
+// PostInteractions returns the current user's read and bookmark flags for a
+// list of posts
+func (h *PostHandler) PostInteractions(c *gin.Context) {
+	// Get user UUID from context
+	userUUID, exists := GetUserUUID(c)
+	if !exists {
+		Error(c, http.StatusUnauthorized, ErrCodeUnauthorized,
+			"Unauthorized", "User not authenticated",
+			"Please login to view your interactions")
+		return
+	}
+
+	// Parse request
+	var req domain.PostInteractionsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		BindError(c, err)
+		return
+	}
+
+	// Validate
+	if err := h.validate.Struct(req); err != nil {
+		ValidationError(c, err)
+		return
+	}
+
+	interactions, err := h.service.Interactions(c.Request.Context(), userUUID, req)
+	if err != nil {
+		ServiceError(c, err)
+		return
+	}
+
+	Success(c, http.StatusOK, interactions)
+}
+
+// ListBookmarks lists the current user's bookmarked posts
+func (h *PostHandler) ListBookmarks(c *gin.Context) {
+	// Get user UUID from context
+	userUUID, exists := GetUserUUID(c)
+	if !exists {
+		Error(c, http.StatusUnauthorized, ErrCodeUnauthorized,
+			"Unauthorized", "User not authenticated",
+			"Please login to view your bookmarks")
+		return
+	}
+
+	// Parse query parameters
+	var req domain.ListBookmarksRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		ValidationError(c, err)
+		return
+	}
+
+	// Validate
+	if err := h.validate.Struct(req); err != nil {
+		ValidationError(c, err)
+		return
+	}
+
+	// List bookmarks
+	bookmarks, err := h.service.ListBookmarks(c.Request.Context(), userUUID, req)
+	if err != nil {
+		ServiceError(c, err)
+		return
+	}
+
+	bookmarks.Links = paginationLinks(c, h.publicURL, bookmarks.Page, bookmarks.Limit, bookmarks.TotalCount)
+	Success(c, http.StatusOK, bookmarks)
+}
+
+// ListEditablePosts lists the posts the current user can edit. Unlike the
+// author filter on the public listing, it includes drafts and archived posts,
+// and for admins it covers every post.
+func (h *PostHandler) ListEditablePosts(c *gin.Context) {
+	// Get user UUID from context
+	userUUID, exists := GetUserUUID(c)
+	if !exists {
+		Error(c, http.StatusUnauthorized, ErrCodeUnauthorized,
+			"Unauthorized", "User not authenticated",
+			"Please login to view your editable posts")
+		return
+	}
+
+	// Parse query parameters
+	var req domain.ListEditablePostsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		ValidationError(c, err)
+		return
+	}
+
+	// Validate
+	if err := h.validate.Struct(req); err != nil {
+		ValidationError(c, err)
+		return
+	}
+
+	// List editable posts
+	posts, err := h.service.ListEditable(c.Request.Context(), userUUID, req)
+	if err != nil {
+		ServiceError(c, err)
+		return
+	}
+
+	posts.Links = paginationLinks(c, h.publicURL, posts.Page, posts.Limit, posts.TotalCount)
+	Success(c, http.StatusOK, posts)
+}
+
+// ListMyPostsGrouped returns the current user's posts grouped by status
+func (h *PostHandler) ListMyPostsGrouped(c *gin.Context) {
+	// Get user UUID from context
+	userUUID, exists := GetUserUUID(c)
+	if !exists {
+		Error(c, http.StatusUnauthorized, ErrCodeUnauthorized,
+			"Unauthorized", "User not authenticated",
+			"Please login to view your posts")
+		return
+	}
+
+	grouped, err := h.service.ListGrouped(c.Request.Context(), userUUID)
+	if err != nil {
+		ServiceError(c, err)
+		return
+	}
+
+	Success(c, http.StatusOK, grouped)
+}
+
+// GetMyStats returns dashboard statistics for the current user's posts
+func (h *PostHandler) GetMyStats(c *gin.Context) {
+	// Get user UUID from context
+	userUUID, exists := GetUserUUID(c)
+	if !exists {
+		Error(c, http.StatusUnauthorized, ErrCodeUnauthorized,
+			"Unauthorized", "User not authenticated",
+			"Please login to view your stats")
+		return
+	}
+
+	stats, err := h.service.AuthorStats(c.Request.Context(), userUUID)
+	if err != nil {
+		ServiceError(c, err)
+		return
+	}
+
+	Success(c, http.StatusOK, stats)
+}
@@ -0,0 +1,176 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/saimonsiddique/blog-api/internal/domain"
+)
+
+func TestParseCanonicalUUID(t *testing.T) {
+	canonical := "6ba7b810-9dad-11d1-80b4-00c04fd430c8"
+
+	tests := []struct {
+		name   string
+		input  string
+		wantOK bool
+	}{
+		{name: "canonical", input: canonical, wantOK: true},
+		{name: "canonical upper case", input: "6BA7B810-9DAD-11D1-80B4-00C04FD430C8", wantOK: true},
+		{name: "bare hex", input: "6ba7b8109dad11d180b400c04fd430c8"},
+		{name: "braced", input: "{" + canonical + "}"},
+		{name: "urn", input: "urn:uuid:" + canonical},
+		{name: "36 chars but not hex", input: "how-to-write-a-blog-post-in-go-today"},
+		{name: "36 chars with misplaced dashes", input: "6ba7b8109-dad-11d1-80b4-00c04fd430c8"},
+		{name: "slug", input: "hello-world"},
+		{name: "empty", input: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := parseCanonicalUUID(tt.input)
+			if ok != tt.wantOK {
+				t.Fatalf("parseCanonicalUUID(%q) ok = %v, want %v", tt.input, ok, tt.wantOK)
+			}
+			if ok && got != uuid.MustParse(canonical) {
+				t.Errorf("parseCanonicalUUID(%q) = %s, want %s", tt.input, got, canonical)
+			}
+		})
+	}
+}
+
+func TestFindPost(t *testing.T) {
+	postUUID := uuid.MustParse("6ba7b810-9dad-11d1-80b4-00c04fd430c8")
+	byID := &domain.PostResponse{UUID: postUUID}
+	bySlug := &domain.PostResponse{Slug: "found-by-slug"}
+	errDB := errors.New("connection refused")
+
+	tests := []struct {
+		name     string
+		id       string
+		uuidErr  error
+		want     *domain.PostResponse
+		wantErr  error
+		wantUUID bool
+		wantSlug bool
+	}{
+		{name: "canonical uuid", id: postUUID.String(), want: byID, wantUUID: true},
+		{name: "slug", id: "hello-world", want: bySlug, wantSlug: true},
+		{name: "slug of 32 hex digits", id: "6ba7b8109dad11d180b400c04fd430c8", want: bySlug, wantSlug: true},
+		{name: "braced uuid is a slug", id: "{" + postUUID.String() + "}", want: bySlug, wantSlug: true},
+		{name: "urn uuid is a slug", id: "urn:uuid:" + postUUID.String(), want: bySlug, wantSlug: true},
+		{
+			name: "slug that looks like a uuid", id: postUUID.String(), uuidErr: domain.ErrPostNotFound,
+			want: bySlug, wantUUID: true, wantSlug: true,
+		},
+		{name: "uuid lookup failure", id: postUUID.String(), uuidErr: errDB, wantErr: errDB, wantUUID: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calledUUID, calledSlug bool
+
+			got, err := findPost(tt.id,
+				func(id uuid.UUID) (*domain.PostResponse, error) {
+					calledUUID = true
+					if id != postUUID {
+						t.Errorf("looked up UUID %s, want %s", id, postUUID)
+					}
+					if tt.uuidErr != nil {
+						return nil, tt.uuidErr
+					}
+					return byID, nil
+				},
+				func(slug string) (*domain.PostResponse, error) {
+					calledSlug = true
+					if slug != tt.id {
+						t.Errorf("looked up slug %q, want %q", slug, tt.id)
+					}
+					return bySlug, nil
+				},
+			)
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("findPost() error = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("findPost() = %+v, want %+v", got, tt.want)
+			}
+			if calledUUID != tt.wantUUID || calledSlug != tt.wantSlug {
+				t.Errorf("lookups (uuid, slug) = (%v, %v), want (%v, %v)", calledUUID, calledSlug, tt.wantUUID, tt.wantSlug)
+			}
+		})
+	}
+}
+
+func TestUnmodifiedSince(t *testing.T) {
+	modified := time.Date(2026, 3, 1, 12, 0, 0, 500_000_000, time.UTC)
+
+	tests := []struct {
+		name             string
+		header           string
+		loadErr          error
+		wantStatus       int
+		wantCode         string
+		wantLoaded       bool
+		wantLastModified string
+	}{
+		{name: "no header", wantStatus: http.StatusOK},
+		{name: "unparseable header", header: "yesterday", wantStatus: http.StatusOK},
+		{
+			name: "fresh", header: modified.Add(time.Hour).Format(http.TimeFormat),
+			wantStatus: http.StatusOK, wantLoaded: true,
+		},
+		{
+			name: "same second", header: modified.Format(http.TimeFormat),
+			wantStatus: http.StatusOK, wantLoaded: true,
+		},
+		{
+			name: "stale", header: modified.Add(-time.Second).Format(http.TimeFormat),
+			wantStatus: http.StatusPreconditionFailed, wantCode: ErrCodePreconditionFailed, wantLoaded: true,
+			wantLastModified: "Sun, 01 Mar 2026 12:00:00 GMT",
+		},
+		{
+			name: "post not found", header: modified.Format(http.TimeFormat), loadErr: domain.ErrPostNotFound,
+			wantStatus: http.StatusNotFound, wantCode: ErrCodePostNotFound, wantLoaded: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPut, "/", nil)
+			if tt.header != "" {
+				req.Header.Set("If-Unmodified-Since", tt.header)
+			}
+
+			var loaded bool
+			rec, resp := serve(t, req, func(c *gin.Context) {
+				ok := unmodifiedSince(c, func() (time.Time, error) {
+					loaded = true
+					return modified, tt.loadErr
+				})
+				if ok {
+					Success(c, http.StatusOK, nil)
+				}
+			})
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if tt.wantCode != "" && (resp.Error == nil || resp.Error.Code != tt.wantCode) {
+				t.Errorf("error = %+v, want code %s", resp.Error, tt.wantCode)
+			}
+			if loaded != tt.wantLoaded {
+				t.Errorf("loaded last modified = %v, want %v", loaded, tt.wantLoaded)
+			}
+			if got := rec.Header().Get("Last-Modified"); got != tt.wantLastModified {
+				t.Errorf("Last-Modified = %q, want %q", got, tt.wantLastModified)
+			}
+		})
+	}
+}
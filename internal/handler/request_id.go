@@ -0,0 +1,44 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/saimonsiddique/blog-api/internal/pkg/requestid"
+)
+
+// requestIDHeaderKey holds the header request IDs are carried in
+const requestIDHeaderKey = "requestIdHeader"
+
+// requestIDHeader returns the header request IDs are carried in, as
+// configured on RequestIDMiddleware
+func requestIDHeader(c *gin.Context) string {
+	if header := c.GetString(requestIDHeaderKey); header != "" {
+		return header
+	}
+	return requestid.DefaultHeader
+}
+
+// RequestIDMiddleware assigns each request an ID, taken from header when the
+// client sends one. With fromTraceparent set, a request without it uses the
+// trace ID of its W3C traceparent header instead. Otherwise a new UUID is
+// generated. The ID is echoed in header on the response and carried on the
+// request context so work started by the request, such as queued events, can
+// be correlated with it.
+func RequestIDMiddleware(header string, fromTraceparent bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(requestIDHeaderKey, header)
+
+		id := c.GetHeader(header)
+		if id == "" && fromTraceparent {
+			id, _ = requestid.FromTraceparent(c.GetHeader(requestid.TraceparentHeader))
+		}
+		if id == "" {
+			id = uuid.New().String()
+		}
+
+		c.Header(header, id)
+		c.Request = c.Request.WithContext(requestid.WithID(c.Request.Context(), id))
+
+		c.Next()
+	}
+}
@@ -0,0 +1,66 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/saimonsiddique/blog-api/internal/pkg/requestid"
+)
+
+func TestTrackingIDUsesConfiguredHeader(t *testing.T) {
+	const header = "X-Correlation-ID"
+
+	failing := func(c *gin.Context) {
+		Error(c, http.StatusBadRequest, ErrCodeValidationFailed, "Bad request", "detail", "fix it")
+	}
+	// A handler that swaps in a request without the request ID on its context
+	lostContext := func(c *gin.Context) {
+		c.Request = c.Request.WithContext(context.Background())
+		failing(c)
+	}
+
+	tests := []struct {
+		name       string
+		handlers   []gin.HandlerFunc
+		sentHeader string
+		wantHeader string
+	}{
+		{
+			name:       "from the request context",
+			handlers:   []gin.HandlerFunc{RequestIDMiddleware(header, false), failing},
+			sentHeader: header,
+			wantHeader: header,
+		},
+		{
+			name:       "fallback reads the configured header",
+			handlers:   []gin.HandlerFunc{RequestIDMiddleware(header, false), lostContext},
+			sentHeader: header,
+			wantHeader: header,
+		},
+		{
+			name:       "default header without the middleware",
+			handlers:   []gin.HandlerFunc{failing},
+			sentHeader: requestid.DefaultHeader,
+			wantHeader: requestid.DefaultHeader,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set(tt.sentHeader, "client-id")
+
+			rec, resp := serve(t, req, tt.handlers...)
+
+			if resp.TrackingID != "client-id" {
+				t.Errorf("trackingId = %q, want the ID sent in %s", resp.TrackingID, tt.sentHeader)
+			}
+			if got := rec.Header().Get(tt.wantHeader); got != "client-id" {
+				t.Errorf("%s response header = %q, want %q", tt.wantHeader, got, "client-id")
+			}
+		})
+	}
+}
@@ -1,27 +1,53 @@
 package handler
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
 	"github.com/google/uuid"
+	"github.com/saimonsiddique/blog-api/internal/database"
 	"github.com/saimonsiddique/blog-api/internal/domain"
+	"github.com/saimonsiddique/blog-api/internal/pkg/requestid"
 )
 
 const docsURL = "https://api-docs.example.com"
 
+// serviceUnavailableRetryAfter is the Retry-After, in seconds, sent when the
+// database is unreachable
+const serviceUnavailableRetryAfter = 5
+
 func getTrackingID(c *gin.Context) string {
-	trackingID := c.GetHeader("X-Request-ID")
+	if trackingID := requestid.FromContext(c.Request.Context()); trackingID != "" {
+		return trackingID
+	}
+
+	header := requestIDHeader(c)
+	trackingID := c.GetHeader(header)
 	if trackingID == "" {
 		trackingID = uuid.New().String()
 	}
-	c.Header("X-Request-ID", trackingID)
+	c.Header(header, trackingID)
 	return trackingID
 }
 
+// render writes the response as XML when the client asks for it via the
+// Accept header, and as JSON otherwise
+func render(c *gin.Context, statusCode int, response domain.APIResponse) {
+	switch c.NegotiateFormat(binding.MIMEJSON, binding.MIMEXML, binding.MIMEXML2) {
+	case binding.MIMEXML, binding.MIMEXML2:
+		c.XML(statusCode, response)
+	default:
+		c.JSON(statusCode, jsonBody(c, response))
+	}
+}
+
 func Success(c *gin.Context, statusCode int, data interface{}) {
 	trackingID := getTrackingID(c)
 
@@ -33,7 +59,24 @@ func Success(c *gin.Context, statusCode int, data interface{}) {
 		DocumentationURL: docsURL,
 	}
 
-	c.JSON(statusCode, response)
+	render(c, statusCode, response)
+}
+
+// Raw writes body as is, without the APIResponse envelope, for responses that
+// aren't JSON API resources and would be corrupted by wrapping. Endpoints that
+// opt out of the envelope:
+//
+//   - GET /ping: plain text liveness probe, written with Raw
+//   - GET /metrics: Prometheus exposition format, written by the Prometheus handler
+//   - GET /api/v1/events: Server-Sent Events stream
+//   - GET /api/v1/me/notifications/stream: Server-Sent Events stream of the
+//     current user's notifications
+//
+// Errors raised before a raw body is written, such as invalid query
+// parameters or a missing login, still use Error.
+func Raw(c *gin.Context, statusCode int, contentType string, body []byte) {
+	getTrackingID(c)
+	c.Data(statusCode, contentType, body)
 }
 
 func Error(c *gin.Context, statusCode int, code, message, details, suggestion string) {
@@ -54,7 +97,7 @@ func Error(c *gin.Context, statusCode int, code, message, details, suggestion st
 		},
 	}
 
-	c.JSON(statusCode, response)
+	render(c, statusCode, response)
 }
 
 func ServiceError(c *gin.Context, err error) {
@@ -67,6 +110,14 @@ func ServiceError(c *gin.Context, err error) {
 		Error(c, http.StatusNotFound, ErrCodeUserNotFound,
 			"User not found", err.Error(),
 			"Verify the user ID or email")
+	case errors.Is(err, domain.ErrUserInactive):
+		Error(c, http.StatusBadRequest, ErrCodeUserInactive,
+			"User is not active", err.Error(),
+			"Choose an active user")
+	case errors.Is(err, domain.ErrSessionNotFound):
+		Error(c, http.StatusNotFound, ErrCodeSessionNotFound,
+			"Session not found", err.Error(),
+			"The session may have expired or been revoked")
 	case errors.Is(err, domain.ErrEmailTaken):
 		Error(c, http.StatusConflict, ErrCodeEmailTaken,
 			"Email already taken", err.Error(),
@@ -75,6 +126,10 @@ func ServiceError(c *gin.Context, err error) {
 		Error(c, http.StatusConflict, ErrCodeUsernameTaken,
 			"Username already taken", err.Error(),
 			"Use a different username")
+	case errors.Is(err, domain.ErrUsernameReserved):
+		Error(c, http.StatusBadRequest, ErrCodeUsernameReserved,
+			"Username is reserved", err.Error(),
+			"Choose a username that isn't reserved")
 	case errors.Is(err, domain.ErrPostNotFound):
 		Error(c, http.StatusNotFound, ErrCodePostNotFound,
 			"Post not found", err.Error(),
@@ -83,6 +138,10 @@ func ServiceError(c *gin.Context, err error) {
 		Error(c, http.StatusConflict, ErrCodeSlugTaken,
 			"Slug already taken", err.Error(),
 			"Use a different title or slug")
+	case errors.Is(err, domain.ErrDuplicatePost):
+		Error(c, http.StatusConflict, ErrCodeDuplicatePost,
+			"Duplicate post", err.Error(),
+			"You recently created a post with the same title and content")
 	case errors.Is(err, domain.ErrPostAlreadyPublished):
 		Error(c, http.StatusConflict, ErrCodePostAlreadyPublished,
 			"Post already published", err.Error(),
@@ -91,6 +150,58 @@ func ServiceError(c *gin.Context, err error) {
 		Error(c, http.StatusBadRequest, ErrCodeInvalidStatusChange,
 			"Invalid status change", err.Error(),
 			"Check the current post status and allowed transitions")
+	case errors.Is(err, domain.ErrPostNotReady):
+		Error(c, http.StatusBadRequest, ErrCodePostNotReady,
+			"Post not ready to publish", err.Error(),
+			"Complete the missing fields, or save the post as a draft")
+	case errors.Is(err, domain.ErrContentTooLarge):
+		Error(c, http.StatusRequestEntityTooLarge, ErrCodePayloadTooLarge,
+			"Content too large", err.Error(),
+			"Shorten the content and try again")
+	case errors.Is(err, domain.ErrInvalidPostContent):
+		Error(c, http.StatusBadRequest, ErrCodeValidationFailed,
+			"Validation failed", err.Error(),
+			"Check the request payload")
+	case errors.Is(err, domain.ErrSeriesNotFound):
+		Error(c, http.StatusNotFound, ErrCodeSeriesNotFound,
+			"Series not found", err.Error(),
+			"Verify the series slug")
+	case errors.Is(err, domain.ErrPostInSeries):
+		Error(c, http.StatusConflict, ErrCodePostInSeries,
+			"Post already in a series", err.Error(),
+			"Remove the post from its current series first")
+	case errors.Is(err, domain.ErrInvalidSeriesOrder):
+		Error(c, http.StatusBadRequest, ErrCodeInvalidSeriesOrder,
+			"Invalid series order", err.Error(),
+			"List every post in the series exactly once")
+	case errors.Is(err, domain.ErrNotificationNotFound):
+		Error(c, http.StatusNotFound, ErrCodeNotificationNotFound,
+			"Notification not found", err.Error(),
+			"Verify the notification ID")
+	case errors.Is(err, domain.ErrFeatureFlagNotFound):
+		Error(c, http.StatusNotFound, ErrCodeFeatureFlagNotFound,
+			"Feature flag not found", err.Error(),
+			"List the feature flags to see the known keys")
+	case errors.Is(err, domain.ErrRegistrationClosed):
+		Error(c, http.StatusForbidden, ErrCodeRegistrationClosed,
+			"Registration closed", err.Error(),
+			"Registration is invite-only at the moment; register with an invite code")
+	case errors.Is(err, domain.ErrInvalidInvite):
+		Error(c, http.StatusForbidden, ErrCodeInvalidInvite,
+			"Invalid invite", err.Error(),
+			"Check the invite code, and register with the email it was sent to")
+	case errors.Is(err, domain.ErrInviteUsed):
+		Error(c, http.StatusForbidden, ErrCodeInviteUsed,
+			"Invite already used", err.Error(),
+			"Ask for a new invite code")
+	case errors.Is(err, domain.ErrInviteExpired):
+		Error(c, http.StatusForbidden, ErrCodeInviteExpired,
+			"Invite expired", err.Error(),
+			"Ask for a new invite code")
+	case errors.Is(err, domain.ErrCaptchaFailed):
+		Error(c, http.StatusBadRequest, ErrCodeCaptchaFailed,
+			"CAPTCHA verification failed", err.Error(),
+			"Solve the CAPTCHA again and send its token as captchaToken")
 	case errors.Is(err, domain.ErrForbidden):
 		Error(c, http.StatusForbidden, ErrCodeForbidden,
 			"Forbidden", err.Error(),
@@ -103,6 +214,11 @@ func ServiceError(c *gin.Context, err error) {
 		Error(c, http.StatusConflict, ErrCodeConflict,
 			"Conflict", err.Error(),
 			"Resolve the conflict and try again")
+	case database.IsConnectionError(err):
+		c.Header("Retry-After", strconv.Itoa(serviceUnavailableRetryAfter))
+		Error(c, http.StatusServiceUnavailable, ErrCodeServiceUnavailable,
+			"Service unavailable", "The database is temporarily unreachable",
+			"Retry the request shortly")
 	default:
 		Error(c, http.StatusInternalServerError, ErrCodeInternalServer,
 			"Internal server error", "An unexpected error occurred",
@@ -110,6 +226,21 @@ func ServiceError(c *gin.Context, err error) {
 	}
 }
 
+// Recovered responds to a request whose handler panicked, for use with
+// gin.CustomRecovery. Panics carrying an error are mapped like ServiceError,
+// so one raised by a straggler query against a database pool closed during
+// shutdown still gets a 503 rather than a bare 500.
+func Recovered(c *gin.Context, recovered any) {
+	if err, ok := recovered.(error); ok {
+		ServiceError(c, err)
+	} else {
+		Error(c, http.StatusInternalServerError, ErrCodeInternalServer,
+			"Internal server error", "An unexpected error occurred",
+			"Please try again later or contact support")
+	}
+	c.Abort()
+}
+
 func ValidationError(c *gin.Context, err error) {
 	trackingID := getTrackingID(c)
 
@@ -128,5 +259,42 @@ func ValidationError(c *gin.Context, err error) {
 		},
 	}
 
-	c.JSON(http.StatusBadRequest, response)
+	render(c, http.StatusBadRequest, response)
+}
+
+// BindError reports a failure to bind a JSON request body, distinguishing an
+// empty body, malformed JSON and fields of the wrong type
+func BindError(c *gin.Context, err error) {
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	var maxBytesErr *http.MaxBytesError
+
+	switch {
+	case errors.As(err, &maxBytesErr):
+		Error(c, http.StatusRequestEntityTooLarge, ErrCodePayloadTooLarge,
+			"Request body too large", fmt.Sprintf("The request body must be at most %d bytes", maxBytesErr.Limit),
+			"Shorten the content and try again")
+	case errors.Is(err, io.EOF):
+		Error(c, http.StatusBadRequest, ErrCodeInvalidRequestBody,
+			"Empty request body", "The request body must not be empty",
+			"Send a JSON object in the request body")
+	case errors.As(err, &syntaxErr):
+		Error(c, http.StatusBadRequest, ErrCodeInvalidRequestBody,
+			"Malformed JSON", fmt.Sprintf("Invalid JSON syntax at byte offset %d", syntaxErr.Offset),
+			"Check the request body is valid JSON")
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		Error(c, http.StatusBadRequest, ErrCodeInvalidRequestBody,
+			"Malformed JSON", "The request body ended unexpectedly",
+			"Check the request body is complete, valid JSON")
+	case errors.As(err, &typeErr):
+		field := typeErr.Field
+		if field == "" {
+			field = "request body"
+		}
+		Error(c, http.StatusBadRequest, ErrCodeInvalidRequestBody,
+			"Invalid field type", fmt.Sprintf("Field '%s' must be of type %s, got %s", field, typeErr.Type, typeErr.Value),
+			"Check the types of the fields in the request body")
+	default:
+		ValidationError(c, err)
+	}
 }
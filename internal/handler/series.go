@@ -0,0 +1,169 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/saimonsiddique/blog-api/internal/domain"
+	"github.com/saimonsiddique/blog-api/internal/service"
+)
+
+type SeriesHandler struct {
+	service  *service.SeriesService
+	validate *validator.Validate
+}
+
+func NewSeriesHandler(service *service.SeriesService) *SeriesHandler {
+	return &SeriesHandler{
+		service:  service,
+		validate: validator.New(),
+	}
+}
+
+// CreateSeries creates a new series
+func (h *SeriesHandler) CreateSeries(c *gin.Context) {
+	// Get user UUID from context
+	userUUID, exists := GetUserUUID(c)
+	if !exists {
+		Error(c, http.StatusUnauthorized, ErrCodeUnauthorized,
+			"Unauthorized", "User not authenticated",
+			"Please login to create a series")
+		return
+	}
+
+	// Parse request
+	var req domain.CreateSeriesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		BindError(c, err)
+		return
+	}
+
+	// Validate
+	if err := h.validate.Struct(req); err != nil {
+		ValidationError(c, err)
+		return
+	}
+
+	// Create series
+	series, err := h.service.Create(c.Request.Context(), userUUID, req)
+	if err != nil {
+		ServiceError(c, err)
+		return
+	}
+
+	Success(c, http.StatusCreated, series)
+}
+
+// GetSeries retrieves a series and its published posts by slug
+func (h *SeriesHandler) GetSeries(c *gin.Context) {
+	series, err := h.service.GetBySlug(c.Request.Context(), c.Param("slug"))
+	if err != nil {
+		ServiceError(c, err)
+		return
+	}
+
+	Success(c, http.StatusOK, series)
+}
+
+// AddPost appends a post to a series
+func (h *SeriesHandler) AddPost(c *gin.Context) {
+	// Get user from context
+	userUUID, exists := GetUserUUID(c)
+	if !exists {
+		Error(c, http.StatusUnauthorized, ErrCodeUnauthorized,
+			"Unauthorized", "User not authenticated",
+			"Please login to modify this series")
+		return
+	}
+	role, _ := GetUserRole(c)
+
+	// Parse request
+	var req domain.AddSeriesPostRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		BindError(c, err)
+		return
+	}
+
+	// Validate
+	if err := h.validate.Struct(req); err != nil {
+		ValidationError(c, err)
+		return
+	}
+
+	// Add post
+	series, err := h.service.AddPost(c.Request.Context(), userUUID, role, c.Param("slug"), req)
+	if err != nil {
+		ServiceError(c, err)
+		return
+	}
+
+	Success(c, http.StatusOK, series)
+}
+
+// RemovePost removes a post from a series
+func (h *SeriesHandler) RemovePost(c *gin.Context) {
+	// Get user from context
+	userUUID, exists := GetUserUUID(c)
+	if !exists {
+		Error(c, http.StatusUnauthorized, ErrCodeUnauthorized,
+			"Unauthorized", "User not authenticated",
+			"Please login to modify this series")
+		return
+	}
+	role, _ := GetUserRole(c)
+
+	// Parse post UUID
+	postUUID, err := uuid.Parse(c.Param("postId"))
+	if err != nil {
+		Error(c, http.StatusBadRequest, ErrCodeValidationFailed,
+			"Invalid post ID", "Post ID must be a valid UUID",
+			"Provide a valid post UUID")
+		return
+	}
+
+	// Remove post
+	series, err := h.service.RemovePost(c.Request.Context(), userUUID, role, c.Param("slug"), postUUID)
+	if err != nil {
+		ServiceError(c, err)
+		return
+	}
+
+	Success(c, http.StatusOK, series)
+}
+
+// ReorderPosts sets the order of the posts in a series
+func (h *SeriesHandler) ReorderPosts(c *gin.Context) {
+	// Get user from context
+	userUUID, exists := GetUserUUID(c)
+	if !exists {
+		Error(c, http.StatusUnauthorized, ErrCodeUnauthorized,
+			"Unauthorized", "User not authenticated",
+			"Please login to modify this series")
+		return
+	}
+	role, _ := GetUserRole(c)
+
+	// Parse request
+	var req domain.ReorderSeriesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		BindError(c, err)
+		return
+	}
+
+	// Validate
+	if err := h.validate.Struct(req); err != nil {
+		ValidationError(c, err)
+		return
+	}
+
+	// Reorder posts
+	series, err := h.service.Reorder(c.Request.Context(), userUUID, role, c.Param("slug"), req)
+	if err != nil {
+		ServiceError(c, err)
+		return
+	}
+
+	Success(c, http.StatusOK, series)
+}
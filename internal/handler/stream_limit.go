@@ -0,0 +1,100 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/saimonsiddique/blog-api/internal/metrics"
+)
+
+// StreamLimiter caps the number of open streaming connections, such as SSE
+// streams, per client and across the instance. Clients are identified by user
+// when authenticated and by IP address otherwise. A slot is freed when the
+// client disconnects.
+type StreamLimiter struct {
+	perClient  int
+	total      int
+	retryAfter time.Duration
+	mu         sync.Mutex
+	open       int
+	perKey     map[string]int
+}
+
+// NewStreamLimiter creates a limiter allowing perClient connections per client
+// and total connections overall. Rejected clients are told to retry after
+// retryAfter.
+func NewStreamLimiter(perClient, total int, retryAfter time.Duration) *StreamLimiter {
+	return &StreamLimiter{
+		perClient:  perClient,
+		total:      total,
+		retryAfter: retryAfter,
+		perKey:     make(map[string]int),
+	}
+}
+
+// Middleware rejects a connection to the named stream with 429 when the
+// client already has the maximum number of streams open, and with 503 when
+// the instance does. Authenticated streams must register it after
+// AuthMiddleware.
+func (l *StreamLimiter) Middleware(stream string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := "ip:" + c.ClientIP()
+		if userUUID, exists := GetUserUUID(c); exists {
+			key = "user:" + userUUID.String()
+		}
+
+		clientFull, instanceFull := l.acquire(key)
+		if clientFull || instanceFull {
+			c.Header("Retry-After", strconv.Itoa(int(l.retryAfter.Seconds())))
+			if clientFull {
+				Error(c, http.StatusTooManyRequests, ErrCodeTooManyRequests,
+					"Too many open streams", "You have too many streaming connections open",
+					"Close an existing stream before opening another")
+			} else {
+				Error(c, http.StatusServiceUnavailable, ErrCodeServiceUnavailable,
+					"Service unavailable", "The server has too many streaming connections open",
+					"Retry the connection later")
+			}
+			c.Abort()
+			return
+		}
+
+		metrics.StreamConnections.WithLabelValues(stream).Inc()
+		defer func() {
+			l.release(key)
+			metrics.StreamConnections.WithLabelValues(stream).Dec()
+		}()
+
+		c.Next()
+	}
+}
+
+func (l *StreamLimiter) acquire(key string) (clientFull, instanceFull bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.perKey[key] >= l.perClient {
+		return true, false
+	}
+	if l.open >= l.total {
+		return false, true
+	}
+
+	l.perKey[key]++
+	l.open++
+	return false, false
+}
+
+func (l *StreamLimiter) release(key string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.open--
+	l.perKey[key]--
+	if l.perKey[key] <= 0 {
+		delete(l.perKey, key)
+	}
+}
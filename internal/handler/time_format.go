@@ -0,0 +1,27 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/saimonsiddique/blog-api/internal/pkg/jsontime"
+)
+
+// timeFormatKey holds the format JSON responses encode timestamps in
+const timeFormatKey = "timeFormat"
+
+// TimeFormatMiddleware sets the format timestamps in JSON responses are
+// encoded in: jsontime.FormatRFC3339 strings or jsontime.FormatUnixMillis
+// numbers. XML responses always use RFC3339.
+func TimeFormatMiddleware(format string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(timeFormatKey, format)
+		c.Next()
+	}
+}
+
+// jsonBody returns data ready to be encoded in the request's time format
+func jsonBody(c *gin.Context, data interface{}) interface{} {
+	if c.GetString(timeFormatKey) == jsontime.FormatUnixMillis {
+		return jsontime.UnixMillis(data)
+	}
+	return data
+}
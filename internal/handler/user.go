@@ -50,7 +50,7 @@ func (h *UserHandler) UpdateProfile(c *gin.Context) {
 
 	var req domain.UpdateProfileRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		ValidationError(c, err)
+		BindError(c, err)
 		return
 	}
 
@@ -67,3 +67,25 @@ func (h *UserHandler) UpdateProfile(c *gin.Context) {
 
 	Success(c, http.StatusOK, resp)
 }
+
+// GetUsersBatch returns the public profiles of several users at once
+func (h *UserHandler) GetUsersBatch(c *gin.Context) {
+	var req domain.UsersBatchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		BindError(c, err)
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		ValidationError(c, err)
+		return
+	}
+
+	resp, err := h.userService.GetPublicProfiles(c.Request.Context(), req)
+	if err != nil {
+		ServiceError(c, err)
+		return
+	}
+
+	Success(c, http.StatusOK, resp)
+}
@@ -0,0 +1,42 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/saimonsiddique/blog-api/internal/domain"
+	"github.com/saimonsiddique/blog-api/internal/pkg/slug"
+)
+
+type UtilsHandler struct {
+	slugMaxLength int
+	validate      *validator.Validate
+}
+
+func NewUtilsHandler(slugMaxLength int) *UtilsHandler {
+	return &UtilsHandler{
+		slugMaxLength: slugMaxLength,
+		validate:      validator.New(),
+	}
+}
+
+// Slugify previews the slug that would be generated for a title
+func (h *UtilsHandler) Slugify(c *gin.Context) {
+	// Parse query parameters
+	var req domain.SlugifyRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		ValidationError(c, err)
+		return
+	}
+
+	// Validate
+	if err := h.validate.Struct(req); err != nil {
+		ValidationError(c, err)
+		return
+	}
+
+	Success(c, http.StatusOK, domain.SlugifyResponse{
+		Slug: slug.GenerateWithMaxLength(req.Title, h.slugMaxLength),
+	})
+}
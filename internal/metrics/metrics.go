@@ -0,0 +1,62 @@
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "blog_api"
+
+var (
+	// QueueDepth tracks the number of messages waiting in a queue
+	QueueDepth = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "queue_depth",
+		Help:      "Number of messages ready for delivery in the queue.",
+	}, []string{"queue"})
+
+	// EventProcessingLatency tracks the time from an event being due, when it
+	// was requested or scheduled for, to it being processed
+	EventProcessingLatency = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "event_processing_latency_seconds",
+		Help:      "Time between an event being due (requested, or scheduled for if later) and it being processed.",
+		Buckets:   []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60, 300},
+	}, []string{"queue"})
+
+	// EventRetries counts messages requeued for another delivery attempt
+	EventRetries = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "event_retries_total",
+		Help:      "Number of messages requeued after a processing failure.",
+	}, []string{"queue"})
+
+	// EventsDeadLettered counts messages rejected without requeue
+	EventsDeadLettered = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "events_dead_lettered_total",
+		Help:      "Number of messages rejected without requeue.",
+	}, []string{"queue"})
+
+	// CacheRequests counts cache lookups by outcome, hit or miss
+	CacheRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "cache_requests_total",
+		Help:      "Number of cache lookups, by cache and result (hit or miss).",
+	}, []string{"cache", "result"})
+
+	// StreamConnections tracks the number of open streaming connections
+	StreamConnections = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "stream_connections",
+		Help:      "Number of open streaming connections, by stream.",
+	}, []string{"stream"})
+)
+
+// Handler returns the HTTP handler exposing the registered metrics
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
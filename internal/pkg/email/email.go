@@ -0,0 +1,38 @@
+package email
+
+import "strings"
+
+// gmailDomains are the domains for which Gmail address aliasing applies
+var gmailDomains = map[string]bool{
+	"gmail.com":      true,
+	"googlemail.com": true,
+}
+
+// Normalize returns the form of an email address used for matching accounts.
+// Addresses are lowercased; with stripGmailAliases set, dots and "+suffix"
+// aliases are removed from Gmail local parts, since Gmail ignores them.
+func Normalize(address string, stripGmailAliases bool) string {
+	address = strings.ToLower(strings.TrimSpace(address))
+
+	if !stripGmailAliases {
+		return address
+	}
+
+	at := strings.LastIndex(address, "@")
+	if at < 0 {
+		return address
+	}
+
+	local, domain := address[:at], address[at+1:]
+	if !gmailDomains[domain] {
+		return address
+	}
+
+	if plus := strings.Index(local, "+"); plus >= 0 {
+		local = local[:plus]
+	}
+	local = strings.ReplaceAll(local, ".", "")
+
+	// Gmail treats googlemail.com as an alias of gmail.com
+	return local + "@gmail.com"
+}
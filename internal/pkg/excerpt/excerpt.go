@@ -0,0 +1,83 @@
+package excerpt
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// Strategies for generating excerpts
+const (
+	StrategyChars          = "chars"
+	StrategyWords          = "words"
+	StrategyFirstParagraph = "first-paragraph"
+)
+
+const ellipsis = "…"
+
+// ValidStrategy reports whether strategy is supported
+func ValidStrategy(strategy string) bool {
+	switch strategy {
+	case StrategyChars, StrategyWords, StrategyFirstParagraph:
+		return true
+	}
+	return false
+}
+
+// Generate builds an excerpt from content. length is a word count for
+// StrategyWords and a character count otherwise; first-paragraph excerpts are
+// also capped at length characters. Content that already fits is returned
+// whole, with whitespace collapsed. Character counts include the ellipsis
+// ending a shortened excerpt.
+func Generate(content, strategy string, length int) string {
+	switch strategy {
+	case StrategyWords:
+		return words(content, length)
+	case StrategyFirstParagraph:
+		return chars(firstParagraph(content), length)
+	default:
+		return chars(content, length)
+	}
+}
+
+// Truncate shortens text to at most length characters, ellipsis included,
+// as the "chars" strategy does
+func Truncate(text string, length int) string {
+	return chars(text, length)
+}
+
+// chars cuts at the last word boundary that leaves room for the ellipsis
+// within length characters
+func chars(content string, length int) string {
+	text := strings.Join(strings.Fields(content), " ")
+	if utf8.RuneCountInString(text) <= length {
+		return text
+	}
+
+	runes := []rune(text)
+	cut := string(runes[:length])
+	if idx := strings.LastIndex(cut, " "); idx > 0 {
+		cut = cut[:idx]
+	} else {
+		cut = string(runes[:length-1])
+	}
+
+	return strings.TrimRight(cut, " .,;:") + ellipsis
+}
+
+func words(content string, length int) string {
+	fields := strings.Fields(content)
+	if len(fields) <= length {
+		return strings.Join(fields, " ")
+	}
+
+	return strings.TrimRight(strings.Join(fields[:length], " "), ".,;:") + ellipsis
+}
+
+// firstParagraph returns the text before the first blank line
+func firstParagraph(content string) string {
+	content = strings.TrimSpace(strings.ReplaceAll(content, "\r\n", "\n"))
+	if idx := strings.Index(content, "\n\n"); idx >= 0 {
+		return content[:idx]
+	}
+	return content
+}
@@ -0,0 +1,118 @@
+package excerpt
+
+import (
+	"testing"
+	"unicode/utf8"
+)
+
+func TestGenerate(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		strategy string
+		length   int
+		want     string
+	}{
+		// chars
+		{name: "chars shorter than target", content: "Hello world", strategy: StrategyChars, length: 20, want: "Hello world"},
+		{name: "chars exactly target", content: "Hello world", strategy: StrategyChars, length: 11, want: "Hello world"},
+		{name: "chars whitespace collapsed", content: "  Hello \n world  ", strategy: StrategyChars, length: 20, want: "Hello world"},
+		{name: "chars cut at word boundary", content: "The quick brown fox jumps", strategy: StrategyChars, length: 12, want: "The quick…"},
+		{name: "chars cut on a space", content: "The quick brown", strategy: StrategyChars, length: 10, want: "The quick…"},
+		{name: "chars trailing punctuation dropped", content: "Hello, world and more", strategy: StrategyChars, length: 8, want: "Hello…"},
+		{name: "chars single long word", content: "supercalifragilistic", strategy: StrategyChars, length: 5, want: "supe…"},
+		{name: "chars multibyte without spaces", content: "日本語のテキストです", strategy: StrategyChars, length: 4, want: "日本語…"},
+		{name: "chars multibyte words", content: "café crème brûlée", strategy: StrategyChars, length: 12, want: "café crème…"},
+		{name: "chars empty", content: "", strategy: StrategyChars, length: 10, want: ""},
+
+		// words
+		{name: "words shorter than target", content: "one two three", strategy: StrategyWords, length: 5, want: "one two three"},
+		{name: "words cut", content: "one two three four", strategy: StrategyWords, length: 2, want: "one two…"},
+		{name: "words trailing punctuation dropped", content: "Hello, world. Again", strategy: StrategyWords, length: 2, want: "Hello, world…"},
+		{name: "words whitespace collapsed", content: "  spaced   out\n words ", strategy: StrategyWords, length: 2, want: "spaced out…"},
+		{name: "words multibyte", content: "日本 語の テキスト", strategy: StrategyWords, length: 2, want: "日本 語の…"},
+
+		// first-paragraph
+		{name: "first paragraph", content: "First para.\n\nSecond para.", strategy: StrategyFirstParagraph, length: 100, want: "First para."},
+		{name: "first paragraph CRLF", content: "First para.\r\n\r\nSecond para.", strategy: StrategyFirstParagraph, length: 100, want: "First para."},
+		{name: "first paragraph leading blank lines", content: "\n\n  Leading para\n\nNext", strategy: StrategyFirstParagraph, length: 100, want: "Leading para"},
+		{name: "first paragraph single paragraph", content: "Line one\nline two", strategy: StrategyFirstParagraph, length: 100, want: "Line one line two"},
+		{name: "first paragraph capped", content: "The quick brown fox\n\nNext", strategy: StrategyFirstParagraph, length: 10, want: "The quick…"},
+
+		{name: "unknown strategy uses chars", content: "The quick brown fox jumps", strategy: "sentences", length: 12, want: "The quick…"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Generate(tt.content, tt.strategy, tt.length); got != tt.want {
+				t.Errorf("Generate(%q, %q, %d) = %q, want %q", tt.content, tt.strategy, tt.length, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidStrategy(t *testing.T) {
+	tests := []struct {
+		strategy string
+		want     bool
+	}{
+		{strategy: StrategyChars, want: true},
+		{strategy: StrategyWords, want: true},
+		{strategy: StrategyFirstParagraph, want: true},
+		{strategy: "", want: false},
+		{strategy: "Words", want: false},
+		{strategy: "sentences", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.strategy, func(t *testing.T) {
+			if got := ValidStrategy(tt.strategy); got != tt.want {
+				t.Errorf("ValidStrategy(%q) = %v, want %v", tt.strategy, got, tt.want)
+			}
+		})
+	}
+}
+
+// Shortened excerpts fit within length, counting the ellipsis
+func TestCharsFitWithinLength(t *testing.T) {
+	contents := []string{
+		"The quick brown fox jumps over the lazy dog",
+		"supercalifragilisticexpialidocious",
+		"日本語のテキストです 日本語のテキストです",
+		"a b c d e f g h",
+		"Hello, world. And more, and more.",
+	}
+
+	for _, content := range contents {
+		for length := 1; length <= 45; length++ {
+			for _, strategy := range []string{StrategyChars, StrategyFirstParagraph} {
+				if got := Generate(content, strategy, length); utf8.RuneCountInString(got) > length {
+					t.Errorf("Generate(%q, %q, %d) = %q, longer than %d characters", content, strategy, length, got, length)
+				}
+			}
+			if got := Truncate(content, length); utf8.RuneCountInString(got) > length {
+				t.Errorf("Truncate(%q, %d) = %q, longer than %d characters", content, length, got, length)
+			}
+		}
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		text   string
+		length int
+		want   string
+	}{
+		{text: "one two three", length: 20, want: "one two three"},
+		{text: "one two three", length: 13, want: "one two three"},
+		{text: "one two three", length: 12, want: "one two…"},
+		{text: "one two three…", length: 10, want: "one two…"},
+		{text: "onetwothree", length: 6, want: "onetw…"},
+	}
+
+	for _, tt := range tests {
+		if got := Truncate(tt.text, tt.length); got != tt.want {
+			t.Errorf("Truncate(%q, %d) = %q, want %q", tt.text, tt.length, got, tt.want)
+		}
+	}
+}
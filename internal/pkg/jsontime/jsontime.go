@@ -0,0 +1,357 @@
+package jsontime
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Formats timestamps can be encoded in
+const (
+	FormatRFC3339    = "rfc3339"
+	FormatUnixMillis = "unix_ms"
+)
+
+// IsValidFormat reports whether format is a supported timestamp format
+func IsValidFormat(format string) bool {
+	return format == FormatRFC3339 || format == FormatUnixMillis
+}
+
+// UnixMillis returns a copy of v that encodes to the same JSON as v, except
+// that every time.Time is encoded as milliseconds since the Unix epoch.
+// Structs are encoded by encoding/json's rules: declared order, json tags
+// with the omitempty, omitzero and string options, promoted fields of
+// embedded structs, and dropped or shadowed fields where names collide.
+// Values with their own MarshalJSON or MarshalText, other than time.Time,
+// are encoded by those methods, so times inside them are left alone.
+func UnixMillis(v interface{}) interface{} {
+	return convert(reflect.ValueOf(v))
+}
+
+var (
+	timeType          = reflect.TypeOf(time.Time{})
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+func convert(v reflect.Value) interface{} {
+	if !v.IsValid() {
+		return nil
+	}
+
+	if v.Type() == timeType {
+		return v.Interface().(time.Time).UnixMilli()
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		if v.Kind() == reflect.Ptr && v.Type().Elem() != timeType && implementsMarshaler(v.Type()) {
+			return v.Interface()
+		}
+		return convert(v.Elem())
+	}
+
+	// Types with their own encoding, e.g. UUIDs, are left as they are
+	if implementsMarshaler(v.Type()) {
+		return v.Interface()
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		return convertStruct(v)
+	case reflect.Slice:
+		if v.IsNil() {
+			return nil
+		}
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return v.Interface()
+		}
+		return convertList(v)
+	case reflect.Array:
+		return convertList(v)
+	case reflect.Map:
+		if v.IsNil() || v.Type().Key().Kind() != reflect.String {
+			return v.Interface()
+		}
+		converted := make(map[string]interface{}, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			converted[iter.Key().String()] = convert(iter.Value())
+		}
+		return converted
+	default:
+		return v.Interface()
+	}
+}
+
+func implementsMarshaler(t reflect.Type) bool {
+	return t.Implements(jsonMarshalerType) || t.Implements(textMarshalerType)
+}
+
+func convertList(v reflect.Value) []interface{} {
+	converted := make([]interface{}, v.Len())
+	for i := range converted {
+		converted[i] = convert(v.Index(i))
+	}
+	return converted
+}
+
+// field is a struct field converted for encoding
+type field struct {
+	name  string
+	value interface{}
+}
+
+// object encodes its fields in order, as encoding/json does for structs
+type object []field
+
+func (o object) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, f := range o {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		name, err := json.Marshal(f.name)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(f.value)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(name)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// quoted encodes a value as a JSON string holding its encoding, as the
+// ",string" option does
+type quoted struct {
+	value interface{}
+}
+
+func (q quoted) MarshalJSON() ([]byte, error) {
+	encoded, err := json.Marshal(q.value)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(string(encoded))
+}
+
+func convertStruct(v reflect.Value) object {
+	fields := object{}
+	for _, sf := range structFields(v.Type()) {
+		fv, ok := fieldByIndex(v, sf.index)
+		if !ok {
+			continue
+		}
+		if sf.omitEmpty && isEmpty(fv) {
+			continue
+		}
+		if sf.omitZero && isZero(fv) {
+			continue
+		}
+
+		value := convert(fv)
+		if sf.quoted && value != nil {
+			value = quoted{value}
+		}
+		fields = append(fields, field{name: sf.name, value: value})
+	}
+	return fields
+}
+
+// fieldByIndex returns the field at index, or false if it sits in an
+// embedded struct behind a nil pointer
+func fieldByIndex(v reflect.Value, index []int) (reflect.Value, bool) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return reflect.Value{}, false
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v, true
+}
+
+// structField is a field encoding/json encodes for a struct type
+type structField struct {
+	name      string
+	index     []int
+	depth     int
+	tagged    bool
+	omitEmpty bool
+	omitZero  bool
+	quoted    bool
+}
+
+var fieldCache sync.Map // map[reflect.Type][]structField
+
+// structFields lists the fields encoding/json encodes for t, in its order.
+// Fields of untagged embedded structs are promoted; where several fields
+// share a name the shallowest wins, then the only tagged one, and otherwise
+// all of them are dropped.
+func structFields(t reflect.Type) []structField {
+	if cached, ok := fieldCache.Load(t); ok {
+		return cached.([]structField)
+	}
+
+	var all []structField
+	collectFields(t, nil, &all)
+
+	byName := map[string][]structField{}
+	for _, sf := range all {
+		byName[sf.name] = append(byName[sf.name], sf)
+	}
+
+	fields := []structField{}
+	for _, sf := range all {
+		if dominant, ok := dominantField(byName[sf.name]); ok && slices.Equal(dominant.index, sf.index) {
+			fields = append(fields, sf)
+		}
+	}
+
+	cached, _ := fieldCache.LoadOrStore(t, fields)
+	return cached.([]structField)
+}
+
+func collectFields(t reflect.Type, index []int, fields *[]structField) {
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		ft := sf.Type
+		if ft.Name() == "" && ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+
+		// Unexported embedded structs may still have exported fields
+		if !sf.IsExported() && !(sf.Anonymous && ft.Kind() == reflect.Struct) {
+			continue
+		}
+
+		tag := sf.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		fieldIndex := append(slices.Clone(index), i)
+
+		// Untagged embedded structs are flattened into the parent
+		if sf.Anonymous && name == "" && ft.Kind() == reflect.Struct {
+			collectFields(ft, fieldIndex, fields)
+			continue
+		}
+		if !sf.IsExported() {
+			continue
+		}
+
+		tagged := name != ""
+		if !tagged {
+			name = sf.Name
+		}
+
+		quoted := false
+		if hasOption(opts, "string") {
+			switch ft.Kind() {
+			case reflect.Bool, reflect.String, reflect.Float32, reflect.Float64,
+				reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+				reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+				quoted = true
+			}
+		}
+
+		*fields = append(*fields, structField{
+			name:      name,
+			index:     fieldIndex,
+			depth:     len(index),
+			tagged:    tagged,
+			omitEmpty: hasOption(opts, "omitempty"),
+			omitZero:  hasOption(opts, "omitzero"),
+			quoted:    quoted,
+		})
+	}
+}
+
+// dominantField picks the field encoded for a name, or false if the fields
+// sharing it conflict
+func dominantField(fields []structField) (structField, bool) {
+	depth := fields[0].depth
+	for _, sf := range fields[1:] {
+		depth = min(depth, sf.depth)
+	}
+
+	var shallowest []structField
+	for _, sf := range fields {
+		if sf.depth == depth {
+			shallowest = append(shallowest, sf)
+		}
+	}
+	if len(shallowest) == 1 {
+		return shallowest[0], true
+	}
+
+	var tagged []structField
+	for _, sf := range shallowest {
+		if sf.tagged {
+			tagged = append(tagged, sf)
+		}
+	}
+	if len(tagged) == 1 {
+		return tagged[0], true
+	}
+
+	return structField{}, false
+}
+
+func hasOption(opts, option string) bool {
+	for _, opt := range strings.Split(opts, ",") {
+		if opt == option {
+			return true
+		}
+	}
+	return false
+}
+
+// isZero matches encoding/json's definition of a zero value for omitzero:
+// an IsZero method decides if the type has one
+func isZero(v reflect.Value) bool {
+	if z, ok := v.Interface().(interface{ IsZero() bool }); ok {
+		if v.Kind() == reflect.Ptr && v.IsNil() {
+			return true
+		}
+		return z.IsZero()
+	}
+	return v.IsZero()
+}
+
+// isEmpty matches encoding/json's definition of an empty value for omitempty
+func isEmpty(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return v.Float() == 0
+	case reflect.Interface, reflect.Ptr:
+		return v.IsNil()
+	}
+	return false
+}
@@ -0,0 +1,214 @@
+package jsontime
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/saimonsiddique/blog-api/internal/domain"
+)
+
+type inner struct {
+	A string `json:"a"`
+	B int    `json:"b,omitempty"`
+}
+
+type embeddedPointer struct {
+	*inner
+	C string `json:"c"`
+}
+
+type options struct {
+	Count     int               `json:"count,string"`
+	Ratio     float64           `json:"ratio,string"`
+	Flag      bool              `json:"flag,string"`
+	Name      string            `json:"name,string"`
+	Pointer   *int              `json:"pointer,string"`
+	NilString *int              `json:"nilString,string"`
+	Slice     []int             `json:"slice,string"`
+	Empty     string            `json:"empty,omitempty"`
+	EmptyMap  map[string]string `json:"emptyMap,omitempty"`
+	Zero      inner             `json:"zero,omitzero"`
+	ZeroUUID  uuid.UUID         `json:"zeroUuid,omitzero"`
+	Untagged  string
+	Skipped   string `json:"-"`
+	Dash      string `json:"-,"`
+	private   string
+}
+
+// Fields named alike at the same depth cancel out; a shallower or the only
+// tagged one wins
+type shadowA struct {
+	Name  string
+	Same  string
+	Deep  string
+	Tag   string `json:"tag"`
+	Clash string
+}
+
+type shadowB struct {
+	Name  string
+	Same  string
+	Tag   string
+	Clash string
+}
+
+type shadowNested struct {
+	shadowB
+}
+
+type shadowing struct {
+	shadowA
+	shadowNested
+	Name string
+	Deep string `json:"Same"`
+}
+
+type lowercase struct {
+	Visible string `json:"visible"`
+}
+
+type unexportedEmbedded struct {
+	lowercase
+	Other string `json:"other"`
+}
+
+// Values without timestamps encode exactly as encoding/json encodes them
+func TestUnixMillisMatchesEncodingJSON(t *testing.T) {
+	seven := 7
+	id := uuid.MustParse("7a0e7b5e-3f4b-4b8e-9a52-4a3c2a1d0f6e")
+
+	tests := []struct {
+		name  string
+		value interface{}
+	}{
+		{name: "nil", value: nil},
+		{name: "string", value: "hello"},
+		{name: "uuid", value: id},
+		{name: "uuid pointer", value: &id},
+		{name: "nil uuid pointer", value: (*uuid.UUID)(nil)},
+		{name: "slice of uuids", value: []uuid.UUID{id, uuid.Nil}},
+		{name: "nil slice", value: []string(nil)},
+		{name: "empty slice", value: []string{}},
+		{name: "bytes", value: []byte("raw")},
+		{name: "raw message", value: json.RawMessage(`{"kept":true}`)},
+		{name: "nested slices", value: [][]int{{1, 2}, nil, {}}},
+		{name: "nested maps", value: map[string]map[string][]int{"a": {"b": {1}}, "c": nil}},
+		{name: "int keyed map", value: map[int]string{1: "one", 2: "two"}},
+		{name: "array", value: [2]string{"a", "b"}},
+		{name: "embedded pointer", value: embeddedPointer{inner: &inner{A: "a", B: 2}, C: "c"}},
+		{name: "nil embedded pointer", value: embeddedPointer{C: "c"}},
+		{name: "options", value: options{
+			Count: 3, Ratio: 1.5, Flag: true, Name: `say "hi"`, Pointer: &seven,
+			Slice: []int{1}, Untagged: "u", Skipped: "s", Dash: "d", private: "p",
+		}},
+		{name: "options left empty", value: options{}},
+		{name: "options with zero set", value: options{Zero: inner{A: "a"}, ZeroUUID: id, Empty: "e", EmptyMap: map[string]string{"k": "v"}}},
+		{name: "shadowing", value: shadowing{
+			shadowA:      shadowA{Name: "a", Same: "a", Deep: "a", Tag: "a", Clash: "a"},
+			shadowNested: shadowNested{shadowB{Name: "b", Same: "b", Tag: "b", Clash: "b"}},
+			Name:         "outer",
+			Deep:         "outer",
+		}},
+		{name: "unexported embedded", value: unexportedEmbedded{lowercase: lowercase{Visible: "v"}, Other: "o"}},
+		{name: "post author", value: domain.PostAuthor{UUID: id, Username: "author"}},
+		{name: "interface slice", value: []interface{}{1, "two", nil, inner{A: "a"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want, err := json.Marshal(tt.value)
+			if err != nil {
+				t.Fatalf("json.Marshal() error = %v", err)
+			}
+			got, err := json.Marshal(UnixMillis(tt.value))
+			if err != nil {
+				t.Fatalf("json.Marshal(UnixMillis()) error = %v", err)
+			}
+			if string(got) != string(want) {
+				t.Errorf("UnixMillis() encodes to\n%s\nwant\n%s", got, want)
+			}
+		})
+	}
+}
+
+type timestamps struct {
+	At       time.Time             `json:"at"`
+	Optional *time.Time            `json:"optional,omitempty"`
+	Nullable *time.Time            `json:"nullable"`
+	List     []time.Time           `json:"list"`
+	ByName   map[string]*time.Time `json:"byName"`
+	Nested   []map[string][]time.Time
+}
+
+func TestUnixMillis(t *testing.T) {
+	at := time.Date(2026, 1, 2, 3, 4, 5, 6_000_000, time.UTC)
+	id := uuid.MustParse("7a0e7b5e-3f4b-4b8e-9a52-4a3c2a1d0f6e")
+	excerpt := "Excerpt"
+
+	tests := []struct {
+		name  string
+		value interface{}
+		want  string
+	}{
+		{name: "time", value: at, want: "1767323045006"},
+		{name: "time pointer", value: &at, want: "1767323045006"},
+		{name: "nil time pointer", value: (*time.Time)(nil), want: "null"},
+		{
+			name:  "nil time pointers",
+			value: timestamps{At: at},
+			want:  `{"at":1767323045006,"nullable":null,"list":null,"byName":null,"Nested":null}`,
+		},
+		{
+			name: "nested times",
+			value: timestamps{
+				At:       at,
+				Optional: &at,
+				Nullable: &at,
+				List:     []time.Time{at, at},
+				ByName:   map[string]*time.Time{"set": &at, "unset": nil},
+				Nested:   []map[string][]time.Time{{"times": {at}}},
+			},
+			want: `{"at":1767323045006,"optional":1767323045006,"nullable":1767323045006,` +
+				`"list":[1767323045006,1767323045006],"byName":{"set":1767323045006,"unset":null},` +
+				`"Nested":[{"times":[1767323045006]}]}`,
+		},
+		{
+			name: "post with author",
+			value: domain.PostWithAuthor{
+				Post: domain.Post{
+					ID: 1, UUID: id, AuthorID: 2, Title: "Title", Slug: "title", Content: "Content",
+					Excerpt: &excerpt, Format: domain.PostFormatMarkdown, Visibility: domain.PostVisibilityPublic,
+					Status: domain.PostStatusPublished, PublishedAt: &at, CreatedAt: at, UpdatedAt: at,
+				},
+				Author: domain.PostAuthor{UUID: id, Username: "author"},
+			},
+			want: `{"id":1,"uuid":"7a0e7b5e-3f4b-4b8e-9a52-4a3c2a1d0f6e","authorId":2,"title":"Title",` +
+				`"slug":"title","content":"Content","excerpt":"Excerpt","format":"markdown","visibility":"public",` +
+				`"status":"published","publishedAt":1767323045006,"createdAt":1767323045006,"updatedAt":1767323045006,` +
+				`"author":{"uuid":"7a0e7b5e-3f4b-4b8e-9a52-4a3c2a1d0f6e","username":"author"}}`,
+		},
+		{
+			name: "unpublished post omits its publish time",
+			value: domain.PostWithAuthor{
+				Post: domain.Post{UUID: id, CreatedAt: at, UpdatedAt: at},
+			},
+			want: `{"id":0,"uuid":"7a0e7b5e-3f4b-4b8e-9a52-4a3c2a1d0f6e","authorId":0,"title":"","slug":"",` +
+				`"content":"","format":"","visibility":"","status":"","createdAt":1767323045006,"updatedAt":1767323045006,` +
+				`"author":{"uuid":"00000000-0000-0000-0000-000000000000","username":""}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := json.Marshal(UnixMillis(tt.value))
+			if err != nil {
+				t.Fatalf("json.Marshal(UnixMillis()) error = %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("UnixMillis() encodes to\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
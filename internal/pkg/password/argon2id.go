@@ -0,0 +1,108 @@
+package password
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+)
+
+// argon2idParams are the cost parameters of an argon2id hash
+type argon2idParams struct {
+	memory  uint32 // KiB
+	time    uint32
+	threads uint8
+	keyLen  uint32
+}
+
+// argon2idHasher encodes hashes in the PHC string format:
+// $argon2id$v=19$m=65536,t=3,p=4$<salt>$<key>
+type argon2idHasher struct {
+	params  argon2idParams
+	saltLen int
+}
+
+// newArgon2id uses the second recommended option of RFC 9106 (64 MiB of
+// memory, three passes), which suits servers without memory to spare for
+// the first option's 2 GiB
+func newArgon2id() *argon2idHasher {
+	return &argon2idHasher{
+		params: argon2idParams{
+			memory:  64 * 1024,
+			time:    3,
+			threads: 4,
+			keyLen:  32,
+		},
+		saltLen: 16,
+	}
+}
+
+func (h *argon2idHasher) Hash(password string) (string, error) {
+	salt := make([]byte, h.saltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+
+	p := h.params
+	key := argon2.IDKey([]byte(password), salt, p.time, p.memory, p.threads, p.keyLen)
+
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version, p.memory, p.time, p.threads,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	), nil
+}
+
+func (h *argon2idHasher) Verify(hashed, password string) error {
+	p, salt, key, err := decodeArgon2id(hashed)
+	if err != nil {
+		return err
+	}
+
+	candidate := argon2.IDKey([]byte(password), salt, p.time, p.memory, p.threads, p.keyLen)
+	if subtle.ConstantTimeCompare(key, candidate) != 1 {
+		return ErrMismatch
+	}
+	return nil
+}
+
+func (h *argon2idHasher) NeedsRehash(hashed string) bool {
+	p, salt, _, err := decodeArgon2id(hashed)
+	return err != nil || p != h.params || len(salt) != h.saltLen
+}
+
+// decodeArgon2id parses a PHC-format argon2id hash
+func decodeArgon2id(hashed string) (argon2idParams, []byte, []byte, error) {
+	var p argon2idParams
+
+	// "", "argon2id", "v=19", "m=...,t=...,p=...", salt, key
+	parts := strings.Split(hashed, "$")
+	if len(parts) != 6 || parts[1] != AlgorithmArgon2id {
+		return p, nil, nil, ErrUnknownHash
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return p, nil, nil, ErrUnknownHash
+	}
+
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &p.memory, &p.time, &p.threads); err != nil {
+		return p, nil, nil, ErrUnknownHash
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return p, nil, nil, ErrUnknownHash
+	}
+
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(key) == 0 {
+		return p, nil, nil, ErrUnknownHash
+	}
+	p.keyLen = uint32(len(key))
+
+	return p, salt, key, nil
+}
@@ -0,0 +1,40 @@
+package password
+
+import (
+	"errors"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+type bcryptHasher struct {
+	cost int
+}
+
+func newBcrypt() *bcryptHasher {
+	return &bcryptHasher{cost: bcrypt.DefaultCost}
+}
+
+func (h *bcryptHasher) Hash(password string) (string, error) {
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), h.cost)
+	if err != nil {
+		return "", err
+	}
+	return string(hashedPassword), nil
+}
+
+func (h *bcryptHasher) Verify(hashed, password string) error {
+	err := bcrypt.CompareHashAndPassword([]byte(hashed), []byte(password))
+	if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+		return ErrMismatch
+	}
+	return err
+}
+
+func (h *bcryptHasher) NeedsRehash(hashed string) bool {
+	if algorithmOf(hashed) != AlgorithmBcrypt {
+		return true
+	}
+
+	cost, err := bcrypt.Cost([]byte(hashed))
+	return err != nil || cost != h.cost
+}
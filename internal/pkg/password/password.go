@@ -1,15 +1,88 @@
 package password
 
-import "golang.org/x/crypto/bcrypt"
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
 
-func Hash(password string) (string, error) {
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
-	if err != nil {
-		return "", err
+// Supported hashing algorithms
+const (
+	AlgorithmBcrypt   = "bcrypt"
+	AlgorithmArgon2id = "argon2id"
+)
+
+// ErrMismatch is returned when a password doesn't match its hash
+var ErrMismatch = errors.New("password does not match")
+
+// ErrUnknownHash is returned for a stored hash in no supported format
+var ErrUnknownHash = errors.New("unrecognized password hash format")
+
+// Hasher hashes and verifies passwords. Hashes are self-describing: they
+// encode their algorithm and parameters, so they can be verified after the
+// configured algorithm or parameters change.
+type Hasher interface {
+	// Hash returns the encoded hash of password
+	Hash(password string) (string, error)
+	// Verify returns ErrMismatch when password doesn't match hashed
+	Verify(hashed, password string) error
+	// NeedsRehash reports whether hashed was made with a different algorithm
+	// or parameters than this hasher uses
+	NeedsRehash(hashed string) bool
+}
+
+// IsValidAlgorithm reports whether algorithm is supported
+func IsValidAlgorithm(algorithm string) bool {
+	return algorithm == AlgorithmBcrypt || algorithm == AlgorithmArgon2id
+}
+
+// New returns a Hasher that hashes new passwords with algorithm and verifies
+// hashes made with any supported algorithm
+func New(algorithm string) (Hasher, error) {
+	hashers := map[string]Hasher{
+		AlgorithmBcrypt:   newBcrypt(),
+		AlgorithmArgon2id: newArgon2id(),
+	}
+
+	preferred, ok := hashers[algorithm]
+	if !ok {
+		return nil, fmt.Errorf("unsupported password hashing algorithm: %s", algorithm)
+	}
+
+	return &dispatcher{preferred: preferred, hashers: hashers}, nil
+}
+
+// dispatcher hashes with the preferred algorithm and verifies with whichever
+// algorithm produced the stored hash
+type dispatcher struct {
+	preferred Hasher
+	hashers   map[string]Hasher
+}
+
+func (d *dispatcher) Hash(password string) (string, error) {
+	return d.preferred.Hash(password)
+}
+
+func (d *dispatcher) Verify(hashed, password string) error {
+	hasher, ok := d.hashers[algorithmOf(hashed)]
+	if !ok {
+		return ErrUnknownHash
 	}
-	return string(hashedPassword), nil
+	return hasher.Verify(hashed, password)
 }
 
-func Verify(hashedPassword, password string) error {
-	return bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(password))
+func (d *dispatcher) NeedsRehash(hashed string) bool {
+	return d.preferred.NeedsRehash(hashed)
+}
+
+// algorithmOf identifies the algorithm of an encoded hash from its prefix
+func algorithmOf(hashed string) string {
+	switch {
+	case strings.HasPrefix(hashed, "$argon2id$"):
+		return AlgorithmArgon2id
+	case strings.HasPrefix(hashed, "$2a$"), strings.HasPrefix(hashed, "$2b$"), strings.HasPrefix(hashed, "$2y$"):
+		return AlgorithmBcrypt
+	default:
+		return ""
+	}
 }
@@ -0,0 +1,180 @@
+package password
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+func newHasher(t *testing.T, algorithm string) Hasher {
+	t.Helper()
+	hasher, err := New(algorithm)
+	if err != nil {
+		t.Fatalf("New(%q) error = %v", algorithm, err)
+	}
+	return hasher
+}
+
+func TestNewUnsupportedAlgorithm(t *testing.T) {
+	if _, err := New("md5"); err == nil {
+		t.Error("New(\"md5\") error = nil, want an error")
+	}
+}
+
+func TestHashRoundTrip(t *testing.T) {
+	tests := []struct {
+		algorithm string
+		prefix    string
+	}{
+		{algorithm: AlgorithmBcrypt, prefix: "$2a$"},
+		{algorithm: AlgorithmArgon2id, prefix: "$argon2id$v=19$"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.algorithm, func(t *testing.T) {
+			hasher := newHasher(t, tt.algorithm)
+
+			hashed, err := hasher.Hash("correct horse battery staple")
+			if err != nil {
+				t.Fatalf("Hash() error = %v", err)
+			}
+			if !strings.HasPrefix(hashed, tt.prefix) {
+				t.Errorf("Hash() = %q, want prefix %q", hashed, tt.prefix)
+			}
+
+			if err := hasher.Verify(hashed, "correct horse battery staple"); err != nil {
+				t.Errorf("Verify() with the right password error = %v", err)
+			}
+			if err := hasher.Verify(hashed, "wrong password"); !errors.Is(err, ErrMismatch) {
+				t.Errorf("Verify() with a wrong password error = %v, want ErrMismatch", err)
+			}
+			if hasher.NeedsRehash(hashed) {
+				t.Error("NeedsRehash() = true for a fresh hash")
+			}
+		})
+	}
+}
+
+func TestHashIsSalted(t *testing.T) {
+	for _, algorithm := range []string{AlgorithmBcrypt, AlgorithmArgon2id} {
+		t.Run(algorithm, func(t *testing.T) {
+			hasher := newHasher(t, algorithm)
+
+			first, err := hasher.Hash("password")
+			if err != nil {
+				t.Fatalf("Hash() error = %v", err)
+			}
+			second, err := hasher.Hash("password")
+			if err != nil {
+				t.Fatalf("Hash() error = %v", err)
+			}
+			if first == second {
+				t.Error("Hash() returned the same hash twice")
+			}
+		})
+	}
+}
+
+// Hashes made before a change of algorithm keep verifying, and are flagged for
+// rehashing so they move to the configured algorithm on the next login
+func TestCrossAlgorithm(t *testing.T) {
+	bcryptHasher := newHasher(t, AlgorithmBcrypt)
+	argonHasher := newHasher(t, AlgorithmArgon2id)
+
+	legacy, err := bcrypt.GenerateFromPassword([]byte("password"), bcrypt.DefaultCost)
+	if err != nil {
+		t.Fatalf("failed to make a legacy bcrypt hash: %v", err)
+	}
+	argonHash, err := argonHasher.Hash("password")
+	if err != nil {
+		t.Fatalf("Hash() error = %v", err)
+	}
+
+	tests := []struct {
+		name            string
+		hasher          Hasher
+		hashed          string
+		wantNeedsRehash bool
+	}{
+		{name: "legacy bcrypt with bcrypt configured", hasher: bcryptHasher, hashed: string(legacy)},
+		{name: "legacy bcrypt with argon2id configured", hasher: argonHasher, hashed: string(legacy), wantNeedsRehash: true},
+		{name: "argon2id with bcrypt configured", hasher: bcryptHasher, hashed: argonHash, wantNeedsRehash: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.hasher.Verify(tt.hashed, "password"); err != nil {
+				t.Errorf("Verify() error = %v", err)
+			}
+			if err := tt.hasher.Verify(tt.hashed, "wrong"); !errors.Is(err, ErrMismatch) {
+				t.Errorf("Verify() with a wrong password error = %v, want ErrMismatch", err)
+			}
+			if got := tt.hasher.NeedsRehash(tt.hashed); got != tt.wantNeedsRehash {
+				t.Errorf("NeedsRehash() = %v, want %v", got, tt.wantNeedsRehash)
+			}
+		})
+	}
+}
+
+func TestNeedsRehashOnParameterChange(t *testing.T) {
+	lowCost, err := bcrypt.GenerateFromPassword([]byte("password"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("failed to make a bcrypt hash: %v", err)
+	}
+
+	weakArgon := &argon2idHasher{
+		params:  argon2idParams{memory: 8 * 1024, time: 1, threads: 1, keyLen: 32},
+		saltLen: 16,
+	}
+	weakHash, err := weakArgon.Hash("password")
+	if err != nil {
+		t.Fatalf("Hash() error = %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		algorithm string
+		hashed    string
+	}{
+		{name: "bcrypt cost changed", algorithm: AlgorithmBcrypt, hashed: string(lowCost)},
+		{name: "argon2id parameters changed", algorithm: AlgorithmArgon2id, hashed: weakHash},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hasher := newHasher(t, tt.algorithm)
+
+			if err := hasher.Verify(tt.hashed, "password"); err != nil {
+				t.Errorf("Verify() error = %v", err)
+			}
+			if !hasher.NeedsRehash(tt.hashed) {
+				t.Error("NeedsRehash() = false, want true")
+			}
+		})
+	}
+}
+
+func TestVerifyUnknownHash(t *testing.T) {
+	hasher := newHasher(t, AlgorithmBcrypt)
+
+	tests := []struct {
+		name   string
+		hashed string
+	}{
+		{name: "empty", hashed: ""},
+		{name: "plain text", hashed: "password"},
+		{name: "unsupported algorithm", hashed: "$scrypt$ln=15,r=8,p=1$c2FsdA$a2V5"},
+		{name: "truncated argon2id", hashed: "$argon2id$v=19$m=65536,t=3,p=4$c2FsdA"},
+		{name: "wrong argon2 version", hashed: "$argon2id$v=16$m=65536,t=3,p=4$c2FsdA$a2V5"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := hasher.Verify(tt.hashed, "password"); !errors.Is(err, ErrUnknownHash) {
+				t.Errorf("Verify(%q) error = %v, want ErrUnknownHash", tt.hashed, err)
+			}
+		})
+	}
+}
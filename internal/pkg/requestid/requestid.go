@@ -0,0 +1,50 @@
+package requestid
+
+import (
+	"context"
+	"encoding/hex"
+	"strings"
+)
+
+// DefaultHeader carries the request ID on HTTP requests and responses unless
+// another header is configured
+const DefaultHeader = "X-Request-ID"
+
+// TraceparentHeader is the W3C Trace Context header
+const TraceparentHeader = "traceparent"
+
+type contextKey struct{}
+
+// WithID returns a copy of ctx carrying the request ID
+func WithID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, contextKey{}, id)
+}
+
+// FromContext returns the request ID carried by ctx, or an empty string
+func FromContext(ctx context.Context) string {
+	id, _ := ctx.Value(contextKey{}).(string)
+	return id
+}
+
+// FromTraceparent returns the trace ID of a W3C traceparent header value,
+// reporting false when the value is malformed or the trace ID is all zeros
+func FromTraceparent(value string) (string, bool) {
+	// version "-" trace-id "-" parent-id "-" trace-flags
+	parts := strings.Split(strings.TrimSpace(value), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" {
+		return "", false
+	}
+
+	traceID := parts[1]
+	if len(traceID) != 32 || traceID != strings.ToLower(traceID) {
+		return "", false
+	}
+	if _, err := hex.DecodeString(traceID); err != nil {
+		return "", false
+	}
+	if traceID == strings.Repeat("0", 32) {
+		return "", false
+	}
+
+	return traceID, true
+}
@@ -0,0 +1,14 @@
+package sanitize
+
+import "github.com/microcosm-cc/bluemonday"
+
+// policy is bluemonday's UGC policy: it keeps common formatting, links and
+// images, and strips scripts, styles, iframes, event handler attributes and
+// javascript: URLs. Policies are safe for concurrent use.
+var policy = bluemonday.UGCPolicy()
+
+// HTML removes markup that could run script when the content is rendered
+// as HTML
+func HTML(s string) string {
+	return policy.Sanitize(s)
+}
@@ -4,6 +4,7 @@ import (
 	"regexp"
 	"strings"
 	"unicode"
+	"unicode/utf8"
 
 	"golang.org/x/text/transform"
 	"golang.org/x/text/unicode/norm"
@@ -35,6 +36,39 @@ func Generate(s string) string {
 	return s
 }
 
+// GenerateWithMaxLength creates a slug no longer than maxLength bytes,
+// truncating at a word boundary where possible. A maxLength of zero or less
+// disables truncation.
+func GenerateWithMaxLength(s string, maxLength int) string {
+	return Truncate(Generate(s), maxLength)
+}
+
+// Truncate shortens a slug to at most maxLength bytes, cutting at the last
+// dash so words are kept whole. A single word longer than maxLength is cut
+// mid-word. The result never ends with a dash.
+func Truncate(s string, maxLength int) string {
+	if maxLength <= 0 || len(s) <= maxLength {
+		return s
+	}
+
+	// Slugs are ASCII after Generate, so byte slicing can't split a rune;
+	// back off to a rune boundary anyway for arbitrary input
+	cut := maxLength
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+
+	// Cut at a word boundary unless the cut already falls on one
+	truncated := s[:cut]
+	if s[cut] != '-' {
+		if idx := strings.LastIndex(truncated, "-"); idx > 0 {
+			truncated = truncated[:idx]
+		}
+	}
+
+	return strings.TrimRight(truncated, "-")
+}
+
 func isMark(r rune) bool {
 	return unicode.Is(unicode.Mn, r)
 }
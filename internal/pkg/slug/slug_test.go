@@ -0,0 +1,89 @@
+package slug
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGenerate(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{name: "simple", input: "Hello World", want: "hello-world"},
+		{name: "punctuation runs", input: "  Hello,   World!!! ", want: "hello-world"},
+		{name: "dash runs", input: "a---b", want: "a-b"},
+		{name: "digits kept", input: "Go 1.22 --- released", want: "go-1-22-released"},
+		{name: "accents removed", input: "Crème Brûlée", want: "creme-brulee"},
+		{name: "umlauts removed", input: "Ünïcödé", want: "unicode"},
+		{name: "non-latin dropped", input: "Go 日本語 tips", want: "go-tips"},
+		{name: "only non-latin", input: "日本語", want: ""},
+		{name: "only punctuation", input: "!!! ---", want: ""},
+		{name: "empty", input: "", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Generate(tt.input); got != tt.want {
+				t.Errorf("Generate(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		maxLength int
+		want      string
+	}{
+		{name: "no limit", input: "hello-world", maxLength: 0, want: "hello-world"},
+		{name: "negative limit", input: "hello-world", maxLength: -1, want: "hello-world"},
+		{name: "within limit", input: "hello-world", maxLength: 20, want: "hello-world"},
+		{name: "exact limit", input: "hello-world", maxLength: 11, want: "hello-world"},
+		{name: "cut mid-word", input: "hello-world-again", maxLength: 13, want: "hello-world"},
+		{name: "cut on a dash", input: "hello-world-again", maxLength: 11, want: "hello-world"},
+		{name: "cut after a dash", input: "hello-world-again", maxLength: 12, want: "hello-world"},
+		{name: "single long word", input: "supercalifragilistic", maxLength: 5, want: "super"},
+		{name: "multibyte not split", input: "héllo-wörld", maxLength: 2, want: "h"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Truncate(tt.input, tt.maxLength); got != tt.want {
+				t.Errorf("Truncate(%q, %d) = %q, want %q", tt.input, tt.maxLength, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGenerateWithMaxLengthLongTitles(t *testing.T) {
+	tests := []struct {
+		name      string
+		title     string
+		maxLength int
+	}{
+		{name: "many words", title: strings.Repeat("word ", 50), maxLength: 100},
+		{name: "one long word", title: strings.Repeat("a", 300), maxLength: 100},
+		{name: "accented words", title: strings.Repeat("crème brûlée ", 40), maxLength: 100},
+		{name: "punctuation between words", title: strings.Repeat("go, rust & zig! ", 30), maxLength: 64},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := GenerateWithMaxLength(tt.title, tt.maxLength)
+
+			if len(got) == 0 || len(got) > tt.maxLength {
+				t.Errorf("len(slug) = %d, want 1..%d", len(got), tt.maxLength)
+			}
+			if strings.HasSuffix(got, "-") || strings.HasPrefix(got, "-") {
+				t.Errorf("slug %q has a leading or trailing dash", got)
+			}
+			if !strings.HasPrefix(Generate(tt.title), got) {
+				t.Errorf("slug %q is not a prefix of the full slug", got)
+			}
+		})
+	}
+}
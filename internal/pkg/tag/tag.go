@@ -0,0 +1,25 @@
+package tag
+
+import (
+	"errors"
+
+	"github.com/saimonsiddique/blog-api/internal/pkg/slug"
+)
+
+// MaxLength is the longest a normalized tag can be
+const MaxLength = 50
+
+// ErrEmpty is returned for tags with no usable characters
+var ErrEmpty = errors.New("tag is empty after normalization")
+
+// Normalize returns the canonical form of a tag name, so that "Go ", "GO"
+// and "go" are the same tag. Tags follow slug rules: lowercased, accents
+// removed, runs of spaces and other disallowed characters collapsed to a
+// single dash, and at most MaxLength bytes.
+func Normalize(name string) (string, error) {
+	normalized := slug.GenerateWithMaxLength(name, MaxLength)
+	if normalized == "" {
+		return "", ErrEmpty
+	}
+	return normalized, nil
+}
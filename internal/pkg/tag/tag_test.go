@@ -0,0 +1,91 @@
+package tag
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    string
+		wantErr error
+	}{
+		{name: "lowercased", input: "GoLang", want: "golang"},
+		{name: "trimmed", input: "  go  ", want: "go"},
+		{name: "spaces to dashes", input: "web  development", want: "web-development"},
+		{name: "disallowed characters stripped", input: "c++ & rust!", want: "c-rust"},
+		{name: "punctuation runs", input: "node.js -- tips", want: "node-js-tips"},
+		{name: "accents removed", input: "Café", want: "cafe"},
+		{name: "non-latin dropped", input: "go 日本", want: "go"},
+		{name: "empty", input: "", wantErr: ErrEmpty},
+		{name: "whitespace only", input: "   ", wantErr: ErrEmpty},
+		{name: "punctuation only", input: "#!?", wantErr: ErrEmpty},
+		{name: "non-latin only", input: "日本語", wantErr: ErrEmpty},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Normalize(tt.input)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Normalize(%q) error = %v, want %v", tt.input, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Normalize(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeDeduplicates(t *testing.T) {
+	tests := []struct {
+		name     string
+		variants []string
+		want     string
+	}{
+		{name: "case and space", variants: []string{"Go ", "GO", "go", " go"}, want: "go"},
+		{name: "separators", variants: []string{"web dev", "web-dev", "Web_Dev", "web   dev", "web--dev"}, want: "web-dev"},
+		{name: "accents", variants: []string{"résumé", "Resume", "RÉSUMÉ"}, want: "resume"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, variant := range tt.variants {
+				got, err := Normalize(variant)
+				if err != nil {
+					t.Fatalf("Normalize(%q) error = %v", variant, err)
+				}
+				if got != tt.want {
+					t.Errorf("Normalize(%q) = %q, want %q", variant, got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestNormalizeMaxLength(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{name: "many words", input: strings.Repeat("tag ", 30)},
+		{name: "one long word", input: strings.Repeat("x", 80)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Normalize(tt.input)
+			if err != nil {
+				t.Fatalf("Normalize() error = %v", err)
+			}
+			if len(got) > MaxLength {
+				t.Errorf("len(%q) = %d, want at most %d", got, len(got), MaxLength)
+			}
+			if strings.HasSuffix(got, "-") {
+				t.Errorf("Normalize() = %q, has a trailing dash", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package toc
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/saimonsiddique/blog-api/internal/pkg/slug"
+)
+
+var (
+	atxHeadingRegex      = regexp.MustCompile(`^ {0,3}(#{1,6})(?:[ \t]+(.*?))?(?:[ \t]+#+)?[ \t]*$`)
+	setextUnderlineRegex = regexp.MustCompile(`^ {0,3}(=+|-+)[ \t]*$`)
+	fenceRegex           = regexp.MustCompile("^ {0,3}(```|~~~)")
+	linkRegex            = regexp.MustCompile(`!?\[([^\]]*)\]\([^)]*\)`)
+	emphasisReplacer     = strings.NewReplacer("`", "", "**", "", "__", "", "*", "", "~~", "")
+)
+
+// Entry is a single heading in a table of contents
+type Entry struct {
+	Level  int
+	Text   string
+	Anchor string
+}
+
+// Extract returns the headings of Markdown content in document order. ATX
+// ("## Title") and setext (underlined) headings are recognized; headings
+// inside fenced code blocks are ignored. Anchors are slugs of the heading
+// text, suffixed with "-1", "-2", ... when a heading repeats.
+func Extract(content string) []Entry {
+	var entries []Entry
+	anchors := make(map[string]bool)
+
+	inFence := false
+	fence := ""
+	previous := ""
+
+	for _, line := range strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n") {
+		if m := fenceRegex.FindStringSubmatch(line); m != nil {
+			if !inFence {
+				inFence, fence = true, m[1]
+			} else if m[1] == fence {
+				inFence = false
+			}
+			previous = ""
+			continue
+		}
+		if inFence {
+			continue
+		}
+
+		if m := atxHeadingRegex.FindStringSubmatch(line); m != nil {
+			entries = appendEntry(entries, anchors, len(m[1]), m[2])
+			previous = ""
+			continue
+		}
+
+		// A setext underline turns the preceding paragraph line into a heading
+		if m := setextUnderlineRegex.FindStringSubmatch(line); m != nil && previous != "" {
+			level := 2
+			if m[1][0] == '=' {
+				level = 1
+			}
+			entries = appendEntry(entries, anchors, level, previous)
+			previous = ""
+			continue
+		}
+
+		previous = strings.TrimSpace(line)
+	}
+
+	return entries
+}
+
+func appendEntry(entries []Entry, anchors map[string]bool, level int, text string) []Entry {
+	text = plainText(text)
+	if text == "" {
+		return entries
+	}
+
+	return append(entries, Entry{
+		Level:  level,
+		Text:   text,
+		Anchor: uniqueAnchor(anchors, text),
+	})
+}
+
+// plainText strips links and emphasis markers from heading text
+func plainText(text string) string {
+	text = linkRegex.ReplaceAllString(text, "$1")
+	return strings.TrimSpace(emphasisReplacer.Replace(text))
+}
+
+func uniqueAnchor(anchors map[string]bool, text string) string {
+	base := slug.Generate(text)
+	if base == "" {
+		base = "section"
+	}
+
+	anchor := base
+	for i := 1; anchors[anchor]; i++ {
+		anchor = base + "-" + strconv.Itoa(i)
+	}
+	anchors[anchor] = true
+
+	return anchor
+}
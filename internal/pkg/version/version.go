@@ -0,0 +1,32 @@
+// Package version holds build metadata injected at link time, e.g.
+//
+//	go build -ldflags "-X github.com/saimonsiddique/blog-api/internal/pkg/version.Version=1.2.0 \
+//		-X github.com/saimonsiddique/blog-api/internal/pkg/version.Commit=$(git rev-parse HEAD) \
+//		-X github.com/saimonsiddique/blog-api/internal/pkg/version.BuildTime=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+package version
+
+import "runtime"
+
+var (
+	Version   = "dev"
+	Commit    = "unknown"
+	BuildTime = "unknown"
+)
+
+// Info represents the build metadata of the running binary
+type Info struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	BuildTime string `json:"buildTime"`
+	GoVersion string `json:"goVersion"`
+}
+
+// Get returns the build metadata of the running binary
+func Get() Info {
+	return Info{
+		Version:   Version,
+		Commit:    Commit,
+		BuildTime: BuildTime,
+		GoVersion: runtime.Version(),
+	}
+}
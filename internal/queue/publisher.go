@@ -4,8 +4,10 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"time"
 
 	"github.com/saimonsiddique/blog-api/internal/domain"
+	"github.com/saimonsiddique/blog-api/internal/pkg/requestid"
 )
 
 type PostPublisher struct {
@@ -19,11 +21,24 @@ func NewPostPublisher(queue *RabbitMQ) *PostPublisher {
 }
 
 func (p *PostPublisher) PublishPostPublishEvent(ctx context.Context, event *domain.PostPublishEvent) error {
-	body, err := json.Marshal(event)
+	payload, err := json.Marshal(event)
 	if err != nil {
 		return fmt.Errorf("failed to marshal event: %w", err)
 	}
 
+	envelope := domain.EventEnvelope{
+		Type:          domain.EventTypePostPublish,
+		Version:       domain.PostPublishEventVersion,
+		OccurredAt:    time.Now(),
+		CorrelationID: requestid.FromContext(ctx),
+		Payload:       payload,
+	}
+
+	body, err := json.Marshal(envelope)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event envelope: %w", err)
+	}
+
 	err = p.queue.Publish(ctx, domain.QueuePostPublish, body)
 	if err != nil {
 		return fmt.Errorf("failed to publish event: %w", err)
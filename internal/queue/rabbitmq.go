@@ -2,7 +2,9 @@ package queue
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
 
 	amqp "github.com/rabbitmq/amqp091-go"
 	"github.com/sirupsen/logrus"
@@ -51,6 +53,16 @@ func NewRabbitMQ(cfg *Config, logger *logrus.Logger) (*RabbitMQ, error) {
 	}, nil
 }
 
+// ServerVersion returns the product and version reported by the broker
+func (r *RabbitMQ) ServerVersion() string {
+	product, _ := r.conn.Properties["product"].(string)
+	version, _ := r.conn.Properties["version"].(string)
+	if product == "" && version == "" {
+		return "unknown"
+	}
+	return strings.TrimSpace(product + " " + version)
+}
+
 func (r *RabbitMQ) Close() error {
 	if r.channel != nil {
 		if err := r.channel.Close(); err != nil {
@@ -81,6 +93,57 @@ func (r *RabbitMQ) DeclareQueue(name string) error {
 	return nil
 }
 
+// DeclareQueueWithDLQ declares a queue whose rejected messages are routed to a dead-letter queue
+func (r *RabbitMQ) DeclareQueueWithDLQ(name, dlqName string) error {
+	if err := r.DeclareQueue(dlqName); err != nil {
+		return err
+	}
+
+	_, err := r.channel.QueueDeclare(
+		name,  // name
+		true,  // durable
+		false, // delete when unused
+		false, // exclusive
+		false, // no-wait
+		amqp.Table{
+			"x-dead-letter-exchange":    "",
+			"x-dead-letter-routing-key": dlqName,
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("failed to declare queue %s: %w", name, err)
+	}
+	r.logger.Infof("Queue '%s' declared with dead-letter queue '%s'", name, dlqName)
+	return nil
+}
+
+// QueueExists reports whether a queue exists. It checks on a channel of its
+// own, since the broker closes the channel a missing queue is looked up on.
+func (r *RabbitMQ) QueueExists(name string) (bool, error) {
+	channel, err := r.conn.Channel()
+	if err != nil {
+		return false, fmt.Errorf("failed to open channel: %w", err)
+	}
+	defer channel.Close()
+
+	_, err = channel.QueueDeclarePassive(
+		name,  // name
+		true,  // durable
+		false, // delete when unused
+		false, // exclusive
+		false, // no-wait
+		nil,   // arguments
+	)
+	if err != nil {
+		var amqpErr *amqp.Error
+		if errors.As(err, &amqpErr) && amqpErr.Code == amqp.NotFound {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to inspect queue %s: %w", name, err)
+	}
+	return true, nil
+}
+
 func (r *RabbitMQ) Publish(ctx context.Context, queueName string, body []byte) error {
 	err := r.channel.PublishWithContext(
 		ctx,
@@ -100,6 +163,103 @@ func (r *RabbitMQ) Publish(ctx context.Context, queueName string, body []byte) e
 	return nil
 }
 
+// DeclareFanout declares an exchange that copies each message to every queue
+// bound to it
+func (r *RabbitMQ) DeclareFanout(name string) error {
+	err := r.channel.ExchangeDeclare(
+		name,     // name
+		"fanout", // kind
+		true,     // durable
+		false,    // delete when unused
+		false,    // internal
+		false,    // no-wait
+		nil,      // arguments
+	)
+	if err != nil {
+		return fmt.Errorf("failed to declare exchange %s: %w", name, err)
+	}
+	r.logger.Infof("Exchange '%s' declared", name)
+	return nil
+}
+
+// PublishFanout publishes a transient message to every queue bound to the
+// exchange
+func (r *RabbitMQ) PublishFanout(ctx context.Context, exchange, contentType string, body []byte) error {
+	err := r.channel.PublishWithContext(
+		ctx,
+		exchange, // exchange
+		"",       // routing key
+		false,    // mandatory
+		false,    // immediate
+		amqp.Publishing{
+			DeliveryMode: amqp.Transient,
+			ContentType:  contentType,
+			Body:         body,
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("failed to publish to exchange %s: %w", exchange, err)
+	}
+	return nil
+}
+
+// SubscribeFanout binds a queue of this connection's own to the exchange and
+// consumes it. The queue is deleted when the connection closes. It is
+// consumed on a channel of its own so the work queue's prefetch limit and
+// channel errors don't apply to it.
+func (r *RabbitMQ) SubscribeFanout(exchange string) (<-chan amqp.Delivery, error) {
+	channel, err := r.conn.Channel()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open channel: %w", err)
+	}
+
+	q, err := channel.QueueDeclare(
+		"",    // name, chosen by the server
+		false, // durable
+		true,  // delete when unused
+		true,  // exclusive
+		false, // no-wait
+		nil,   // arguments
+	)
+	if err != nil {
+		channel.Close()
+		return nil, fmt.Errorf("failed to declare queue for exchange %s: %w", exchange, err)
+	}
+
+	if err := channel.QueueBind(q.Name, "", exchange, false, nil); err != nil {
+		channel.Close()
+		return nil, fmt.Errorf("failed to bind queue to exchange %s: %w", exchange, err)
+	}
+
+	msgs, err := channel.Consume(
+		q.Name, // queue
+		"",     // consumer
+		true,   // auto-ack
+		true,   // exclusive
+		false,  // no-local
+		false,  // no-wait
+		nil,    // args
+	)
+	if err != nil {
+		channel.Close()
+		return nil, fmt.Errorf("failed to register consumer: %w", err)
+	}
+	return msgs, nil
+}
+
+// SetQos limits the number of unacknowledged messages delivered to consumers
+func (r *RabbitMQ) SetQos(prefetchCount int) error {
+	err := r.channel.Qos(
+		prefetchCount, // prefetch count
+		0,             // prefetch size
+		false,         // global
+	)
+	if err != nil {
+		return fmt.Errorf("failed to set QoS: %w", err)
+	}
+	return nil
+}
+
 func (r *RabbitMQ) Consume(queueName string) (<-chan amqp.Delivery, error) {
 	msgs, err := r.channel.Consume(
 		queueName, // queue
@@ -115,3 +275,27 @@ func (r *RabbitMQ) Consume(queueName string) (<-chan amqp.Delivery, error) {
 	}
 	return msgs, nil
 }
+
+// QueueDepth returns the number of messages ready for delivery in the queue.
+// Like QueueExists, it checks on a channel of its own, so a missing queue
+// doesn't close the channel the worker consumes on.
+func (r *RabbitMQ) QueueDepth(queueName string) (int, error) {
+	channel, err := r.conn.Channel()
+	if err != nil {
+		return 0, fmt.Errorf("failed to open channel: %w", err)
+	}
+	defer channel.Close()
+
+	q, err := channel.QueueDeclarePassive(
+		queueName, // name
+		true,      // durable
+		false,     // delete when unused
+		false,     // exclusive
+		false,     // no-wait
+		nil,       // arguments
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to inspect queue %s: %w", queueName, err)
+	}
+	return q.Messages, nil
+}
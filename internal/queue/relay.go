@@ -0,0 +1,99 @@
+package queue
+
+import (
+	"bytes"
+	"context"
+	"encoding/gob"
+
+	"github.com/saimonsiddique/blog-api/internal/events"
+	"github.com/sirupsen/logrus"
+)
+
+const relayContentType = "application/x-gob"
+
+// Relay delivers live notifications to subscribers on every instance. Each
+// instance consumes the fanout exchange and passes what it receives to its
+// in-process broker, so an SSE stream sees notifications produced on any
+// instance. Notifications are gob-encoded so fields kept out of API
+// responses, such as a notification's recipient, survive the trip.
+type Relay[T any] struct {
+	queue    *RabbitMQ
+	exchange string
+	broker   *events.Broker[T]
+	logger   *logrus.Logger
+}
+
+// NewRelay creates a relay over the exchange. Without a queue, notifications
+// only reach broker's local subscribers.
+func NewRelay[T any](queue *RabbitMQ, exchange string, broker *events.Broker[T], logger *logrus.Logger) *Relay[T] {
+	return &Relay[T]{
+		queue:    queue,
+		exchange: exchange,
+		broker:   broker,
+		logger:   logger,
+	}
+}
+
+// Start declares the exchange and passes its messages to the broker until
+// the connection closes
+func (r *Relay[T]) Start() error {
+	if err := r.queue.DeclareFanout(r.exchange); err != nil {
+		return err
+	}
+
+	msgs, err := r.queue.SubscribeFanout(r.exchange)
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		for msg := range msgs {
+			notification, err := decodeRelayed[T](msg.Body)
+			if err != nil {
+				r.logger.WithError(err).WithField("exchange", r.exchange).Error("Failed to decode relayed notification")
+				continue
+			}
+			r.broker.Publish(notification)
+		}
+	}()
+
+	return nil
+}
+
+// Publish sends a notification to subscribers on every instance. If the
+// exchange can't be reached, local subscribers still get it.
+func (r *Relay[T]) Publish(ctx context.Context, notification T) {
+	if r.queue == nil {
+		r.broker.Publish(notification)
+		return
+	}
+
+	body, err := encodeRelayed(notification)
+	if err == nil {
+		err = r.queue.PublishFanout(ctx, r.exchange, relayContentType, body)
+	}
+	if err != nil {
+		r.logger.WithError(err).WithField("exchange", r.exchange).Warn("Failed to relay notification, delivering locally only")
+		r.broker.Publish(notification)
+	}
+}
+
+// Subscribe subscribes to the broker, which receives notifications published
+// on every instance. See events.Broker.Subscribe.
+func (r *Relay[T]) Subscribe(match func(T) bool) (<-chan T, func()) {
+	return r.broker.Subscribe(match)
+}
+
+func encodeRelayed[T any](notification T) ([]byte, error) {
+	var body bytes.Buffer
+	if err := gob.NewEncoder(&body).Encode(notification); err != nil {
+		return nil, err
+	}
+	return body.Bytes(), nil
+}
+
+func decodeRelayed[T any](body []byte) (T, error) {
+	var notification T
+	err := gob.NewDecoder(bytes.NewReader(body)).Decode(&notification)
+	return notification, err
+}
@@ -0,0 +1,75 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/saimonsiddique/blog-api/internal/domain"
+	"github.com/saimonsiddique/blog-api/internal/events"
+	"github.com/sirupsen/logrus"
+)
+
+// The recipient is kept out of API responses but is what inbox streams
+// match on, so it must survive the trip between instances
+func TestRelayedNotificationKeepsRecipient(t *testing.T) {
+	readAt := time.Now().UTC().Truncate(time.Second)
+	sent := domain.Notification{
+		ID:        7,
+		UUID:      uuid.New(),
+		UserID:    42,
+		Type:      domain.NotificationPostPublished,
+		Payload:   json.RawMessage(`{"title":"Hello"}`),
+		ReadAt:    &readAt,
+		CreatedAt: readAt,
+	}
+
+	body, err := encodeRelayed(sent)
+	if err != nil {
+		t.Fatalf("encodeRelayed() error = %v", err)
+	}
+	got, err := decodeRelayed[domain.Notification](body)
+	if err != nil {
+		t.Fatalf("decodeRelayed() error = %v", err)
+	}
+
+	if got.UserID != sent.UserID || got.ID != sent.ID || got.UUID != sent.UUID || got.Type != sent.Type {
+		t.Errorf("decoded %+v, want %+v", got, sent)
+	}
+	if string(got.Payload) != string(sent.Payload) {
+		t.Errorf("payload = %s, want %s", got.Payload, sent.Payload)
+	}
+	if got.ReadAt == nil || !got.ReadAt.Equal(readAt) || !got.CreatedAt.Equal(readAt) {
+		t.Errorf("timestamps = (%v, %v), want %v", got.ReadAt, got.CreatedAt, readAt)
+	}
+}
+
+func TestDecodeRelayedRejectsGarbage(t *testing.T) {
+	if _, err := decodeRelayed[domain.PostPublishedNotification]([]byte(`{"postId":"x"}`)); err == nil {
+		t.Error("decodeRelayed() error = nil, want an error")
+	}
+}
+
+func TestRelayWithoutQueueDeliversLocally(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	relay := NewRelay(nil, domain.ExchangePostPublished, events.NewBroker[domain.PostPublishedNotification](), logger)
+
+	received, unsubscribe := relay.Subscribe(nil)
+	defer unsubscribe()
+
+	sent := domain.PostPublishedNotification{PostUUID: uuid.New(), Title: "Hello"}
+	relay.Publish(context.Background(), sent)
+
+	select {
+	case got := <-received:
+		if got != sent {
+			t.Errorf("received %+v, want %+v", got, sent)
+		}
+	default:
+		t.Error("notification was not delivered to the local subscriber")
+	}
+}
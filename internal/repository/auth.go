@@ -7,6 +7,7 @@ import (
 	"errors"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/saimonsiddique/blog-api/internal/domain"
@@ -20,18 +21,116 @@ func NewAuthRepository(db *pgxpool.Pool) *AuthRepository {
 	return &AuthRepository{db: db}
 }
 
-func (r *AuthRepository) StoreRefreshToken(ctx context.Context, userID int, token string, expiresAt time.Time) error {
+// StoreRefreshToken stores a new session's refresh token and records it as
+// the user's last login, in one statement so logging in costs no extra round
+// trip
+func (r *AuthRepository) StoreRefreshToken(ctx context.Context, userID int, token string, expiresAt time.Time, client domain.ClientInfo) error {
 	tokenHash := hashToken(token)
 
 	query := `
-		INSERT INTO refresh_tokens (user_id, token_hash, expires_at)
-		VALUES ($1, $2, $3)
+		WITH login AS (
+			UPDATE users
+			SET last_login_at = CURRENT_TIMESTAMP, last_login_ip = NULLIF($5, '')
+			WHERE id = $1
+		)
+		INSERT INTO refresh_tokens (user_id, token_hash, expires_at, user_agent, ip_address)
+		VALUES ($1, $2, $3, NULLIF($4, ''), NULLIF($5, ''))
 	`
 
-	_, err := r.db.Exec(ctx, query, userID, tokenHash, expiresAt)
+	_, err := r.db.Exec(ctx, query, userID, tokenHash, expiresAt, client.UserAgent, client.IPAddress)
 	return err
 }
 
+// RememberDevice records that a user logged in from the device identified by
+// deviceHash. It reports whether the device is new for a user who already
+// had known devices, so the first device recorded for a user isn't treated
+// as new.
+func (r *AuthRepository) RememberDevice(ctx context.Context, userID int, deviceHash string) (bool, error) {
+	query := `
+		WITH known AS (
+			SELECT EXISTS(SELECT 1 FROM known_devices WHERE user_id = $1) AS any
+		)
+		INSERT INTO known_devices (user_id, device_hash)
+		VALUES ($1, $2)
+		ON CONFLICT (user_id, device_hash) DO UPDATE SET last_seen_at = CURRENT_TIMESTAMP
+		RETURNING xmax = 0, (SELECT any FROM known)
+	`
+
+	var inserted, hadDevices bool
+	if err := r.db.QueryRow(ctx, query, userID, deviceHash).Scan(&inserted, &hadDevices); err != nil {
+		return false, err
+	}
+
+	return inserted && hadDevices, nil
+}
+
+// ListSessions returns unexpired sessions, newest first, optionally for a
+// single user
+func (r *AuthRepository) ListSessions(ctx context.Context, req domain.ListSessionsRequest) ([]domain.Session, int, error) {
+	where := ` WHERE rt.expires_at > NOW() AND ($1::uuid IS NULL OR u.uuid = $1)`
+
+	var totalCount int
+	countQuery := `SELECT COUNT(*) FROM refresh_tokens rt INNER JOIN users u ON rt.user_id = u.id` + where
+	if err := r.db.QueryRow(ctx, countQuery, req.UserID).Scan(&totalCount); err != nil {
+		return nil, 0, err
+	}
+
+	query := `
+		SELECT rt.uuid, u.uuid, u.username, rt.user_agent, rt.ip_address, rt.created_at, rt.expires_at
+		FROM refresh_tokens rt
+		INNER JOIN users u ON rt.user_id = u.id
+	` + where + `
+		ORDER BY rt.created_at DESC
+		LIMIT $2 OFFSET $3
+	`
+
+	rows, err := r.db.Query(ctx, query, req.UserID, req.Limit, (req.Page-1)*req.Limit)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	sessions := []domain.Session{}
+	for rows.Next() {
+		var session domain.Session
+		err := rows.Scan(
+			&session.UUID,
+			&session.User.UUID,
+			&session.User.Username,
+			&session.UserAgent,
+			&session.IPAddress,
+			&session.CreatedAt,
+			&session.ExpiresAt,
+		)
+		if err != nil {
+			return nil, 0, err
+		}
+		sessions = append(sessions, session)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	return sessions, totalCount, nil
+}
+
+// DeleteSession revokes a session by its UUID
+func (r *AuthRepository) DeleteSession(ctx context.Context, sessionUUID uuid.UUID) error {
+	query := `DELETE FROM refresh_tokens WHERE uuid = $1`
+
+	result, err := r.db.Exec(ctx, query, sessionUUID)
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return domain.ErrSessionNotFound
+	}
+
+	return nil
+}
+
 func (r *AuthRepository) GetRefreshToken(ctx context.Context, token string) (*domain.RefreshToken, error) {
 	tokenHash := hashToken(token)
 
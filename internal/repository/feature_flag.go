@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/saimonsiddique/blog-api/internal/domain"
+)
+
+type FeatureFlagRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewFeatureFlagRepository(db *pgxpool.Pool) *FeatureFlagRepository {
+	return &FeatureFlagRepository{db: db}
+}
+
+// List returns every flag that has been set
+func (r *FeatureFlagRepository) List(ctx context.Context) ([]domain.FeatureFlag, error) {
+	query := `SELECT key, enabled, updated_at FROM feature_flags ORDER BY key`
+
+	rows, err := r.db.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	flags := []domain.FeatureFlag{}
+	for rows.Next() {
+		var flag domain.FeatureFlag
+		if err := rows.Scan(&flag.Key, &flag.Enabled, &flag.UpdatedAt); err != nil {
+			return nil, err
+		}
+		flags = append(flags, flag)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return flags, nil
+}
+
+// Set stores a flag's value, creating it if needed
+func (r *FeatureFlagRepository) Set(ctx context.Context, key string, enabled bool) (*domain.FeatureFlag, error) {
+	query := `
+		INSERT INTO feature_flags (key, enabled)
+		VALUES ($1, $2)
+		ON CONFLICT (key) DO UPDATE SET enabled = EXCLUDED.enabled, updated_at = CURRENT_TIMESTAMP
+		RETURNING key, enabled, updated_at
+	`
+
+	var flag domain.FeatureFlag
+	err := r.db.QueryRow(ctx, query, key, enabled).Scan(&flag.Key, &flag.Enabled, &flag.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return &flag, nil
+}
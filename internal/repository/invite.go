@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/saimonsiddique/blog-api/internal/domain"
+)
+
+type InviteRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewInviteRepository(db *pgxpool.Pool) *InviteRepository {
+	return &InviteRepository{db: db}
+}
+
+// Create stores a new invite created by the given user. emailNormalized
+// pins the invite to an address when not nil.
+func (r *InviteRepository) Create(ctx context.Context, invite *domain.Invite, emailNormalized *string, createdBy int) error {
+	query := `
+		INSERT INTO invites (code, email, created_by, expires_at)
+		VALUES ($1, $2, $3, $4)
+		RETURNING created_at
+	`
+
+	return r.db.QueryRow(ctx, query, invite.Code, emailNormalized, createdBy, invite.ExpiresAt).Scan(&invite.CreatedAt)
+}
+
+// Claim marks an unused, unexpired invite as used and returns its ID, so two
+// registrations can't share one code. The invite must not be pinned to an
+// address other than emailNormalized. It returns ErrInviteUsed or
+// ErrInviteExpired when the invite can't be claimed for those reasons, and
+// ErrInvalidInvite otherwise.
+func (r *InviteRepository) Claim(ctx context.Context, code, emailNormalized string) (int, error) {
+	query := `
+		UPDATE invites
+		SET used_at = CURRENT_TIMESTAMP
+		WHERE code = $1
+			AND used_at IS NULL
+			AND (expires_at IS NULL OR expires_at > CURRENT_TIMESTAMP)
+			AND (email IS NULL OR email = $2)
+		RETURNING id
+	`
+
+	var id int
+	err := r.db.QueryRow(ctx, query, code, emailNormalized).Scan(&id)
+	if err == nil {
+		return id, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return 0, err
+	}
+
+	// Work out why the invite couldn't be claimed
+	var used, expired bool
+	reasonQuery := `
+		SELECT used_at IS NOT NULL, expires_at IS NOT NULL AND expires_at <= CURRENT_TIMESTAMP
+		FROM invites
+		WHERE code = $1
+	`
+	err = r.db.QueryRow(ctx, reasonQuery, code).Scan(&used, &expired)
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		return 0, domain.ErrInvalidInvite
+	case err != nil:
+		return 0, err
+	case used:
+		return 0, domain.ErrInviteUsed
+	case expired:
+		return 0, domain.ErrInviteExpired
+	default:
+		// Pinned to another address
+		return 0, domain.ErrInvalidInvite
+	}
+}
+
+// Release makes a claimed invite usable again, for when registration fails
+// after the claim
+func (r *InviteRepository) Release(ctx context.Context, id int) error {
+	query := `UPDATE invites SET used_at = NULL WHERE id = $1 AND used_by IS NULL`
+
+	_, err := r.db.Exec(ctx, query, id)
+	return err
+}
+
+// SetUsedBy records the user who registered with a claimed invite
+func (r *InviteRepository) SetUsedBy(ctx context.Context, id, userID int) error {
+	query := `UPDATE invites SET used_by = $2 WHERE id = $1`
+
+	_, err := r.db.Exec(ctx, query, id, userID)
+	return err
+}
@@ -0,0 +1,192 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/saimonsiddique/blog-api/internal/domain"
+)
+
+type NotificationRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewNotificationRepository(db *pgxpool.Pool) *NotificationRepository {
+	return &NotificationRepository{db: db}
+}
+
+// List returns a user's notifications, newest first, optionally only unread ones
+func (r *NotificationRepository) List(ctx context.Context, userID int, req domain.ListNotificationsRequest) ([]domain.Notification, int, error) {
+	where := ` WHERE user_id = $1 AND (NOT $2 OR read_at IS NULL)`
+
+	var totalCount int
+	countQuery := `SELECT COUNT(*) FROM notifications` + where
+	if err := r.db.QueryRow(ctx, countQuery, userID, req.Unread).Scan(&totalCount); err != nil {
+		return nil, 0, err
+	}
+
+	query := `
+		SELECT id, uuid, user_id, type, payload, read_at, created_at
+		FROM notifications
+	` + where + `
+		ORDER BY created_at DESC, id DESC
+		LIMIT $3 OFFSET $4
+	`
+
+	rows, err := r.db.Query(ctx, query, userID, req.Unread, req.Limit, (req.Page-1)*req.Limit)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	notifications := []domain.Notification{}
+	for rows.Next() {
+		var notification domain.Notification
+		err := rows.Scan(
+			&notification.ID,
+			&notification.UUID,
+			&notification.UserID,
+			&notification.Type,
+			&notification.Payload,
+			&notification.ReadAt,
+			&notification.CreatedAt,
+		)
+		if err != nil {
+			return nil, 0, err
+		}
+		notifications = append(notifications, notification)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	return notifications, totalCount, nil
+}
+
+// ListAfter returns up to limit of a user's notifications created after the
+// given one, oldest first. Nothing is returned when the given notification
+// doesn't exist or belongs to another user.
+func (r *NotificationRepository) ListAfter(ctx context.Context, userID int, afterUUID uuid.UUID, limit int) ([]domain.Notification, error) {
+	query := `
+		SELECT n.id, n.uuid, n.user_id, n.type, n.payload, n.read_at, n.created_at
+		FROM notifications n
+		INNER JOIN notifications after ON after.uuid = $2 AND after.user_id = $1
+		WHERE n.user_id = $1 AND (n.created_at, n.id) > (after.created_at, after.id)
+		ORDER BY n.created_at, n.id
+		LIMIT $3
+	`
+
+	rows, err := r.db.Query(ctx, query, userID, afterUUID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	notifications := []domain.Notification{}
+	for rows.Next() {
+		var notification domain.Notification
+		err := rows.Scan(
+			&notification.ID,
+			&notification.UUID,
+			&notification.UserID,
+			&notification.Type,
+			&notification.Payload,
+			&notification.ReadAt,
+			&notification.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		notifications = append(notifications, notification)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return notifications, nil
+}
+
+// Create adds a notification to a user's inbox
+func (r *NotificationRepository) Create(ctx context.Context, userID int, notificationType domain.NotificationType, payload []byte) (*domain.Notification, error) {
+	query := `
+		INSERT INTO notifications (user_id, type, payload)
+		VALUES ($1, $2, $3)
+		RETURNING id, uuid, user_id, type, payload, read_at, created_at
+	`
+
+	var notification domain.Notification
+	err := r.db.QueryRow(ctx, query, userID, notificationType, payload).Scan(
+		&notification.ID,
+		&notification.UUID,
+		&notification.UserID,
+		&notification.Type,
+		&notification.Payload,
+		&notification.ReadAt,
+		&notification.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &notification, nil
+}
+
+// UnreadCount returns how many unread notifications a user has
+func (r *NotificationRepository) UnreadCount(ctx context.Context, userID int) (int, error) {
+	query := `SELECT COUNT(*) FROM notifications WHERE user_id = $1 AND read_at IS NULL`
+
+	var count int
+	if err := r.db.QueryRow(ctx, query, userID).Scan(&count); err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// MarkRead marks one of a user's notifications as read. Marking a read
+// notification again keeps its original read time.
+func (r *NotificationRepository) MarkRead(ctx context.Context, userID int, notificationUUID uuid.UUID) (*domain.Notification, error) {
+	query := `
+		UPDATE notifications
+		SET read_at = COALESCE(read_at, CURRENT_TIMESTAMP)
+		WHERE uuid = $1 AND user_id = $2
+		RETURNING id, uuid, user_id, type, payload, read_at, created_at
+	`
+
+	var notification domain.Notification
+	err := r.db.QueryRow(ctx, query, notificationUUID, userID).Scan(
+		&notification.ID,
+		&notification.UUID,
+		&notification.UserID,
+		&notification.Type,
+		&notification.Payload,
+		&notification.ReadAt,
+		&notification.CreatedAt,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrNotificationNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &notification, nil
+}
+
+// MarkAllRead marks every unread notification of a user as read, returning
+// how many were updated
+func (r *NotificationRepository) MarkAllRead(ctx context.Context, userID int) (int, error) {
+	query := `UPDATE notifications SET read_at = CURRENT_TIMESTAMP WHERE user_id = $1 AND read_at IS NULL`
+
+	result, err := r.db.Exec(ctx, query, userID)
+	if err != nil {
+		return 0, err
+	}
+
+	return int(result.RowsAffected()), nil
+}
@@ -3,26 +3,39 @@ package repository
 import (
 	"context"
 	"errors"
+	"sort"
+	"strconv"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/saimonsiddique/blog-api/internal/database"
 	"github.com/saimonsiddique/blog-api/internal/domain"
 )
 
+// placeholder returns the query parameter placeholder for the nth argument
+func placeholder(n int) string {
+	return "$" + strconv.Itoa(n)
+}
+
 type PostRepository struct {
-	db *pgxpool.Pool
+	db      *pgxpool.Pool
+	replica *pgxpool.Pool
 }
 
-func NewPostRepository(db *pgxpool.Pool) *PostRepository {
-	return &PostRepository{db: db}
+// NewPostRepository creates a post repository. replica may be nil, in which
+// case reads are served by the primary.
+func NewPostRepository(db *pgxpool.Pool, replica *pgxpool.Pool) *PostRepository {
+	return &PostRepository{db: db, replica: replica}
 }
 
 // Create creates a new post
 func (r *PostRepository) Create(ctx context.Context, post *domain.Post) error {
 	query := `
-		INSERT INTO posts (author_id, title, slug, content, excerpt, status, published_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		INSERT INTO posts (author_id, title, slug, content, excerpt, format, visibility, status, published_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
 		RETURNING id, uuid, created_at, updated_at
 	`
 
@@ -34,25 +47,62 @@ func (r *PostRepository) Create(ctx context.Context, post *domain.Post) error {
 		post.Slug,
 		post.Content,
 		post.Excerpt,
+		post.Format,
+		post.Visibility,
 		post.Status,
 		post.PublishedAt,
 	).Scan(&post.ID, &post.UUID, &post.CreatedAt, &post.UpdatedAt)
 
 	if err != nil {
-		if err.Error() == `ERROR: duplicate key value violates unique constraint "posts_slug_key" (SQLSTATE 23505)` {
+		return writePostError(err)
+	}
+
+	return nil
+}
+
+// writePostError maps unique violations on insert or update to domain errors
+func writePostError(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		switch pgErr.ConstraintName {
+		case "posts_slug_key":
 			return domain.ErrSlugTaken
+		default:
+			return domain.ErrConflict
 		}
-		return err
 	}
+	return err
+}
 
-	return nil
+// FindRecentDuplicate returns the UUID of the author's most recent post
+// created since since with the same title and content, or uuid.Nil if there
+// is none
+func (r *PostRepository) FindRecentDuplicate(ctx context.Context, authorID int, title, content string, since time.Time) (uuid.UUID, error) {
+	query := `
+		SELECT uuid
+		FROM posts
+		WHERE author_id = $1 AND content_hash = md5($2 || E'\n' || $3) AND created_at >= $4
+		ORDER BY created_at DESC
+		LIMIT 1
+	`
+
+	var postUUID uuid.UUID
+	err := r.db.QueryRow(ctx, query, authorID, title, content, since).Scan(&postUUID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return uuid.Nil, nil
+		}
+		return uuid.Nil, err
+	}
+
+	return postUUID, nil
 }
 
 // GetByUUID retrieves a post by UUID with author information
 func (r *PostRepository) GetByUUID(ctx context.Context, postUUID uuid.UUID) (*domain.PostWithAuthor, error) {
 	query := `
 		SELECT
-			p.id, p.uuid, p.author_id, p.title, p.slug, p.content, p.excerpt,
+			p.id, p.uuid, p.author_id, p.title, p.slug, p.content, p.excerpt, p.format, p.visibility,
 			p.status, p.published_at, p.created_at, p.updated_at,
 			u.uuid, u.username
 		FROM posts p
@@ -61,7 +111,7 @@ func (r *PostRepository) GetByUUID(ctx context.Context, postUUID uuid.UUID) (*do
 	`
 
 	var post domain.PostWithAuthor
-	err := r.db.QueryRow(ctx, query, postUUID).Scan(
+	err := database.ReadPool(ctx, r.db, r.replica).QueryRow(ctx, query, postUUID).Scan(
 		&post.ID,
 		&post.UUID,
 		&post.AuthorID,
@@ -69,6 +119,8 @@ func (r *PostRepository) GetByUUID(ctx context.Context, postUUID uuid.UUID) (*do
 		&post.Slug,
 		&post.Content,
 		&post.Excerpt,
+		&post.Format,
+		&post.Visibility,
 		&post.Status,
 		&post.PublishedAt,
 		&post.CreatedAt,
@@ -91,7 +143,7 @@ func (r *PostRepository) GetByUUID(ctx context.Context, postUUID uuid.UUID) (*do
 func (r *PostRepository) GetBySlug(ctx context.Context, slug string) (*domain.PostWithAuthor, error) {
 	query := `
 		SELECT
-			p.id, p.uuid, p.author_id, p.title, p.slug, p.content, p.excerpt,
+			p.id, p.uuid, p.author_id, p.title, p.slug, p.content, p.excerpt, p.format, p.visibility,
 			p.status, p.published_at, p.created_at, p.updated_at,
 			u.uuid, u.username
 		FROM posts p
@@ -100,7 +152,7 @@ func (r *PostRepository) GetBySlug(ctx context.Context, slug string) (*domain.Po
 	`
 
 	var post domain.PostWithAuthor
-	err := r.db.QueryRow(ctx, query, slug).Scan(
+	err := database.ReadPool(ctx, r.db, r.replica).QueryRow(ctx, query, slug).Scan(
 		&post.ID,
 		&post.UUID,
 		&post.AuthorID,
@@ -108,6 +160,8 @@ func (r *PostRepository) GetBySlug(ctx context.Context, slug string) (*domain.Po
 		&post.Slug,
 		&post.Content,
 		&post.Excerpt,
+		&post.Format,
+		&post.Visibility,
 		&post.Status,
 		&post.PublishedAt,
 		&post.CreatedAt,
@@ -126,50 +180,50 @@ func (r *PostRepository) GetBySlug(ctx context.Context, slug string) (*domain.Po
 	return &post, nil
 }
 
-// List retrieves posts with filters and pagination
+// List retrieves public posts with filters and pagination. Unlisted and
+// private posts are never listed.
 func (r *PostRepository) List(ctx context.Context, req domain.ListPostsRequest) ([]domain.PostWithAuthor, int, error) {
 	// Build query with filters
 	query := `
 		SELECT
-			p.id, p.uuid, p.author_id, p.title, p.slug, p.content, p.excerpt,
+			p.id, p.uuid, p.author_id, p.title, p.slug, p.content, p.excerpt, p.format, p.visibility,
 			p.status, p.published_at, p.created_at, p.updated_at,
 			u.uuid, u.username
 		FROM posts p
 		INNER JOIN users u ON p.author_id = u.id
-		WHERE 1=1
+		WHERE p.visibility = 'public'
 	`
-	countQuery := `SELECT COUNT(*) FROM posts p INNER JOIN users u ON p.author_id = u.id WHERE 1=1`
+	countQuery := `SELECT COUNT(*) FROM posts p INNER JOIN users u ON p.author_id = u.id WHERE p.visibility = 'public'`
 	args := []interface{}{}
+	db := database.ReadPool(ctx, r.db, r.replica)
 	argIndex := 1
 
 	// Add filters
 	if req.Status != nil {
-		query += ` AND p.status = $` + string(rune(argIndex+'0'))
-		countQuery += ` AND p.status = $` + string(rune(argIndex+'0'))
+		query += ` AND p.status = ` + placeholder(argIndex)
+		countQuery += ` AND p.status = ` + placeholder(argIndex)
 		args = append(args, *req.Status)
 		argIndex++
 	}
 
 	if req.AuthorID != nil {
-		// Get user ID from UUID
-		var authorID int
-		err := r.db.QueryRow(ctx, `SELECT id FROM users WHERE uuid = $1`, *req.AuthorID).Scan(&authorID)
-		if err != nil {
-			if errors.Is(err, pgx.ErrNoRows) {
-				return []domain.PostWithAuthor{}, 0, nil
-			}
-			return nil, 0, err
-		}
+		query += ` AND u.uuid = ` + placeholder(argIndex)
+		countQuery += ` AND u.uuid = ` + placeholder(argIndex)
+		args = append(args, *req.AuthorID)
+		argIndex++
+	}
 
-		query += ` AND p.author_id = $` + string(rune(argIndex+'0'))
-		countQuery += ` AND p.author_id = $` + string(rune(argIndex+'0'))
-		args = append(args, authorID)
+	if req.UnpublishedAuthor != nil {
+		filter := ` AND (p.status = 'published' OR u.uuid = ` + placeholder(argIndex) + `)`
+		query += filter
+		countQuery += filter
+		args = append(args, *req.UnpublishedAuthor)
 		argIndex++
 	}
 
 	// Get total count
 	var totalCount int
-	err := r.db.QueryRow(ctx, countQuery, args...).Scan(&totalCount)
+	err := db.QueryRow(ctx, countQuery, args...).Scan(&totalCount)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -178,18 +232,18 @@ func (r *PostRepository) List(ctx context.Context, req domain.ListPostsRequest)
 	query += ` ORDER BY p.created_at DESC`
 
 	if req.Limit > 0 {
-		query += ` LIMIT $` + string(rune(argIndex+'0'))
+		query += ` LIMIT ` + placeholder(argIndex)
 		args = append(args, req.Limit)
 		argIndex++
 	}
 
 	if req.Page > 1 && req.Limit > 0 {
 		offset := (req.Page - 1) * req.Limit
-		query += ` OFFSET $` + string(rune(argIndex+'0'))
+		query += ` OFFSET ` + placeholder(argIndex)
 		args = append(args, offset)
 	}
 
-	rows, err := r.db.Query(ctx, query, args...)
+	rows, err := db.Query(ctx, query, args...)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -206,6 +260,8 @@ func (r *PostRepository) List(ctx context.Context, req domain.ListPostsRequest)
 			&post.Slug,
 			&post.Content,
 			&post.Excerpt,
+			&post.Format,
+			&post.Visibility,
 			&post.Status,
 			&post.PublishedAt,
 			&post.CreatedAt,
@@ -232,23 +288,7 @@ func (r *PostRepository) List(ctx context.Context, req domain.ListPostsRequest)
 
 // Update updates a post
 func (r *PostRepository) Update(ctx context.Context, postUUID uuid.UUID, updates map[string]interface{}) (*domain.Post, error) {
-	// Build dynamic update query
-	query := `UPDATE posts SET `
-	args := []interface{}{}
-	argIndex := 1
-
-	for field, value := range updates {
-		if argIndex > 1 {
-			query += `, `
-		}
-		query += field + ` = $` + string(rune(argIndex+'0'))
-		args = append(args, value)
-		argIndex++
-	}
-
-	query += `, updated_at = CURRENT_TIMESTAMP WHERE uuid = $` + string(rune(argIndex+'0'))
-	args = append(args, postUUID)
-	query += ` RETURNING id, uuid, author_id, title, slug, content, excerpt, status, published_at, created_at, updated_at`
+	query, args := updatePostQuery(postUUID, updates)
 
 	var post domain.Post
 	err := r.db.QueryRow(ctx, query, args...).Scan(
@@ -259,6 +299,8 @@ func (r *PostRepository) Update(ctx context.Context, postUUID uuid.UUID, updates
 		&post.Slug,
 		&post.Content,
 		&post.Excerpt,
+		&post.Format,
+		&post.Visibility,
 		&post.Status,
 		&post.PublishedAt,
 		&post.CreatedAt,
@@ -269,15 +311,55 @@ func (r *PostRepository) Update(ctx context.Context, postUUID uuid.UUID, updates
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, domain.ErrPostNotFound
 		}
-		if err.Error() == `ERROR: duplicate key value violates unique constraint "posts_slug_key" (SQLSTATE 23505)` {
-			return nil, domain.ErrSlugTaken
-		}
-		return nil, err
+		return nil, writePostError(err)
 	}
 
 	return &post, nil
 }
 
+// updatePostQuery builds the statement setting the given columns of a post.
+// Columns are set in name order so the statement is the same for the same
+// set of updates.
+func updatePostQuery(postUUID uuid.UUID, updates map[string]interface{}) (string, []interface{}) {
+	fields := make([]string, 0, len(updates))
+	for field := range updates {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	query := `UPDATE posts SET `
+	args := []interface{}{}
+	argIndex := 1
+
+	for _, field := range fields {
+		query += field + ` = ` + placeholder(argIndex) + `, `
+		args = append(args, updates[field])
+		argIndex++
+	}
+
+	query += `updated_at = CURRENT_TIMESTAMP WHERE uuid = ` + placeholder(argIndex)
+	args = append(args, postUUID)
+	query += ` RETURNING id, uuid, author_id, title, slug, content, excerpt, format, visibility, status, published_at, created_at, updated_at`
+
+	return query, args
+}
+
+// TransferOwnership makes authorID the author of a post
+func (r *PostRepository) TransferOwnership(ctx context.Context, postUUID uuid.UUID, authorID int) error {
+	query := `UPDATE posts SET author_id = $2, updated_at = CURRENT_TIMESTAMP WHERE uuid = $1`
+
+	result, err := r.db.Exec(ctx, query, postUUID, authorID)
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return domain.ErrPostNotFound
+	}
+
+	return nil
+}
+
 // Delete deletes a post
 func (r *PostRepository) Delete(ctx context.Context, postUUID uuid.UUID) error {
 	query := `DELETE FROM posts WHERE uuid = $1`
@@ -294,15 +376,516 @@ func (r *PostRepository) Delete(ctx context.Context, postUUID uuid.UUID) error {
 	return nil
 }
 
-// IsAuthor checks if a user is the author of a post
-func (r *PostRepository) IsAuthor(ctx context.Context, postUUID uuid.UUID, userID int) (bool, error) {
-	query := `SELECT EXISTS(SELECT 1 FROM posts WHERE uuid = $1 AND author_id = $2)`
+// ListEditable returns the posts a user can edit, most recently updated
+// first: their own posts, or every post when allPosts is set
+func (r *PostRepository) ListEditable(ctx context.Context, userID int, allPosts bool, req domain.ListEditablePostsRequest) ([]domain.PostWithAuthor, int, error) {
+	where := ` WHERE ($1 OR p.author_id = $2) AND ($3::text IS NULL OR p.status = $3)`
+	args := []interface{}{allPosts, userID, req.Status}
+
+	var totalCount int
+	countQuery := `SELECT COUNT(*) FROM posts p` + where
+	if err := r.db.QueryRow(ctx, countQuery, args...).Scan(&totalCount); err != nil {
+		return nil, 0, err
+	}
 
-	var exists bool
-	err := r.db.QueryRow(ctx, query, postUUID, userID).Scan(&exists)
+	query := `
+		SELECT
+			p.id, p.uuid, p.author_id, p.title, p.slug, p.content, p.excerpt, p.format, p.visibility,
+			p.status, p.published_at, p.created_at, p.updated_at,
+			u.uuid, u.username
+		FROM posts p
+		INNER JOIN users u ON p.author_id = u.id
+	` + where + `
+		ORDER BY p.updated_at DESC
+		LIMIT $4 OFFSET $5
+	`
+	args = append(args, req.Limit, (req.Page-1)*req.Limit)
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	posts := []domain.PostWithAuthor{}
+	for rows.Next() {
+		var post domain.PostWithAuthor
+		err := rows.Scan(
+			&post.ID,
+			&post.UUID,
+			&post.AuthorID,
+			&post.Title,
+			&post.Slug,
+			&post.Content,
+			&post.Excerpt,
+			&post.Format,
+			&post.Visibility,
+			&post.Status,
+			&post.PublishedAt,
+			&post.CreatedAt,
+			&post.UpdatedAt,
+			&post.Author.UUID,
+			&post.Author.Username,
+		)
+		if err != nil {
+			return nil, 0, err
+		}
+		posts = append(posts, post)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	return posts, totalCount, nil
+}
+
+// ListStaleDrafts returns drafts not updated since before, oldest first
+func (r *PostRepository) ListStaleDrafts(ctx context.Context, before time.Time, limit int) ([]domain.Post, error) {
+	query := `
+		SELECT id, uuid, author_id, title, slug, content, excerpt, format, visibility, status, published_at, created_at, updated_at
+		FROM posts
+		WHERE status = 'draft' AND updated_at < $1
+		ORDER BY updated_at ASC
+		LIMIT $2
+	`
+
+	rows, err := r.db.Query(ctx, query, before, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	posts := []domain.Post{}
+	for rows.Next() {
+		var post domain.Post
+		err := rows.Scan(
+			&post.ID,
+			&post.UUID,
+			&post.AuthorID,
+			&post.Title,
+			&post.Slug,
+			&post.Content,
+			&post.Excerpt,
+			&post.Format,
+			&post.Visibility,
+			&post.Status,
+			&post.PublishedAt,
+			&post.CreatedAt,
+			&post.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		posts = append(posts, post)
+	}
+
+	return posts, rows.Err()
+}
+
+// ArchiveStaleDraft archives a draft if it is still a draft and hasn't been
+// updated since before. It reports whether the post was archived, so a draft
+// edited after it was listed is left alone.
+func (r *PostRepository) ArchiveStaleDraft(ctx context.Context, postID int, before time.Time) (bool, error) {
+	query := `
+		UPDATE posts
+		SET status = 'archived', updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND status = 'draft' AND updated_at < $2
+	`
+
+	result, err := r.db.Exec(ctx, query, postID, before)
 	if err != nil {
 		return false, err
 	}
 
-	return exists, nil
+	return result.RowsAffected() > 0, nil
+}
+
+// MarkRead records that a user has read a post, refreshing read_at on repeat
+func (r *PostRepository) MarkRead(ctx context.Context, userID, postID int) (time.Time, error) {
+	query := `
+		INSERT INTO read_posts (user_id, post_id)
+		VALUES ($1, $2)
+		ON CONFLICT (user_id, post_id) DO UPDATE SET read_at = CURRENT_TIMESTAMP
+		RETURNING read_at
+	`
+
+	var readAt time.Time
+	err := r.db.QueryRow(ctx, query, userID, postID).Scan(&readAt)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return readAt, nil
+}
+
+// SaveReadProgress stores a user's reading progress for a post, replacing any
+// previous value
+func (r *PostRepository) SaveReadProgress(ctx context.Context, userID, postID, percent int) (time.Time, error) {
+	query := `
+		INSERT INTO read_progress (user_id, post_id, percent)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (user_id, post_id) DO UPDATE
+		SET percent = EXCLUDED.percent, updated_at = CURRENT_TIMESTAMP
+		RETURNING updated_at
+	`
+
+	var updatedAt time.Time
+	err := r.db.QueryRow(ctx, query, userID, postID, percent).Scan(&updatedAt)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return updatedAt, nil
+}
+
+// ReadPostIDs returns which of the given posts have been read by the user
+func (r *PostRepository) ReadPostIDs(ctx context.Context, userUUID uuid.UUID, postIDs []int) (map[int]bool, error) {
+	query := `
+		SELECT rp.post_id
+		FROM read_posts rp
+		INNER JOIN users u ON rp.user_id = u.id
+		WHERE u.uuid = $1 AND rp.post_id = ANY($2)
+	`
+
+	rows, err := r.db.Query(ctx, query, userUUID, postIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	read := make(map[int]bool)
+	for rows.Next() {
+		var postID int
+		if err := rows.Scan(&postID); err != nil {
+			return nil, err
+		}
+		read[postID] = true
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return read, nil
+}
+
+// ListReadHistory retrieves the published posts a user has read, most recent first
+func (r *PostRepository) ListReadHistory(ctx context.Context, userID int, req domain.ReadHistoryRequest) ([]domain.ReadPost, int, error) {
+	countQuery := `
+		SELECT COUNT(*)
+		FROM read_posts rp
+		INNER JOIN posts p ON rp.post_id = p.id
+		WHERE rp.user_id = $1 AND p.status = 'published' AND (p.visibility <> 'private' OR p.author_id = $1)
+	`
+
+	var totalCount int
+	err := r.db.QueryRow(ctx, countQuery, userID).Scan(&totalCount)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	query := `
+		SELECT
+			p.id, p.uuid, p.author_id, p.title, p.slug, p.content, p.excerpt, p.format, p.visibility,
+			p.status, p.published_at, p.created_at, p.updated_at,
+			u.uuid, u.username, rp.read_at, pr.percent
+		FROM read_posts rp
+		INNER JOIN posts p ON rp.post_id = p.id
+		INNER JOIN users u ON p.author_id = u.id
+		LEFT JOIN read_progress pr ON pr.user_id = rp.user_id AND pr.post_id = rp.post_id
+		WHERE rp.user_id = $1 AND p.status = 'published' AND (p.visibility <> 'private' OR p.author_id = $1)
+		ORDER BY rp.read_at DESC
+		LIMIT $2 OFFSET $3
+	`
+
+	rows, err := r.db.Query(ctx, query, userID, req.Limit, (req.Page-1)*req.Limit)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	posts := []domain.ReadPost{}
+	for rows.Next() {
+		var post domain.ReadPost
+		err := rows.Scan(
+			&post.ID,
+			&post.UUID,
+			&post.AuthorID,
+			&post.Title,
+			&post.Slug,
+			&post.Content,
+			&post.Excerpt,
+			&post.Format,
+			&post.Visibility,
+			&post.Status,
+			&post.PublishedAt,
+			&post.CreatedAt,
+			&post.UpdatedAt,
+			&post.Author.UUID,
+			&post.Author.Username,
+			&post.ReadAt,
+			&post.Progress,
+		)
+		if err != nil {
+			return nil, 0, err
+		}
+		posts = append(posts, post)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	return posts, totalCount, nil
+}
+
+// AddBookmark bookmarks a post for a user; bookmarking twice is a no-op
+func (r *PostRepository) AddBookmark(ctx context.Context, userID, postID int) error {
+	query := `
+		INSERT INTO bookmarks (user_id, post_id)
+		VALUES ($1, $2)
+		ON CONFLICT (user_id, post_id) DO NOTHING
+	`
+
+	_, err := r.db.Exec(ctx, query, userID, postID)
+	return err
+}
+
+// RemoveBookmark removes a user's bookmark for a post
+func (r *PostRepository) RemoveBookmark(ctx context.Context, userID, postID int) error {
+	query := `DELETE FROM bookmarks WHERE user_id = $1 AND post_id = $2`
+
+	_, err := r.db.Exec(ctx, query, userID, postID)
+	return err
+}
+
+// BookmarkedPostIDs returns which of the given posts the user has bookmarked
+func (r *PostRepository) BookmarkedPostIDs(ctx context.Context, userUUID uuid.UUID, postIDs []int) (map[int]bool, error) {
+	query := `
+		SELECT b.post_id
+		FROM bookmarks b
+		INNER JOIN users u ON b.user_id = u.id
+		WHERE u.uuid = $1 AND b.post_id = ANY($2)
+	`
+
+	rows, err := r.db.Query(ctx, query, userUUID, postIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	bookmarked := make(map[int]bool)
+	for rows.Next() {
+		var postID int
+		if err := rows.Scan(&postID); err != nil {
+			return nil, err
+		}
+		bookmarked[postID] = true
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return bookmarked, nil
+}
+
+// Interactions returns the user's read and bookmark flags for the published
+// posts among postUUIDs, keyed by post UUID
+func (r *PostRepository) Interactions(ctx context.Context, userUUID uuid.UUID, postUUIDs []uuid.UUID) (map[uuid.UUID]domain.PostInteraction, error) {
+	query := `
+		SELECT
+			p.uuid,
+			EXISTS(SELECT 1 FROM read_posts rp WHERE rp.post_id = p.id AND rp.user_id = u.id),
+			EXISTS(SELECT 1 FROM bookmarks b WHERE b.post_id = p.id AND b.user_id = u.id)
+		FROM posts p
+		CROSS JOIN users u
+		WHERE u.uuid = $1 AND p.uuid = ANY($2) AND p.status = 'published'
+			AND (p.visibility <> 'private' OR p.author_id = u.id)
+	`
+
+	rows, err := database.ReadPool(ctx, r.db, r.replica).Query(ctx, query, userUUID, postUUIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	interactions := make(map[uuid.UUID]domain.PostInteraction)
+	for rows.Next() {
+		var interaction domain.PostInteraction
+		if err := rows.Scan(&interaction.PostUUID, &interaction.ReadByMe, &interaction.BookmarkedByMe); err != nil {
+			return nil, err
+		}
+		interactions[interaction.PostUUID] = interaction
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return interactions, nil
+}
+
+// ListBookmarks retrieves a user's bookmarked posts that are still published, most recent first
+func (r *PostRepository) ListBookmarks(ctx context.Context, userID int, req domain.ListBookmarksRequest) ([]domain.BookmarkedPost, int, error) {
+	countQuery := `
+		SELECT COUNT(*)
+		FROM bookmarks b
+		INNER JOIN posts p ON b.post_id = p.id
+		WHERE b.user_id = $1 AND p.status = 'published' AND (p.visibility <> 'private' OR p.author_id = $1)
+	`
+
+	var totalCount int
+	err := r.db.QueryRow(ctx, countQuery, userID).Scan(&totalCount)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	query := `
+		SELECT
+			p.id, p.uuid, p.author_id, p.title, p.slug, p.content, p.excerpt, p.format, p.visibility,
+			p.status, p.published_at, p.created_at, p.updated_at,
+			u.uuid, u.username, b.created_at
+		FROM bookmarks b
+		INNER JOIN posts p ON b.post_id = p.id
+		INNER JOIN users u ON p.author_id = u.id
+		WHERE b.user_id = $1 AND p.status = 'published' AND (p.visibility <> 'private' OR p.author_id = $1)
+		ORDER BY b.created_at DESC
+		LIMIT $2 OFFSET $3
+	`
+
+	rows, err := r.db.Query(ctx, query, userID, req.Limit, (req.Page-1)*req.Limit)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	posts := []domain.BookmarkedPost{}
+	for rows.Next() {
+		var post domain.BookmarkedPost
+		err := rows.Scan(
+			&post.ID,
+			&post.UUID,
+			&post.AuthorID,
+			&post.Title,
+			&post.Slug,
+			&post.Content,
+			&post.Excerpt,
+			&post.Format,
+			&post.Visibility,
+			&post.Status,
+			&post.PublishedAt,
+			&post.CreatedAt,
+			&post.UpdatedAt,
+			&post.Author.UUID,
+			&post.Author.Username,
+			&post.BookmarkedAt,
+		)
+		if err != nil {
+			return nil, 0, err
+		}
+		posts = append(posts, post)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	return posts, totalCount, nil
+}
+
+// ListGroupedByStatus returns up to perStatus of an author's most recently
+// updated posts in each status, with the total count for each status, in a
+// single query
+func (r *PostRepository) ListGroupedByStatus(ctx context.Context, authorID, perStatus int) ([]domain.PostWithAuthor, map[domain.PostStatus]int, error) {
+	query := `
+		SELECT
+			g.id, g.uuid, g.author_id, g.title, g.slug, g.content, g.excerpt, g.format, g.visibility,
+			g.status, g.published_at, g.created_at, g.updated_at,
+			u.uuid, u.username, g.status_count
+		FROM (
+			SELECT
+				p.*,
+				ROW_NUMBER() OVER (PARTITION BY p.status ORDER BY p.updated_at DESC, p.id DESC) AS rank,
+				COUNT(*) OVER (PARTITION BY p.status) AS status_count
+			FROM posts p
+			WHERE p.author_id = $1
+		) g
+		INNER JOIN users u ON g.author_id = u.id
+		WHERE g.rank <= $2
+		ORDER BY g.status, g.rank
+	`
+
+	rows, err := r.db.Query(ctx, query, authorID, perStatus)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	posts := []domain.PostWithAuthor{}
+	counts := make(map[domain.PostStatus]int)
+	for rows.Next() {
+		var post domain.PostWithAuthor
+		var statusCount int
+		err := rows.Scan(
+			&post.ID,
+			&post.UUID,
+			&post.AuthorID,
+			&post.Title,
+			&post.Slug,
+			&post.Content,
+			&post.Excerpt,
+			&post.Format,
+			&post.Visibility,
+			&post.Status,
+			&post.PublishedAt,
+			&post.CreatedAt,
+			&post.UpdatedAt,
+			&post.Author.UUID,
+			&post.Author.Username,
+			&statusCount,
+		)
+		if err != nil {
+			return nil, nil, err
+		}
+		posts = append(posts, post)
+		counts[post.Status] = statusCount
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	return posts, counts, nil
+}
+
+// AuthorStats aggregates an author's post counts and reader engagement in a single query
+func (r *PostRepository) AuthorStats(ctx context.Context, authorID int) (*domain.AuthorStatsResponse, error) {
+	query := `
+		SELECT
+			COUNT(*) FILTER (WHERE p.status = 'draft'),
+			COUNT(*) FILTER (WHERE p.status = 'published'),
+			COUNT(*) FILTER (WHERE p.status = 'archived'),
+			COUNT(*),
+			(SELECT COUNT(*) FROM read_posts rp INNER JOIN posts rpp ON rp.post_id = rpp.id WHERE rpp.author_id = $1),
+			(SELECT COUNT(*) FROM bookmarks b INNER JOIN posts bp ON b.post_id = bp.id WHERE bp.author_id = $1)
+		FROM posts p
+		WHERE p.author_id = $1
+	`
+
+	var stats domain.AuthorStatsResponse
+	err := r.db.QueryRow(ctx, query, authorID).Scan(
+		&stats.Posts.Draft,
+		&stats.Posts.Published,
+		&stats.Posts.Archived,
+		&stats.Posts.Total,
+		&stats.TotalReads,
+		&stats.TotalBookmarks,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &stats, nil
 }
@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/saimonsiddique/blog-api/internal/domain"
+)
+
+func TestWritePostError(t *testing.T) {
+	errOther := errors.New("connection reset")
+	notNull := &pgconn.PgError{
+		Code:    "23502",
+		Message: `duplicate key value violates unique constraint "posts_slug_key"`,
+	}
+
+	tests := []struct {
+		name string
+		err  error
+		want error
+	}{
+		{
+			name: "slug conflict",
+			err:  &pgconn.PgError{Code: "23505", ConstraintName: "posts_slug_key"},
+			want: domain.ErrSlugTaken,
+		},
+		{
+			name: "slug conflict with a localized message",
+			err: &pgconn.PgError{
+				Code:           "23505",
+				ConstraintName: "posts_slug_key",
+				Message:        "llave duplicada viola restricción de unicidad «posts_slug_key»",
+			},
+			want: domain.ErrSlugTaken,
+		},
+		{
+			name: "wrapped slug conflict",
+			err:  fmt.Errorf("insert post: %w", &pgconn.PgError{Code: "23505", ConstraintName: "posts_slug_key"}),
+			want: domain.ErrSlugTaken,
+		},
+		{
+			name: "other unique constraint",
+			err:  &pgconn.PgError{Code: "23505", ConstraintName: "posts_uuid_key"},
+			want: domain.ErrConflict,
+		},
+		{name: "slug message without the unique violation code", err: notNull, want: notNull},
+		{name: "not a postgres error", err: errOther, want: errOther},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := writePostError(tt.err); !errors.Is(got, tt.want) {
+				t.Errorf("writePostError() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPlaceholder(t *testing.T) {
+	tests := []struct {
+		n    int
+		want string
+	}{
+		{n: 1, want: "$1"},
+		{n: 9, want: "$9"},
+		{n: 10, want: "$10"},
+		{n: 12, want: "$12"},
+		{n: 100, want: "$100"},
+	}
+
+	for _, tt := range tests {
+		if got := placeholder(tt.n); got != tt.want {
+			t.Errorf("placeholder(%d) = %q, want %q", tt.n, got, tt.want)
+		}
+	}
+}
+
+// Updates with ten or more columns need two-digit placeholders
+func TestUpdatePostQueryPlaceholders(t *testing.T) {
+	postUUID := uuid.New()
+	updates := map[string]interface{}{
+		"title":        "Title",
+		"slug":         "title",
+		"content":      "Content",
+		"excerpt":      "Excerpt",
+		"format":       domain.PostFormatMarkdown,
+		"visibility":   domain.PostVisibilityPublic,
+		"status":       domain.PostStatusPublished,
+		"published_at": nil,
+		"author_id":    1,
+		"created_at":   nil,
+	}
+
+	query, args := updatePostQuery(postUUID, updates)
+
+	want := "UPDATE posts SET author_id = $1, content = $2, created_at = $3, excerpt = $4, format = $5, " +
+		"published_at = $6, slug = $7, status = $8, title = $9, visibility = $10, " +
+		"updated_at = CURRENT_TIMESTAMP WHERE uuid = $11 RETURNING "
+	if !strings.HasPrefix(query, want) {
+		t.Errorf("query = %q, want prefix %q", query, want)
+	}
+
+	if len(args) != 11 {
+		t.Fatalf("got %d args, want 11", len(args))
+	}
+	if args[9] != domain.PostVisibilityPublic {
+		t.Errorf("$10 = %v, want the visibility", args[9])
+	}
+	if args[10] != postUUID {
+		t.Errorf("$11 = %v, want the post UUID", args[10])
+	}
+}
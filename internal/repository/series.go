@@ -0,0 +1,297 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/saimonsiddique/blog-api/internal/domain"
+)
+
+type SeriesRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewSeriesRepository(db *pgxpool.Pool) *SeriesRepository {
+	return &SeriesRepository{db: db}
+}
+
+// Create creates a new series
+func (r *SeriesRepository) Create(ctx context.Context, series *domain.Series) error {
+	query := `
+		INSERT INTO series (author_id, title, slug, description)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, uuid, created_at, updated_at
+	`
+
+	err := r.db.QueryRow(ctx, query,
+		series.AuthorID,
+		series.Title,
+		series.Slug,
+		series.Description,
+	).Scan(&series.ID, &series.UUID, &series.CreatedAt, &series.UpdatedAt)
+
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return domain.ErrSlugTaken
+		}
+		return err
+	}
+
+	return nil
+}
+
+// GetBySlug retrieves a series by slug with author information
+func (r *SeriesRepository) GetBySlug(ctx context.Context, slug string) (*domain.SeriesWithAuthor, error) {
+	query := `
+		SELECT
+			s.id, s.uuid, s.author_id, s.title, s.slug, s.description,
+			s.created_at, s.updated_at,
+			u.uuid, u.username
+		FROM series s
+		INNER JOIN users u ON s.author_id = u.id
+		WHERE s.slug = $1
+	`
+
+	var series domain.SeriesWithAuthor
+	err := r.db.QueryRow(ctx, query, slug).Scan(
+		&series.ID,
+		&series.UUID,
+		&series.AuthorID,
+		&series.Title,
+		&series.Slug,
+		&series.Description,
+		&series.CreatedAt,
+		&series.UpdatedAt,
+		&series.Author.UUID,
+		&series.Author.Username,
+	)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrSeriesNotFound
+		}
+		return nil, err
+	}
+
+	return &series, nil
+}
+
+// ListPosts retrieves the posts of a series in order. With publishedOnly
+// set, unpublished and private posts are left out.
+func (r *SeriesRepository) ListPosts(ctx context.Context, seriesID int, publishedOnly bool) ([]domain.SeriesPost, error) {
+	query := `
+		SELECT
+			p.id, p.uuid, p.author_id, p.title, p.slug, p.content, p.excerpt, p.format, p.visibility,
+			p.status, p.published_at, p.created_at, p.updated_at,
+			u.uuid, u.username, sp.position
+		FROM series_posts sp
+		INNER JOIN posts p ON sp.post_id = p.id
+		INNER JOIN users u ON p.author_id = u.id
+		WHERE sp.series_id = $1 AND (NOT $2 OR (p.status = 'published' AND p.visibility <> 'private'))
+		ORDER BY sp.position
+	`
+
+	rows, err := r.db.Query(ctx, query, seriesID, publishedOnly)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	posts := []domain.SeriesPost{}
+	for rows.Next() {
+		var post domain.SeriesPost
+		err := rows.Scan(
+			&post.ID,
+			&post.UUID,
+			&post.AuthorID,
+			&post.Title,
+			&post.Slug,
+			&post.Content,
+			&post.Excerpt,
+			&post.Format,
+			&post.Visibility,
+			&post.Status,
+			&post.PublishedAt,
+			&post.CreatedAt,
+			&post.UpdatedAt,
+			&post.Author.UUID,
+			&post.Author.Username,
+			&post.Position,
+		)
+		if err != nil {
+			return nil, err
+		}
+		posts = append(posts, post)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return posts, nil
+}
+
+// AddPost appends a post to the end of a series
+func (r *SeriesRepository) AddPost(ctx context.Context, seriesID, postID int) error {
+	query := `
+		INSERT INTO series_posts (series_id, post_id, position)
+		SELECT $1, $2, COALESCE(MAX(position), 0) + 1
+		FROM series_posts
+		WHERE series_id = $1
+	`
+
+	_, err := r.db.Exec(ctx, query, seriesID, postID)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return domain.ErrPostInSeries
+		}
+		return err
+	}
+
+	return nil
+}
+
+// RemovePost removes a post from a series and closes the gap in positions
+func (r *SeriesRepository) RemovePost(ctx context.Context, seriesID, postID int) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	result, err := tx.Exec(ctx, `DELETE FROM series_posts WHERE series_id = $1 AND post_id = $2`, seriesID, postID)
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return domain.ErrPostNotFound
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE series_posts sp
+		SET position = ordered.rn
+		FROM (
+			SELECT post_id, ROW_NUMBER() OVER (ORDER BY position) AS rn
+			FROM series_posts
+			WHERE series_id = $1
+		) ordered
+		WHERE sp.post_id = ordered.post_id
+	`, seriesID)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// Reorder sets the positions of a series' posts to the order given.
+// postIDs must contain every post in the series exactly once.
+func (r *SeriesRepository) Reorder(ctx context.Context, seriesID int, postIDs []int) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	// Lock the series' rows so concurrent changes can't interleave
+	var count int
+	err = tx.QueryRow(ctx, `
+		SELECT COUNT(*) FROM (
+			SELECT 1 FROM series_posts WHERE series_id = $1 FOR UPDATE
+		) locked
+	`, seriesID).Scan(&count)
+	if err != nil {
+		return err
+	}
+
+	if count != len(postIDs) {
+		return domain.ErrInvalidSeriesOrder
+	}
+
+	for i, postID := range postIDs {
+		result, err := tx.Exec(ctx,
+			`UPDATE series_posts SET position = $1 WHERE series_id = $2 AND post_id = $3`,
+			i+1, seriesID, postID,
+		)
+		if err != nil {
+			return err
+		}
+
+		if result.RowsAffected() == 0 {
+			return domain.ErrInvalidSeriesOrder
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
+// GetPostSeriesInfo returns a post's position in its series and its published,
+// non-private neighbours, or nil if the post is not part of a series
+func (r *SeriesRepository) GetPostSeriesInfo(ctx context.Context, postID int) (*domain.PostSeriesInfo, error) {
+	query := `
+		SELECT
+			s.uuid, s.title, s.slug, sp.position,
+			prev.uuid, prev.title, prev.slug,
+			next.uuid, next.title, next.slug
+		FROM series_posts sp
+		INNER JOIN series s ON sp.series_id = s.id
+		LEFT JOIN LATERAL (
+			SELECT p.uuid, p.title, p.slug
+			FROM series_posts sp2
+			INNER JOIN posts p ON sp2.post_id = p.id
+			WHERE sp2.series_id = sp.series_id AND sp2.position < sp.position
+				AND p.status = 'published' AND p.visibility <> 'private'
+			ORDER BY sp2.position DESC
+			LIMIT 1
+		) prev ON true
+		LEFT JOIN LATERAL (
+			SELECT p.uuid, p.title, p.slug
+			FROM series_posts sp2
+			INNER JOIN posts p ON sp2.post_id = p.id
+			WHERE sp2.series_id = sp.series_id AND sp2.position > sp.position
+				AND p.status = 'published' AND p.visibility <> 'private'
+			ORDER BY sp2.position
+			LIMIT 1
+		) next ON true
+		WHERE sp.post_id = $1
+	`
+
+	var info domain.PostSeriesInfo
+	var prevUUID, nextUUID *uuid.UUID
+	var prevTitle, prevSlug, nextTitle, nextSlug *string
+	err := r.db.QueryRow(ctx, query, postID).Scan(
+		&info.UUID,
+		&info.Title,
+		&info.Slug,
+		&info.Position,
+		&prevUUID,
+		&prevTitle,
+		&prevSlug,
+		&nextUUID,
+		&nextTitle,
+		&nextSlug,
+	)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	if prevUUID != nil {
+		info.Prev = &domain.SeriesLink{UUID: *prevUUID, Title: *prevTitle, Slug: *prevSlug}
+	}
+	if nextUUID != nil {
+		info.Next = &domain.SeriesLink{UUID: *nextUUID, Title: *nextTitle, Slug: *nextSlug}
+	}
+
+	return &info, nil
+}
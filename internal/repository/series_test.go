@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/saimonsiddique/blog-api/internal/database/dbtest"
+)
+
+// Series navigation skips neighbours a reader couldn't open, so it doesn't
+// reveal a private post's title or slug
+func TestGetPostSeriesInfoSkipsHiddenNeighbours(t *testing.T) {
+	db := dbtest.New(t)
+	ctx := context.Background()
+
+	authorID := dbtest.CreateUser(t, db, "author")
+	var seriesID int
+	if err := db.QueryRow(ctx, `
+		INSERT INTO series (author_id, title, slug) VALUES ($1, 'Series', 'series') RETURNING id
+	`, authorID).Scan(&seriesID); err != nil {
+		t.Fatalf("failed to create series: %v", err)
+	}
+
+	posts := []struct {
+		slug       string
+		status     string
+		visibility string
+	}{
+		{slug: "first", status: "published", visibility: "public"},
+		{slug: "private-before", status: "published", visibility: "private"},
+		{slug: "current", status: "published", visibility: "public"},
+		{slug: "private-after", status: "published", visibility: "private"},
+		{slug: "draft-after", status: "draft", visibility: "public"},
+		{slug: "last", status: "published", visibility: "unlisted"},
+	}
+	ids := map[string]int{}
+	for i, post := range posts {
+		var id int
+		if err := db.QueryRow(ctx, `
+			INSERT INTO posts (author_id, title, slug, content, status, visibility)
+			VALUES ($1, $2, $2, 'content', $3, $4)
+			RETURNING id
+		`, authorID, post.slug, post.status, post.visibility).Scan(&id); err != nil {
+			t.Fatalf("failed to create post: %v", err)
+		}
+		dbtest.Exec(t, db, `INSERT INTO series_posts (series_id, post_id, position) VALUES ($1, $2, $3)`, seriesID, id, i+1)
+		ids[post.slug] = id
+	}
+
+	info, err := NewSeriesRepository(db).GetPostSeriesInfo(ctx, ids["current"])
+	if err != nil {
+		t.Fatalf("GetPostSeriesInfo() error = %v", err)
+	}
+	if info == nil {
+		t.Fatal("GetPostSeriesInfo() = nil, want the series")
+	}
+
+	if info.Prev == nil || info.Prev.Slug != "first" {
+		t.Errorf("prev = %+v, want the first post", info.Prev)
+	}
+	if info.Next == nil || info.Next.Slug != "last" {
+		t.Errorf("next = %+v, want the last post", info.Next)
+	}
+}
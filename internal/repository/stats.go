@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/saimonsiddique/blog-api/internal/domain"
+)
+
+type StatsRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewStatsRepository(db *pgxpool.Pool) *StatsRepository {
+	return &StatsRepository{db: db}
+}
+
+// PlatformTotals aggregates user and post counts across the platform
+func (r *StatsRepository) PlatformTotals(ctx context.Context) (*domain.AdminStatsResponse, error) {
+	query := `
+		SELECT
+			(SELECT COUNT(*) FROM users),
+			(SELECT COUNT(*) FROM users WHERE is_active),
+			COUNT(*) FILTER (WHERE status = 'draft'),
+			COUNT(*) FILTER (WHERE status = 'published'),
+			COUNT(*) FILTER (WHERE status = 'archived'),
+			COUNT(*),
+			COUNT(*) FILTER (WHERE status = 'published' AND published_at >= NOW() - INTERVAL '7 days'),
+			COUNT(*) FILTER (WHERE status = 'published' AND published_at >= NOW() - INTERVAL '30 days')
+		FROM posts
+	`
+
+	var stats domain.AdminStatsResponse
+	err := r.db.QueryRow(ctx, query).Scan(
+		&stats.Users.Total,
+		&stats.Users.Active,
+		&stats.Posts.Draft,
+		&stats.Posts.Published,
+		&stats.Posts.Archived,
+		&stats.Posts.Total,
+		&stats.Published.Last7Days,
+		&stats.Published.Last30Days,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &stats, nil
+}
+
+// TopAuthors retrieves the authors with the most posts
+func (r *StatsRepository) TopAuthors(ctx context.Context, limit int) ([]domain.TopAuthor, error) {
+	query := `
+		SELECT u.uuid, u.username, COUNT(p.id) AS post_count
+		FROM users u
+		INNER JOIN posts p ON p.author_id = u.id
+		GROUP BY u.id
+		ORDER BY post_count DESC, u.username
+		LIMIT $1
+	`
+
+	rows, err := r.db.Query(ctx, query, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	authors := []domain.TopAuthor{}
+	for rows.Next() {
+		var author domain.TopAuthor
+		if err := rows.Scan(&author.UUID, &author.Username, &author.PostCount); err != nil {
+			return nil, err
+		}
+		authors = append(authors, author)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return authors, nil
+}
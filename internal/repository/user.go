@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"errors"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
@@ -21,36 +22,75 @@ func NewUserRepository(db *pgxpool.Pool) *UserRepository {
 
 func (r *UserRepository) Create(ctx context.Context, user *domain.User) error {
 	const q = `
-        INSERT INTO users (username, email, password, role, is_active)
-        VALUES ($1, $2, $3, $4, $5)
+        INSERT INTO users (username, email, email_normalized, password, role, is_active)
+        VALUES ($1, $2, $3, $4, $5, $6)
         RETURNING id, uuid, created_at, updated_at
     `
 	err := r.db.QueryRow(ctx, q,
-		user.Username, user.Email, user.Password, user.Role, user.IsActive,
+		user.Username, user.Email, user.EmailNormalized, user.Password, user.Role, user.IsActive,
 	).Scan(&user.ID, &user.UUID, &user.CreatedAt, &user.UpdatedAt)
 
 	if err != nil {
-		var pgErr *pgconn.PgError
-		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
-			switch pgErr.ConstraintName {
-			case "users_email_key":
-				return domain.ErrEmailTaken
-			case "users_username_key":
-				return domain.ErrUsernameTaken
-			default:
-				return domain.ErrConflict
-			}
-		}
-		return err
+		return createUserError(err)
 	}
 	return nil
 }
 
+// CreateFirstAdmin creates the user as an admin if there are no other users,
+// or with user.Role otherwise, and sets user.Role to the role given. The
+// users table is locked against concurrent writes while checking, so of two
+// simultaneous first registrations only one becomes the admin.
+func (r *UserRepository) CreateFirstAdmin(ctx context.Context, user *domain.User) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `LOCK TABLE users IN SHARE ROW EXCLUSIVE MODE`); err != nil {
+		return err
+	}
+
+	const q = `
+        INSERT INTO users (username, email, email_normalized, password, role, is_active)
+        SELECT $1, $2, $3, $4,
+               CASE WHEN EXISTS (SELECT 1 FROM users) THEN $5 ELSE $7 END,
+               $6
+        RETURNING id, uuid, role, created_at, updated_at
+    `
+	err = tx.QueryRow(ctx, q,
+		user.Username, user.Email, user.EmailNormalized, user.Password, user.Role, user.IsActive,
+		domain.RoleAdmin,
+	).Scan(&user.ID, &user.UUID, &user.Role, &user.CreatedAt, &user.UpdatedAt)
+
+	if err != nil {
+		return createUserError(err)
+	}
+	return tx.Commit(ctx)
+}
+
+// createUserError maps unique violations on insert to domain errors
+func createUserError(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		switch pgErr.ConstraintName {
+		case "users_email_key", "users_email_normalized_key":
+			return domain.ErrEmailTaken
+		case "users_username_key":
+			return domain.ErrUsernameTaken
+		default:
+			return domain.ErrConflict
+		}
+	}
+	return err
+}
+
+// GetByEmail looks up a user by normalized email
 func (r *UserRepository) GetByEmail(ctx context.Context, email string) (*domain.User, error) {
 	query := `
-		SELECT id, uuid, username, email, password, role, is_active, created_at, updated_at
+		SELECT id, uuid, username, email, email_normalized, password, role, is_active, last_login_at, created_at, updated_at
 		FROM users
-		WHERE email = $1
+		WHERE email_normalized = $1
 	`
 
 	var user domain.User
@@ -59,9 +99,11 @@ func (r *UserRepository) GetByEmail(ctx context.Context, email string) (*domain.
 		&user.UUID,
 		&user.Username,
 		&user.Email,
+		&user.EmailNormalized,
 		&user.Password,
 		&user.Role,
 		&user.IsActive,
+		&user.LastLoginAt,
 		&user.CreatedAt,
 		&user.UpdatedAt,
 	)
@@ -78,7 +120,7 @@ func (r *UserRepository) GetByEmail(ctx context.Context, email string) (*domain.
 
 func (r *UserRepository) GetByUUID(ctx context.Context, userUUID uuid.UUID) (*domain.User, error) {
 	query := `
-		SELECT id, uuid, username, email, password, role, is_active, created_at, updated_at
+		SELECT id, uuid, username, email, email_normalized, password, role, is_active, last_login_at, created_at, updated_at
 		FROM users
 		WHERE uuid = $1
 	`
@@ -89,9 +131,11 @@ func (r *UserRepository) GetByUUID(ctx context.Context, userUUID uuid.UUID) (*do
 		&user.UUID,
 		&user.Username,
 		&user.Email,
+		&user.EmailNormalized,
 		&user.Password,
 		&user.Role,
 		&user.IsActive,
+		&user.LastLoginAt,
 		&user.CreatedAt,
 		&user.UpdatedAt,
 	)
@@ -109,14 +153,15 @@ func (r *UserRepository) GetByUUID(ctx context.Context, userUUID uuid.UUID) (*do
 func (r *UserRepository) Update(ctx context.Context, user *domain.User) error {
 	query := `
 		UPDATE users
-		SET username = $1, email = $2, updated_at = NOW()
-		WHERE id = $3
+		SET username = $1, email = $2, email_normalized = $3, updated_at = NOW()
+		WHERE id = $4
 		RETURNING updated_at
 	`
 
 	err := r.db.QueryRow(ctx, query,
 		user.Username,
 		user.Email,
+		user.EmailNormalized,
 		user.ID,
 	).Scan(&user.UpdatedAt)
 
@@ -127,7 +172,7 @@ func (r *UserRepository) Update(ctx context.Context, user *domain.User) error {
 		var pgErr *pgconn.PgError
 		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
 			switch pgErr.ConstraintName {
-			case "users_email_key":
+			case "users_email_key", "users_email_normalized_key":
 				return domain.ErrEmailTaken
 			case "users_username_key":
 				return domain.ErrUsernameTaken
@@ -141,9 +186,100 @@ func (r *UserRepository) Update(ctx context.Context, user *domain.User) error {
 	return nil
 }
 
+// UpdatePassword replaces a user's password hash
+func (r *UserRepository) UpdatePassword(ctx context.Context, userID int, hashedPassword string) error {
+	query := `UPDATE users SET password = $1, updated_at = NOW() WHERE id = $2`
+
+	result, err := r.db.Exec(ctx, query, hashedPassword, userID)
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return domain.ErrUserNotFound
+	}
+
+	return nil
+}
+
+// ListInactive returns active users who haven't logged in since before,
+// least recently active first. Users who never logged in count from when
+// they registered. Admins are only included with includeAdmins set.
+func (r *UserRepository) ListInactive(ctx context.Context, before time.Time, includeAdmins bool, limit int) ([]domain.User, error) {
+	query := `
+		SELECT id, uuid, username, email, email_normalized, password, role, is_active, last_login_at, created_at, updated_at
+		FROM users
+		WHERE is_active AND COALESCE(last_login_at, created_at) < $1
+			AND ($2 OR role <> 'admin')
+		ORDER BY COALESCE(last_login_at, created_at) ASC
+		LIMIT $3
+	`
+
+	rows, err := r.db.Query(ctx, query, before, includeAdmins, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	users := []domain.User{}
+	for rows.Next() {
+		var user domain.User
+		err := rows.Scan(
+			&user.ID,
+			&user.UUID,
+			&user.Username,
+			&user.Email,
+			&user.EmailNormalized,
+			&user.Password,
+			&user.Role,
+			&user.IsActive,
+			&user.LastLoginAt,
+			&user.CreatedAt,
+			&user.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, user)
+	}
+
+	return users, rows.Err()
+}
+
+// DeactivateInactive deactivates a user and deletes their refresh tokens if
+// they are still active and haven't logged in since before. It reports
+// whether the user was deactivated, so one who logged in after being listed
+// is left alone.
+func (r *UserRepository) DeactivateInactive(ctx context.Context, userID int, before time.Time) (bool, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback(ctx)
+
+	result, err := tx.Exec(ctx, `
+		UPDATE users
+		SET is_active = false, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND is_active AND COALESCE(last_login_at, created_at) < $2
+	`, userID, before)
+	if err != nil {
+		return false, err
+	}
+
+	if result.RowsAffected() == 0 {
+		return false, nil
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM refresh_tokens WHERE user_id = $1`, userID); err != nil {
+		return false, err
+	}
+
+	return true, tx.Commit(ctx)
+}
+
 func (r *UserRepository) GetByID(ctx context.Context, id int) (*domain.User, error) {
 	query := `
-		SELECT id, uuid, username, email, password, role, is_active, created_at, updated_at
+		SELECT id, uuid, username, email, email_normalized, password, role, is_active, last_login_at, created_at, updated_at
 		FROM users
 		WHERE id = $1
 	`
@@ -154,9 +290,11 @@ func (r *UserRepository) GetByID(ctx context.Context, id int) (*domain.User, err
 		&user.UUID,
 		&user.Username,
 		&user.Email,
+		&user.EmailNormalized,
 		&user.Password,
 		&user.Role,
 		&user.IsActive,
+		&user.LastLoginAt,
 		&user.CreatedAt,
 		&user.UpdatedAt,
 	)
@@ -171,8 +309,49 @@ func (r *UserRepository) GetByID(ctx context.Context, id int) (*domain.User, err
 	return &user, nil
 }
 
+// GetByUUIDs looks up the users with the given UUIDs in one query. UUIDs
+// with no user are skipped, and the order of the result is unspecified.
+func (r *UserRepository) GetByUUIDs(ctx context.Context, userUUIDs []uuid.UUID) ([]domain.User, error) {
+	query := `
+		SELECT id, uuid, username, email, email_normalized, password, role, is_active, last_login_at, created_at, updated_at
+		FROM users
+		WHERE uuid = ANY($1)
+	`
+
+	rows, err := r.db.Query(ctx, query, userUUIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	users := []domain.User{}
+	for rows.Next() {
+		var user domain.User
+		err := rows.Scan(
+			&user.ID,
+			&user.UUID,
+			&user.Username,
+			&user.Email,
+			&user.EmailNormalized,
+			&user.Password,
+			&user.Role,
+			&user.IsActive,
+			&user.LastLoginAt,
+			&user.CreatedAt,
+			&user.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, user)
+	}
+
+	return users, rows.Err()
+}
+
+// EmailExists reports whether a user with the normalized email exists
 func (r *UserRepository) EmailExists(ctx context.Context, email string) (bool, error) {
-	query := `SELECT EXISTS(SELECT 1 FROM users WHERE email = $1)`
+	query := `SELECT EXISTS(SELECT 1 FROM users WHERE email_normalized = $1)`
 
 	var exists bool
 	err := r.db.QueryRow(ctx, query, email).Scan(&exists)
@@ -0,0 +1,57 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/saimonsiddique/blog-api/internal/domain"
+	"github.com/saimonsiddique/blog-api/internal/repository"
+)
+
+const (
+	adminStatsCacheTTL = time.Minute
+	topAuthorsLimit    = 10
+)
+
+type AdminService struct {
+	statsRepo *repository.StatsRepository
+
+	// Platform stats are expensive and slow-changing, so they're cached briefly
+	mu             sync.Mutex
+	cachedStats    *domain.AdminStatsResponse
+	statsExpiresAt time.Time
+}
+
+func NewAdminService(statsRepo *repository.StatsRepository) *AdminService {
+	return &AdminService{
+		statsRepo: statsRepo,
+	}
+}
+
+// Stats returns platform-wide statistics
+func (s *AdminService) Stats(ctx context.Context) (*domain.AdminStatsResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.cachedStats != nil && time.Now().Before(s.statsExpiresAt) {
+		return s.cachedStats, nil
+	}
+
+	stats, err := s.statsRepo.PlatformTotals(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	stats.TopAuthors, err = s.statsRepo.TopAuthors(ctx, topAuthorsLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	stats.GeneratedAt = time.Now()
+
+	s.cachedStats = stats
+	s.statsExpiresAt = stats.GeneratedAt.Add(adminStatsCacheTTL)
+
+	return stats, nil
+}
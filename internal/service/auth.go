@@ -2,38 +2,81 @@ package service
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"log"
+	"net"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
 	"github.com/saimonsiddique/blog-api/internal/config"
 	"github.com/saimonsiddique/blog-api/internal/domain"
+	"github.com/saimonsiddique/blog-api/internal/pkg/captcha"
+	"github.com/saimonsiddique/blog-api/internal/pkg/email"
 	"github.com/saimonsiddique/blog-api/internal/pkg/password"
 	"github.com/saimonsiddique/blog-api/internal/repository"
 )
 
+// newLoginAlertTimeout bounds the background work of checking a login's
+// device and sending an alert
+const newLoginAlertTimeout = 10 * time.Second
+
 type AuthService struct {
-	userRepo *repository.UserRepository
-	authRepo *repository.AuthRepository
-	jwtCfg   *config.JWTConfig
+	userRepo   *repository.UserRepository
+	authRepo   *repository.AuthRepository
+	jwtCfg     *config.JWTConfig
+	usersCfg   *config.UsersConfig
+	flags      *FeatureFlagService
+	inviteRepo *repository.InviteRepository
+	passwords  password.Hasher
+	notifier   *NotificationService
+	captcha    captcha.Verifier
 }
 
 func NewAuthService(
 	userRepo *repository.UserRepository,
 	authRepo *repository.AuthRepository,
 	jwtCfg *config.JWTConfig,
+	usersCfg *config.UsersConfig,
+	flags *FeatureFlagService,
+	inviteRepo *repository.InviteRepository,
+	passwords password.Hasher,
+	notifier *NotificationService,
+	captchaVerifier captcha.Verifier,
 ) *AuthService {
 	return &AuthService{
-		userRepo: userRepo,
-		authRepo: authRepo,
-		jwtCfg:   jwtCfg,
+		userRepo:   userRepo,
+		authRepo:   authRepo,
+		jwtCfg:     jwtCfg,
+		usersCfg:   usersCfg,
+		flags:      flags,
+		inviteRepo: inviteRepo,
+		passwords:  passwords,
+		notifier:   notifier,
+		captcha:    captchaVerifier,
 	}
 }
 
-func (s *AuthService) Register(ctx context.Context, req domain.RegisterRequest) (*domain.AuthResponse, error) {
+func (s *AuthService) Register(ctx context.Context, req domain.RegisterRequest, client domain.ClientInfo) (*domain.AuthResponse, error) {
+	if err := s.verifyCaptcha(ctx, req.CaptchaToken, client); err != nil {
+		return nil, err
+	}
+
+	// While registration is closed, only invited users can register
+	inviteRequired := !s.flags.Enabled(domain.FlagRegistrationOpen)
+	if inviteRequired && req.InviteCode == "" {
+		return nil, domain.ErrRegistrationClosed
+	}
+
+	if s.usersCfg.IsReservedUsername(req.Username) {
+		return nil, domain.ErrUsernameReserved
+	}
+
 	// Check if email already exists
-	exists, err := s.userRepo.EmailExists(ctx, req.Email)
+	emailNormalized := email.Normalize(req.Email, s.usersCfg.NormalizeGmailAliases)
+	exists, err := s.userRepo.EmailExists(ctx, emailNormalized)
 	if err != nil {
 		return nil, err
 	}
@@ -42,39 +85,101 @@ func (s *AuthService) Register(ctx context.Context, req domain.RegisterRequest)
 	}
 
 	// Hash password
-	hashedPassword, err := password.Hash(req.Password)
+	hashedPassword, err := s.passwords.Hash(req.Password)
 	if err != nil {
 		return nil, err
 	}
 
+	// Claim the invite before creating the user so it can't be used twice,
+	// and give it back if the user can't be created
+	var inviteID int
+	if inviteRequired {
+		inviteID, err = s.inviteRepo.Claim(ctx, req.InviteCode, emailNormalized)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	// Create user
 	user := &domain.User{
-		Username: req.Username,
-		Email:    req.Email,
-		Password: hashedPassword,
-		Role:     domain.RoleUser,
-		IsActive: true,
+		Username:        req.Username,
+		Email:           req.Email,
+		EmailNormalized: emailNormalized,
+		Password:        hashedPassword,
+		Role:            domain.RoleUser,
+		IsActive:        true,
 	}
 
-	if err := s.userRepo.Create(ctx, user); err != nil {
+	if err := s.createUser(ctx, user); err != nil {
+		if inviteRequired {
+			if releaseErr := s.inviteRepo.Release(ctx, inviteID); releaseErr != nil {
+				return nil, errors.Join(err, releaseErr)
+			}
+		}
 		return nil, err
 	}
 
+	if inviteRequired {
+		if err := s.inviteRepo.SetUsedBy(ctx, inviteID, user.ID); err != nil {
+			return nil, err
+		}
+	}
+
 	// Generate tokens
 	log.Printf("deps: repo=%T %#v, svc=%T %#v", s.userRepo, s.userRepo, s, s)
 
-	return s.generateAuthResponse(ctx, user)
+	return s.generateAuthResponse(ctx, user, client)
 }
 
-func (s *AuthService) Login(ctx context.Context, req domain.LoginRequest) (*domain.AuthResponse, error) {
+// CreateInvite creates a single-use invite code for registering while
+// registration is closed, optionally pinned to an email address
+func (s *AuthService) CreateInvite(ctx context.Context, adminUUID uuid.UUID, req domain.CreateInviteRequest) (*domain.Invite, error) {
+	admin, err := s.userRepo.GetByUUID(ctx, adminUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	invite := &domain.Invite{
+		Code:      uuid.New().String(),
+		Email:     req.Email,
+		ExpiresAt: req.ExpiresAt,
+	}
+
+	var emailNormalized *string
+	if req.Email != nil {
+		normalized := email.Normalize(*req.Email, s.usersCfg.NormalizeGmailAliases)
+		emailNormalized = &normalized
+	}
+
+	if err := s.inviteRepo.Create(ctx, invite, emailNormalized, admin.ID); err != nil {
+		return nil, err
+	}
+
+	return invite, nil
+}
+
+// createUser creates a new account. When bootstrapping is enabled the first
+// user is made an admin.
+func (s *AuthService) createUser(ctx context.Context, user *domain.User) error {
+	if s.usersCfg.FirstUserIsAdmin {
+		return s.userRepo.CreateFirstAdmin(ctx, user)
+	}
+	return s.userRepo.Create(ctx, user)
+}
+
+func (s *AuthService) Login(ctx context.Context, req domain.LoginRequest, client domain.ClientInfo) (*domain.AuthResponse, error) {
+	if err := s.verifyCaptcha(ctx, req.CaptchaToken, client); err != nil {
+		return nil, err
+	}
+
 	// Get user by email
-	user, err := s.userRepo.GetByEmail(ctx, req.Email)
+	user, err := s.userRepo.GetByEmail(ctx, email.Normalize(req.Email, s.usersCfg.NormalizeGmailAliases))
 	if err != nil {
 		return nil, err
 	}
 
 	// Verify password
-	if err := password.Verify(user.Password, req.Password); err != nil {
+	if err := s.passwords.Verify(user.Password, req.Password); err != nil {
 		return nil, domain.ErrInvalidCredentials
 	}
 
@@ -83,11 +188,75 @@ func (s *AuthService) Login(ctx context.Context, req domain.LoginRequest) (*doma
 		return nil, domain.ErrForbidden
 	}
 
+	// Move the hash to the configured algorithm now that the password is
+	// known; failing to is harmless, it's retried on the next login
+	if s.passwords.NeedsRehash(user.Password) {
+		if hashedPassword, err := s.passwords.Hash(req.Password); err == nil {
+			_ = s.userRepo.UpdatePassword(ctx, user.ID, hashedPassword)
+		}
+	}
+
 	// Generate tokens
-	return s.generateAuthResponse(ctx, user)
+	response, err := s.generateAuthResponse(ctx, user, client)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.usersCfg.NewLoginAlerts {
+		go s.alertNewDevice(context.WithoutCancel(ctx), user, client)
+	}
+
+	return response, nil
 }
 
-func (s *AuthService) RefreshToken(ctx context.Context, req domain.RefreshRequest) (*domain.AuthResponse, error) {
+// verifyCaptcha checks the client's CAPTCHA token when a provider is
+// configured
+func (s *AuthService) verifyCaptcha(ctx context.Context, token string, client domain.ClientInfo) error {
+	if err := s.captcha.Verify(ctx, token, client.IPAddress); err != nil {
+		if errors.Is(err, captcha.ErrFailed) {
+			return domain.ErrCaptchaFailed
+		}
+		return err
+	}
+	return nil
+}
+
+// alertNewDevice remembers the device a user logged in from and warns them if
+// it's new. It runs off the login path; failures are dropped, as the alert is
+// advisory and the device is retried on the next login.
+func (s *AuthService) alertNewDevice(ctx context.Context, user *domain.User, client domain.ClientInfo) {
+	ctx, cancel := context.WithTimeout(ctx, newLoginAlertTimeout)
+	defer cancel()
+
+	isNew, err := s.authRepo.RememberDevice(ctx, user.ID, s.deviceHash(client))
+	if err != nil || !isNew {
+		return
+	}
+
+	_ = s.notifier.Notify(ctx, user, domain.NotificationNewLogin, domain.NewLoginNotification{
+		IPAddress:  client.IPAddress,
+		UserAgent:  client.UserAgent,
+		LoggedInAt: time.Now(),
+	})
+}
+
+// deviceHash identifies a client device by its user agent and the network
+// of its IP address
+func (s *AuthService) deviceHash(client domain.ClientInfo) string {
+	network := client.IPAddress
+	if ip := net.ParseIP(client.IPAddress); ip != nil {
+		if ip4 := ip.To4(); ip4 != nil {
+			network = ip4.Mask(net.CIDRMask(s.usersCfg.NewDeviceIPv4Prefix, 32)).String()
+		} else {
+			network = ip.Mask(net.CIDRMask(s.usersCfg.NewDeviceIPv6Prefix, 128)).String()
+		}
+	}
+
+	sum := sha256.Sum256([]byte(client.UserAgent + "\n" + network))
+	return hex.EncodeToString(sum[:])
+}
+
+func (s *AuthService) RefreshToken(ctx context.Context, req domain.RefreshRequest, client domain.ClientInfo) (*domain.AuthResponse, error) {
 	// Get refresh token from database
 	rt, err := s.authRepo.GetRefreshToken(ctx, req.RefreshToken)
 	if err != nil {
@@ -113,10 +282,44 @@ func (s *AuthService) RefreshToken(ctx context.Context, req domain.RefreshReques
 	}
 
 	// Generate new tokens
-	return s.generateAuthResponse(ctx, user)
+	return s.generateAuthResponse(ctx, user, client)
+}
+
+// ListSessions lists active sessions across users (admin only)
+func (s *AuthService) ListSessions(ctx context.Context, req domain.ListSessionsRequest) (*domain.ListSessionsResponse, error) {
+	if req.Page == 0 {
+		req.Page = 1
+	}
+	if req.Limit == 0 {
+		req.Limit = 20
+	}
+
+	sessions, totalCount, err := s.authRepo.ListSessions(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.ListSessionsResponse{
+		Sessions:   sessions,
+		TotalCount: totalCount,
+		Page:       req.Page,
+		Limit:      req.Limit,
+	}, nil
+}
+
+// RevokeSession deletes a session so its refresh token can't be used again.
+// Access tokens already issued stay valid until they expire.
+func (s *AuthService) RevokeSession(ctx context.Context, sessionUUID uuid.UUID) error {
+	return s.authRepo.DeleteSession(ctx, sessionUUID)
+}
+
+// Logout ends the session of a refresh token. Unknown tokens are ignored, so
+// logging out twice succeeds.
+func (s *AuthService) Logout(ctx context.Context, req domain.LogoutRequest) error {
+	return s.authRepo.DeleteRefreshToken(ctx, req.RefreshToken)
 }
 
-func (s *AuthService) generateAuthResponse(ctx context.Context, user *domain.User) (*domain.AuthResponse, error) {
+func (s *AuthService) generateAuthResponse(ctx context.Context, user *domain.User, client domain.ClientInfo) (*domain.AuthResponse, error) {
 	// Generate access token
 	accessToken, err := s.generateAccessToken(user)
 	if err != nil {
@@ -128,7 +331,7 @@ func (s *AuthService) generateAuthResponse(ctx context.Context, user *domain.Use
 	expiresAt := time.Now().Add(s.jwtCfg.RefreshTTL)
 
 	// Store refresh token
-	if err := s.authRepo.StoreRefreshToken(ctx, user.ID, refreshToken, expiresAt); err != nil {
+	if err := s.authRepo.StoreRefreshToken(ctx, user.ID, refreshToken, expiresAt, client); err != nil {
 		return nil, err
 	}
 
@@ -140,22 +343,62 @@ func (s *AuthService) generateAuthResponse(ctx context.Context, user *domain.Use
 	}, nil
 }
 
+// Impersonate issues a short-lived access token for the target user with an
+// "imp" claim recording the admin. Admin accounts can't be impersonated.
+func (s *AuthService) Impersonate(ctx context.Context, adminUUID, targetUUID uuid.UUID) (*domain.ImpersonationResponse, error) {
+	if adminUUID == targetUUID {
+		return nil, domain.ErrForbidden
+	}
+
+	target, err := s.userRepo.GetByUUID(ctx, targetUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	if target.Role == domain.RoleAdmin || !target.IsActive {
+		return nil, domain.ErrForbidden
+	}
+
+	accessToken, err := s.signAccessToken(target, s.jwtCfg.ImpersonationTTL, jwt.MapClaims{
+		"imp": adminUUID.String(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.ImpersonationResponse{
+		AccessToken:    accessToken,
+		ExpiresIn:      int(s.jwtCfg.ImpersonationTTL.Seconds()),
+		User:           target.ToResponse(),
+		ImpersonatedBy: adminUUID,
+	}, nil
+}
+
 func (s *AuthService) generateAccessToken(user *domain.User) (string, error) {
+	return s.signAccessToken(user, s.jwtCfg.AccessTTL, nil)
+}
+
+// signAccessToken signs an access token for user valid for ttl, with any
+// extra claims added
+func (s *AuthService) signAccessToken(user *domain.User, ttl time.Duration, extra jwt.MapClaims) (string, error) {
 	claims := jwt.RegisteredClaims{
 		Subject:   user.UUID.String(),
 		Issuer:    s.jwtCfg.Issuer,
-		ExpiresAt: jwt.NewNumericDate(time.Now().Add(s.jwtCfg.AccessTTL)),
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(ttl)),
 		IssuedAt:  jwt.NewNumericDate(time.Now()),
 	}
 
 	// Add custom claims for role
 	customClaims := jwt.MapClaims{
 		"sub":  user.UUID.String(),
-		"role": user.Role,
+		"role": string(user.Role),
 		"iss":  s.jwtCfg.Issuer,
 		"exp":  claims.ExpiresAt.Unix(),
 		"iat":  claims.IssuedAt.Unix(),
 	}
+	for key, value := range extra {
+		customClaims[key] = value
+	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, customClaims)
 	return token.SignedString([]byte(s.jwtCfg.Secret))
@@ -0,0 +1,119 @@
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/saimonsiddique/blog-api/internal/config"
+	"github.com/saimonsiddique/blog-api/internal/database/dbtest"
+	"github.com/saimonsiddique/blog-api/internal/domain"
+	"github.com/saimonsiddique/blog-api/internal/pkg/captcha"
+	"github.com/saimonsiddique/blog-api/internal/pkg/password"
+	"github.com/saimonsiddique/blog-api/internal/repository"
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// weakArgon2idHash hashes with cheaper parameters than the current ones, as
+// an older release might have
+func weakArgon2idHash(t *testing.T, plain string) string {
+	t.Helper()
+	salt := []byte("0123456789abcdef")
+	key := argon2.IDKey([]byte(plain), salt, 1, 8*1024, 1, 32)
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version, 8*1024, 1, 1,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	)
+}
+
+// Logging in with a password stored under an old algorithm or old
+// parameters stores a hash with the current ones
+func TestLoginRehashesPassword(t *testing.T) {
+	db := dbtest.New(t)
+	ctx := context.Background()
+	const plain = "correct horse battery staple"
+
+	hasher, err := password.New(password.AlgorithmArgon2id)
+	if err != nil {
+		t.Fatalf("password.New() error = %v", err)
+	}
+	verifier, err := captcha.New(captcha.ProviderNone, "", time.Second)
+	if err != nil {
+		t.Fatalf("captcha.New() error = %v", err)
+	}
+
+	s := NewAuthService(
+		repository.NewUserRepository(db),
+		repository.NewAuthRepository(db),
+		&config.JWTConfig{Secret: "0123456789abcdef0123456789abcdef", AccessTTL: time.Minute, RefreshTTL: time.Hour},
+		&config.UsersConfig{},
+		nil,
+		nil,
+		hasher,
+		nil,
+		verifier,
+	)
+
+	bcryptHash, err := bcrypt.GenerateFromPassword([]byte(plain), bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("failed to make a bcrypt hash: %v", err)
+	}
+	currentHash, err := hasher.Hash(plain)
+	if err != nil {
+		t.Fatalf("Hash() error = %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		stored     string
+		wantRehash bool
+	}{
+		{name: "bcrypt", stored: string(bcryptHash), wantRehash: true},
+		{name: "weak argon2id", stored: weakArgon2idHash(t, plain), wantRehash: true},
+		{name: "current argon2id", stored: currentHash, wantRehash: false},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			username := fmt.Sprintf("user%d", i)
+			userID := dbtest.CreateUser(t, db, username)
+			dbtest.Exec(t, db, `UPDATE users SET password = $1 WHERE id = $2`, tt.stored, userID)
+
+			if err := hasher.Verify(tt.stored, plain); err != nil {
+				t.Fatalf("stored hash doesn't verify: %v", err)
+			}
+
+			_, err := s.Login(ctx, domain.LoginRequest{Email: username + "@example.com", Password: plain}, domain.ClientInfo{})
+			if err != nil {
+				t.Fatalf("Login() error = %v", err)
+			}
+
+			var stored string
+			if err := db.QueryRow(ctx, `SELECT password FROM users WHERE id = $1`, userID).Scan(&stored); err != nil {
+				t.Fatalf("failed to read user: %v", err)
+			}
+
+			if !tt.wantRehash {
+				if stored != tt.stored {
+					t.Errorf("stored hash changed from %q to %q, want it kept", tt.stored, stored)
+				}
+				return
+			}
+
+			if stored == tt.stored {
+				t.Fatal("stored hash wasn't replaced")
+			}
+			if !strings.HasPrefix(stored, "$argon2id$") || hasher.NeedsRehash(stored) {
+				t.Errorf("stored hash %q isn't an argon2id hash with the current parameters", stored)
+			}
+			if err := hasher.Verify(stored, plain); err != nil {
+				t.Errorf("new hash doesn't verify: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,122 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/saimonsiddique/blog-api/internal/domain"
+	"github.com/saimonsiddique/blog-api/internal/repository"
+	"github.com/sirupsen/logrus"
+)
+
+// FeatureFlagService serves feature flags from memory, refreshed from the
+// database periodically so changes made through another instance are picked
+// up without a restart. Flags that were never set use their default.
+type FeatureFlagService struct {
+	flagRepo *repository.FeatureFlagRepository
+	logger   *logrus.Logger
+
+	mu    sync.RWMutex
+	flags map[string]domain.FeatureFlag
+}
+
+func NewFeatureFlagService(flagRepo *repository.FeatureFlagRepository, logger *logrus.Logger) *FeatureFlagService {
+	return &FeatureFlagService{
+		flagRepo: flagRepo,
+		logger:   logger,
+		flags:    make(map[string]domain.FeatureFlag),
+	}
+}
+
+// Enabled reports whether a flag is on
+func (s *FeatureFlagService) Enabled(key string) bool {
+	s.mu.RLock()
+	flag, ok := s.flags[key]
+	s.mu.RUnlock()
+
+	if !ok {
+		return domain.DefaultFeatureFlags[key]
+	}
+	return flag.Enabled
+}
+
+// List returns every known flag with its current value
+func (s *FeatureFlagService) List() *domain.ListFeatureFlagsResponse {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	flags := make([]domain.FeatureFlag, 0, len(domain.DefaultFeatureFlags))
+	for key, enabled := range domain.DefaultFeatureFlags {
+		flag, ok := s.flags[key]
+		if !ok {
+			flag = domain.FeatureFlag{Key: key, Enabled: enabled}
+		}
+		flags = append(flags, flag)
+	}
+
+	sort.Slice(flags, func(i, j int) bool {
+		return flags[i].Key < flags[j].Key
+	})
+
+	return &domain.ListFeatureFlagsResponse{Flags: flags}
+}
+
+// Set stores a flag's value. It takes effect on this instance immediately
+// and on others at their next refresh.
+func (s *FeatureFlagService) Set(ctx context.Context, key string, enabled bool) (*domain.FeatureFlag, error) {
+	if _, known := domain.DefaultFeatureFlags[key]; !known {
+		return nil, domain.ErrFeatureFlagNotFound
+	}
+
+	flag, err := s.flagRepo.Set(ctx, key, enabled)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	s.flags[key] = *flag
+	s.mu.Unlock()
+
+	return flag, nil
+}
+
+// Refresh reloads every flag from the database
+func (s *FeatureFlagService) Refresh(ctx context.Context) error {
+	stored, err := s.flagRepo.List(ctx)
+	if err != nil {
+		return err
+	}
+
+	flags := make(map[string]domain.FeatureFlag, len(stored))
+	for _, flag := range stored {
+		flags[flag.Key] = flag
+	}
+
+	s.mu.Lock()
+	s.flags = flags
+	s.mu.Unlock()
+
+	return nil
+}
+
+// StartRefresh refreshes the flags every interval until ctx is cancelled.
+// Failed refreshes are logged and the previous values kept.
+func (s *FeatureFlagService) StartRefresh(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := s.Refresh(ctx); err != nil && ctx.Err() == nil {
+					s.logger.WithError(err).Warn("Failed to refresh feature flags")
+				}
+			}
+		}
+	}()
+}
@@ -0,0 +1,166 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/google/uuid"
+	"github.com/saimonsiddique/blog-api/internal/cache"
+	"github.com/saimonsiddique/blog-api/internal/domain"
+	"github.com/saimonsiddique/blog-api/internal/queue"
+	"github.com/saimonsiddique/blog-api/internal/repository"
+)
+
+// notificationReplayLimit caps how many missed notifications are sent to a
+// reconnecting stream
+const notificationReplayLimit = 100
+
+// NotificationService serves users' notification inboxes. Notifications are
+// produced asynchronously, by the workers handling the triggering events or
+// through Notify off the request path, so producing one never slows down the
+// request that caused it. Producers also publish new notifications through
+// broker for live streams on every instance and drop the recipient's cached unread count.
+type NotificationService struct {
+	notificationRepo *repository.NotificationRepository
+	userRepo         *repository.UserRepository
+	broker           *queue.Relay[domain.Notification]
+	unreadCounts     *cache.TTL[*domain.UnreadNotificationsResponse]
+}
+
+// NewNotificationService creates the service. unreadCounts, keyed by user
+// UUID, may be nil.
+func NewNotificationService(
+	notificationRepo *repository.NotificationRepository,
+	userRepo *repository.UserRepository,
+	broker *queue.Relay[domain.Notification],
+	unreadCounts *cache.TTL[*domain.UnreadNotificationsResponse],
+) *NotificationService {
+	return &NotificationService{
+		notificationRepo: notificationRepo,
+		userRepo:         userRepo,
+		broker:           broker,
+		unreadCounts:     unreadCounts,
+	}
+}
+
+// List lists the user's notifications, newest first
+func (s *NotificationService) List(ctx context.Context, userUUID uuid.UUID, req domain.ListNotificationsRequest) (*domain.ListNotificationsResponse, error) {
+	// Set defaults
+	if req.Page == 0 {
+		req.Page = 1
+	}
+	if req.Limit == 0 {
+		req.Limit = 20
+	}
+
+	user, err := s.userRepo.GetByUUID(ctx, userUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	notifications, totalCount, err := s.notificationRepo.List(ctx, user.ID, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.ListNotificationsResponse{
+		Notifications: notifications,
+		TotalCount:    totalCount,
+		Page:          req.Page,
+		Limit:         req.Limit,
+	}, nil
+}
+
+// UnreadCount returns how many unread notifications the user has
+func (s *NotificationService) UnreadCount(ctx context.Context, userUUID uuid.UUID) (*domain.UnreadNotificationsResponse, error) {
+	return s.unreadCounts.GetOrLoad(userUUID.String(), func() (*domain.UnreadNotificationsResponse, error) {
+		user, err := s.userRepo.GetByUUID(ctx, userUUID)
+		if err != nil {
+			return nil, err
+		}
+
+		count, err := s.notificationRepo.UnreadCount(ctx, user.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		return &domain.UnreadNotificationsResponse{Count: count}, nil
+	})
+}
+
+// Notify adds a notification to a user's inbox and pushes it to their live
+// streams
+func (s *NotificationService) Notify(ctx context.Context, user *domain.User, notificationType domain.NotificationType, payload any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	notification, err := s.notificationRepo.Create(ctx, user.ID, notificationType, body)
+	if err != nil {
+		return err
+	}
+
+	s.unreadCounts.Delete(user.UUID.String())
+	s.broker.Publish(ctx, *notification)
+	return nil
+}
+
+// MarkRead marks one of the user's notifications as read. Other users'
+// notifications are reported as not found.
+func (s *NotificationService) MarkRead(ctx context.Context, userUUID, notificationUUID uuid.UUID) (*domain.Notification, error) {
+	user, err := s.userRepo.GetByUUID(ctx, userUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	notification, err := s.notificationRepo.MarkRead(ctx, user.ID, notificationUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.unreadCounts.Delete(userUUID.String())
+	return notification, nil
+}
+
+// MarkAllRead marks all of the user's notifications as read
+func (s *NotificationService) MarkAllRead(ctx context.Context, userUUID uuid.UUID) (*domain.MarkAllNotificationsReadResponse, error) {
+	user, err := s.userRepo.GetByUUID(ctx, userUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	updated, err := s.notificationRepo.MarkAllRead(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.unreadCounts.Delete(userUUID.String())
+	return &domain.MarkAllNotificationsReadResponse{Updated: updated}, nil
+}
+
+// Subscribe returns a channel of the user's new notifications as they are
+// produced, and a function that cancels the subscription
+func (s *NotificationService) Subscribe(ctx context.Context, userUUID uuid.UUID) (<-chan domain.Notification, func(), error) {
+	user, err := s.userRepo.GetByUUID(ctx, userUUID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	notifications, unsubscribe := s.broker.Subscribe(func(notification domain.Notification) bool {
+		return notification.UserID == user.ID
+	})
+	return notifications, unsubscribe, nil
+}
+
+// Missed returns the user's notifications created after lastUUID, oldest
+// first, so a reconnecting stream can catch up. At most
+// notificationReplayLimit are returned.
+func (s *NotificationService) Missed(ctx context.Context, userUUID, lastUUID uuid.UUID) ([]domain.Notification, error) {
+	user, err := s.userRepo.GetByUUID(ctx, userUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.notificationRepo.ListAfter(ctx, user.ID, lastUUID, notificationReplayLimit)
+}
@@ -2,11 +2,22 @@ package service
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/google/uuid"
+	"github.com/saimonsiddique/blog-api/internal/cache"
+	"github.com/saimonsiddique/blog-api/internal/config"
+	"github.com/saimonsiddique/blog-api/internal/database"
 	"github.com/saimonsiddique/blog-api/internal/domain"
+	"github.com/saimonsiddique/blog-api/internal/pkg/excerpt"
+	"github.com/saimonsiddique/blog-api/internal/pkg/sanitize"
 	"github.com/saimonsiddique/blog-api/internal/pkg/slug"
+	"github.com/saimonsiddique/blog-api/internal/pkg/toc"
 	"github.com/saimonsiddique/blog-api/internal/queue"
 	"github.com/saimonsiddique/blog-api/internal/repository"
 )
@@ -14,19 +25,49 @@ import (
 type PostService struct {
 	postRepo      *repository.PostRepository
 	userRepo      *repository.UserRepository
+	seriesRepo    *repository.SeriesRepository
 	postPublisher *queue.PostPublisher
+	slugMaxLength int
+	postsCfg      *config.PostsConfig
+	listCache     *cache.TTL[*domain.ListPostsResponse]
+	broker        *queue.Relay[domain.PostPublishedNotification]
+	notifier      *NotificationService
 }
 
-func NewPostService(postRepo *repository.PostRepository, userRepo *repository.UserRepository, postPublisher *queue.PostPublisher) *PostService {
+// NewPostService creates the post service. Posts published directly, rather
+// than through the publish queue, are announced through broker and confirmed to
+// their author through notifier, as the publish worker does.
+func NewPostService(
+	postRepo *repository.PostRepository,
+	userRepo *repository.UserRepository,
+	seriesRepo *repository.SeriesRepository,
+	postPublisher *queue.PostPublisher,
+	slugMaxLength int,
+	postsCfg *config.PostsConfig,
+	listCache *cache.TTL[*domain.ListPostsResponse],
+	broker *queue.Relay[domain.PostPublishedNotification],
+	notifier *NotificationService,
+) *PostService {
 	return &PostService{
 		postRepo:      postRepo,
 		userRepo:      userRepo,
+		seriesRepo:    seriesRepo,
 		postPublisher: postPublisher,
+		slugMaxLength: slugMaxLength,
+		postsCfg:      postsCfg,
+		listCache:     listCache,
+		broker:        broker,
+		notifier:      notifier,
 	}
 }
 
 // Create creates a new post
 func (s *PostService) Create(ctx context.Context, userUUID uuid.UUID, req domain.CreatePostRequest) (*domain.PostResponse, error) {
+	isDraft := req.Status == "" || req.Status == domain.PostStatusDraft
+	if err := s.checkContentPolicy(&req.Title, &req.Content, req.Excerpt, isDraft); err != nil {
+		return nil, err
+	}
+
 	// Get user by UUID
 	user, err := s.userRepo.GetByUUID(ctx, userUUID)
 	if err != nil {
@@ -34,7 +75,32 @@ func (s *PostService) Create(ctx context.Context, userUUID uuid.UUID, req domain
 	}
 
 	// Generate slug from title
-	postSlug := slug.Generate(req.Title)
+	postSlug := slug.GenerateWithMaxLength(req.Title, s.slugMaxLength)
+
+	req.Content = s.sanitize(req.Content)
+	if req.Excerpt != nil {
+		sanitized := s.sanitize(*req.Excerpt)
+		req.Excerpt = &sanitized
+	}
+
+	// Generate an excerpt when the author didn't write one
+	if s.postsCfg.AutoExcerpt && strings.TrimSpace(req.Content) != "" &&
+		(req.Excerpt == nil || strings.TrimSpace(*req.Excerpt) == "") {
+		generated := s.generateExcerpt(req.Content)
+		req.Excerpt = &generated
+	}
+
+	// Catch accidental double submits of the same post
+	if s.postsCfg.DuplicateWindow > 0 {
+		since := time.Now().Add(-s.postsCfg.DuplicateWindow)
+		existingUUID, err := s.postRepo.FindRecentDuplicate(ctx, user.ID, req.Title, req.Content, since)
+		if err != nil {
+			return nil, err
+		}
+		if existingUUID != uuid.Nil {
+			return nil, fmt.Errorf("%w: matches existing post %s", domain.ErrDuplicatePost, existingUUID)
+		}
+	}
 
 	// Set default status if not provided
 	status := req.Status
@@ -42,9 +108,24 @@ func (s *PostService) Create(ctx context.Context, userUUID uuid.UUID, req domain
 		status = domain.PostStatusDraft
 	}
 
+	// Set default format if not provided
+	format := req.Format
+	if format == "" {
+		format = domain.PostFormatMarkdown
+	}
+
+	// Set default visibility if not provided
+	visibility := req.Visibility
+	if visibility == "" {
+		visibility = domain.PostVisibilityPublic
+	}
+
 	// Set published_at if status is published
 	var publishedAt *time.Time
 	if status == domain.PostStatusPublished {
+		if err := s.validatePublishable(req.Content, req.Excerpt); err != nil {
+			return nil, err
+		}
 		now := time.Now()
 		publishedAt = &now
 	}
@@ -56,13 +137,20 @@ func (s *PostService) Create(ctx context.Context, userUUID uuid.UUID, req domain
 		Slug:        postSlug,
 		Content:     req.Content,
 		Excerpt:     req.Excerpt,
+		Format:      format,
+		Visibility:  visibility,
 		Status:      status,
 		PublishedAt: publishedAt,
 	}
 
-	if err := s.postRepo.Create(ctx, post); err != nil {
+	err = s.withUniqueSlug(postSlug, func(candidate string) error {
+		post.Slug = candidate
+		return s.postRepo.Create(ctx, post)
+	})
+	if err != nil {
 		return nil, err
 	}
+	s.listCache.Invalidate()
 
 	// Return response
 	return &domain.PostResponse{
@@ -71,6 +159,8 @@ func (s *PostService) Create(ctx context.Context, userUUID uuid.UUID, req domain
 		Slug:        post.Slug,
 		Content:     post.Content,
 		Excerpt:     post.Excerpt,
+		Format:      post.Format,
+		Visibility:  post.Visibility,
 		Status:      post.Status,
 		PublishedAt: post.PublishedAt,
 		CreatedAt:   post.CreatedAt,
@@ -82,50 +172,218 @@ func (s *PostService) Create(ctx context.Context, userUUID uuid.UUID, req domain
 	}, nil
 }
 
-// GetByUUID retrieves a post by UUID
-func (s *PostService) GetByUUID(ctx context.Context, postUUID uuid.UUID) (*domain.PostResponse, error) {
+// ListEditable lists the posts the user can edit: their own posts, or every
+// post for admins
+func (s *PostService) ListEditable(ctx context.Context, userUUID uuid.UUID, req domain.ListEditablePostsRequest) (*domain.ListPostsResponse, error) {
+	if req.Page == 0 {
+		req.Page = 1
+	}
+	if req.Limit == 0 {
+		req.Limit = 10
+	}
+
+	user, err := s.userRepo.GetByUUID(ctx, userUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	posts, totalCount, err := s.postRepo.ListEditable(ctx, user.ID, user.Role == domain.RoleAdmin, req)
+	if err != nil {
+		return nil, err
+	}
+
+	postResponses := make([]domain.PostResponse, len(posts))
+	for i, post := range posts {
+		postResponses[i] = domain.PostResponse{
+			UUID:        post.UUID,
+			Title:       post.Title,
+			Slug:        post.Slug,
+			Content:     post.Content,
+			Excerpt:     post.Excerpt,
+			Format:      post.Format,
+			Visibility:  post.Visibility,
+			Status:      post.Status,
+			PublishedAt: post.PublishedAt,
+			CreatedAt:   post.CreatedAt,
+			UpdatedAt:   post.UpdatedAt,
+			Author:      post.Author,
+		}
+	}
+
+	return &domain.ListPostsResponse{
+		Posts:      postResponses,
+		TotalCount: totalCount,
+		Page:       req.Page,
+		Limit:      req.Limit,
+	}, nil
+}
+
+// LastModified returns when one of the user's posts was last updated, read
+// from the primary
+func (s *PostService) LastModified(ctx context.Context, userUUID, postUUID uuid.UUID) (time.Time, error) {
+	post, err := s.ownPost(database.WithPrimary(ctx), userUUID, postUUID)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return post.UpdatedAt, nil
+}
+
+// ownPost returns the post if the user is its author. Otherwise access is
+// denied as the visibility policy dictates; see deniedAccess.
+func (s *PostService) ownPost(ctx context.Context, userUUID, postUUID uuid.UUID) (*domain.PostWithAuthor, error) {
+	post, err := s.postRepo.GetByUUID(ctx, postUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	if post.Author.UUID != userUUID {
+		return nil, s.deniedAccess(post)
+	}
+
+	return post, nil
+}
+
+// deniedAccess returns the error for a user who may not modify a post. A
+// concealed post (see concealed) is reported as not found so its existence
+// isn't revealed; any other post is visible anyway, so ErrForbidden is
+// returned.
+func (s *PostService) deniedAccess(post *domain.PostWithAuthor) error {
+	if s.concealed(post) {
+		return domain.ErrPostNotFound
+	}
+	return domain.ErrForbidden
+}
+
+// concealed reports whether only the post's author and admins may see it:
+// private posts always are, and unpublished posts are with
+// ConcealUnpublished set. Unlisted posts are readable by anyone with a link.
+func (s *PostService) concealed(post *domain.PostWithAuthor) bool {
+	if post.Visibility == domain.PostVisibilityPrivate {
+		return true
+	}
+	return s.postsCfg.ConcealUnpublished && post.Status != domain.PostStatusPublished
+}
+
+// checkVisible reports ErrPostNotFound for a concealed post, unless the
+// viewer is its author or an admin
+func (s *PostService) checkVisible(ctx context.Context, post *domain.PostWithAuthor, viewerUUID *uuid.UUID) error {
+	if !s.concealed(post) {
+		return nil
+	}
+
+	if viewerUUID == nil {
+		return domain.ErrPostNotFound
+	}
+	if post.Author.UUID == *viewerUUID {
+		return nil
+	}
+
+	viewer, err := s.userRepo.GetByUUID(ctx, *viewerUUID)
+	if errors.Is(err, domain.ErrUserNotFound) {
+		return domain.ErrPostNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if viewer.Role != domain.RoleAdmin {
+		return domain.ErrPostNotFound
+	}
+
+	return nil
+}
+
+// checkInteractable reports ErrPostNotFound unless the post is published and
+// visible to the user, so readers can't bookmark, or record reading, a post
+// they couldn't open
+func (s *PostService) checkInteractable(ctx context.Context, post *domain.PostWithAuthor, userUUID uuid.UUID) error {
+	if post.Status != domain.PostStatusPublished {
+		return domain.ErrPostNotFound
+	}
+	return s.checkVisible(ctx, post, &userUUID)
+}
+
+// GetByUUID retrieves a post by UUID. viewerUUID is nil for anonymous requests.
+func (s *PostService) GetByUUID(ctx context.Context, postUUID uuid.UUID, viewerUUID *uuid.UUID) (*domain.PostResponse, error) {
 	post, err := s.postRepo.GetByUUID(ctx, postUUID)
 	if err != nil {
 		return nil, err
 	}
 
+	if err := s.checkVisible(ctx, post, viewerUUID); err != nil {
+		return nil, err
+	}
+
+	state, err := s.getViewerState(ctx, viewerUUID, []int{post.ID})
+	if err != nil {
+		return nil, err
+	}
+
+	seriesInfo, err := s.seriesRepo.GetPostSeriesInfo(ctx, post.ID)
+	if err != nil {
+		return nil, err
+	}
+
 	return &domain.PostResponse{
-		UUID:        post.UUID,
-		Title:       post.Title,
-		Slug:        post.Slug,
-		Content:     post.Content,
-		Excerpt:     post.Excerpt,
-		Status:      post.Status,
-		PublishedAt: post.PublishedAt,
-		CreatedAt:   post.CreatedAt,
-		UpdatedAt:   post.UpdatedAt,
-		Author:      post.Author,
+		UUID:           post.UUID,
+		Title:          post.Title,
+		Slug:           post.Slug,
+		Content:        post.Content,
+		Excerpt:        post.Excerpt,
+		Format:         post.Format,
+		Visibility:     post.Visibility,
+		Status:         post.Status,
+		PublishedAt:    post.PublishedAt,
+		CreatedAt:      post.CreatedAt,
+		UpdatedAt:      post.UpdatedAt,
+		Author:         post.Author,
+		ReadByMe:       state.read[post.ID],
+		BookmarkedByMe: state.bookmarked[post.ID],
+		Series:         seriesInfo,
 	}, nil
 }
 
-// GetBySlug retrieves a post by slug
-func (s *PostService) GetBySlug(ctx context.Context, slug string) (*domain.PostResponse, error) {
+// GetBySlug retrieves a post by slug. viewerUUID is nil for anonymous requests.
+func (s *PostService) GetBySlug(ctx context.Context, slug string, viewerUUID *uuid.UUID) (*domain.PostResponse, error) {
 	post, err := s.postRepo.GetBySlug(ctx, slug)
 	if err != nil {
 		return nil, err
 	}
 
+	if err := s.checkVisible(ctx, post, viewerUUID); err != nil {
+		return nil, err
+	}
+
+	state, err := s.getViewerState(ctx, viewerUUID, []int{post.ID})
+	if err != nil {
+		return nil, err
+	}
+
+	seriesInfo, err := s.seriesRepo.GetPostSeriesInfo(ctx, post.ID)
+	if err != nil {
+		return nil, err
+	}
+
 	return &domain.PostResponse{
-		UUID:        post.UUID,
-		Title:       post.Title,
-		Slug:        post.Slug,
-		Content:     post.Content,
-		Excerpt:     post.Excerpt,
-		Status:      post.Status,
-		PublishedAt: post.PublishedAt,
-		CreatedAt:   post.CreatedAt,
-		UpdatedAt:   post.UpdatedAt,
-		Author:      post.Author,
+		UUID:           post.UUID,
+		Title:          post.Title,
+		Slug:           post.Slug,
+		Content:        post.Content,
+		Excerpt:        post.Excerpt,
+		Format:         post.Format,
+		Visibility:     post.Visibility,
+		Status:         post.Status,
+		PublishedAt:    post.PublishedAt,
+		CreatedAt:      post.CreatedAt,
+		UpdatedAt:      post.UpdatedAt,
+		Author:         post.Author,
+		ReadByMe:       state.read[post.ID],
+		BookmarkedByMe: state.bookmarked[post.ID],
+		Series:         seriesInfo,
 	}, nil
 }
 
-// List retrieves posts with filters and pagination
-func (s *PostService) List(ctx context.Context, req domain.ListPostsRequest) (*domain.ListPostsResponse, error) {
+// List retrieves posts with filters and pagination. viewerUUID is nil for anonymous requests.
+func (s *PostService) List(ctx context.Context, req domain.ListPostsRequest, viewerUUID *uuid.UUID) (*domain.ListPostsResponse, error) {
 	// Set defaults
 	if req.Page == 0 {
 		req.Page = 1
@@ -133,26 +391,109 @@ func (s *PostService) List(ctx context.Context, req domain.ListPostsRequest) (*d
 	if req.Limit == 0 {
 		req.Limit = 10
 	}
+	if req.Status == nil && s.postsCfg.DefaultPublishedOnly {
+		published := domain.PostStatusPublished
+		req.Status = &published
+	}
+	if req.Status == nil || *req.Status != domain.PostStatusPublished {
+		author, err := s.unpublishedAuthor(ctx, viewerUUID)
+		if err != nil {
+			return nil, err
+		}
+		req.UnpublishedAuthor = author
+	}
+
+	// Only anonymous, published-only listings are cached, since they carry
+	// no per-viewer state
+	if viewerUUID == nil && req.Status != nil && *req.Status == domain.PostStatusPublished {
+		resp, err := s.listCache.GetOrLoad(listCacheKey(req), func() (*domain.ListPostsResponse, error) {
+			// The load is shared by concurrent callers, so one caller
+			// going away mustn't cancel it for the rest
+			return s.list(context.WithoutCancel(ctx), req, nil)
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		// Callers may set fields on the response, so hand out a copy
+		cached := *resp
+		return &cached, nil
+	}
+
+	return s.list(ctx, req, viewerUUID)
+}
+
+// unpublishedAuthor returns whose draft and archived posts the viewer may
+// list, following checkVisible: with ConcealUnpublished set, a user sees only
+// their own, anonymous and unknown viewers none, and admins everyone's (nil).
+func (s *PostService) unpublishedAuthor(ctx context.Context, viewerUUID *uuid.UUID) (*uuid.UUID, error) {
+	if !s.postsCfg.ConcealUnpublished {
+		return nil, nil
+	}
+
+	none := uuid.Nil
+	if viewerUUID == nil {
+		return &none, nil
+	}
+
+	viewer, err := s.userRepo.GetByUUID(ctx, *viewerUUID)
+	if errors.Is(err, domain.ErrUserNotFound) {
+		return &none, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if viewer.Role == domain.RoleAdmin {
+		return nil, nil
+	}
+
+	return viewerUUID, nil
+}
+
+// listCacheKey identifies a listing by its full filter set
+func listCacheKey(req domain.ListPostsRequest) string {
+	author := ""
+	if req.AuthorID != nil {
+		author = req.AuthorID.String()
+	}
+	return fmt.Sprintf("status=%s&author=%s&page=%d&limit=%d", *req.Status, author, req.Page, req.Limit)
+}
 
+func (s *PostService) list(ctx context.Context, req domain.ListPostsRequest, viewerUUID *uuid.UUID) (*domain.ListPostsResponse, error) {
 	posts, totalCount, err := s.postRepo.List(ctx, req)
 	if err != nil {
 		return nil, err
 	}
 
+	// Look up per-viewer state for the whole page at once
+	postIDs := make([]int, len(posts))
+	for i, post := range posts {
+		postIDs[i] = post.ID
+	}
+
+	state, err := s.getViewerState(ctx, viewerUUID, postIDs)
+	if err != nil {
+		return nil, err
+	}
+
 	// Convert to response format
 	postResponses := make([]domain.PostResponse, len(posts))
 	for i, post := range posts {
 		postResponses[i] = domain.PostResponse{
-			UUID:        post.UUID,
-			Title:       post.Title,
-			Slug:        post.Slug,
-			Content:     post.Content,
-			Excerpt:     post.Excerpt,
-			Status:      post.Status,
-			PublishedAt: post.PublishedAt,
-			CreatedAt:   post.CreatedAt,
-			UpdatedAt:   post.UpdatedAt,
-			Author:      post.Author,
+			UUID:           post.UUID,
+			Title:          post.Title,
+			Slug:           post.Slug,
+			Content:        post.Content,
+			Excerpt:        post.Excerpt,
+			Format:         post.Format,
+			Visibility:     post.Visibility,
+			Status:         post.Status,
+			PublishedAt:    post.PublishedAt,
+			CreatedAt:      post.CreatedAt,
+			UpdatedAt:      post.UpdatedAt,
+			Author:         post.Author,
+			ReadByMe:       state.read[post.ID],
+			BookmarkedByMe: state.bookmarked[post.ID],
 		}
 	}
 
@@ -166,19 +507,23 @@ func (s *PostService) List(ctx context.Context, req domain.ListPostsRequest) (*d
 
 // Update updates a post
 func (s *PostService) Update(ctx context.Context, userUUID uuid.UUID, postUUID uuid.UUID, req domain.UpdatePostRequest) (*domain.PostResponse, error) {
-	// Get user by UUID
-	user, err := s.userRepo.GetByUUID(ctx, userUUID)
+	// Read from the primary so the returned post reflects this write
+	ctx = database.WithPrimary(ctx)
+
+	currentPost, err := s.ownPost(ctx, userUUID, postUUID)
 	if err != nil {
 		return nil, err
 	}
 
-	// Check if user is the author
-	isAuthor, err := s.postRepo.IsAuthor(ctx, postUUID, user.ID)
-	if err != nil {
-		return nil, err
+	// Drafts may be exempt from the content minimum, so work out whether the
+	// post will be a draft once updated
+	isDraft := currentPost.Status == domain.PostStatusDraft
+	if req.Status != nil {
+		isDraft = *req.Status == domain.PostStatusDraft
 	}
-	if !isAuthor {
-		return nil, domain.ErrForbidden
+
+	if err := s.checkContentPolicy(req.Title, req.Content, req.Excerpt, isDraft); err != nil {
+		return nil, err
 	}
 
 	// Build updates map
@@ -186,24 +531,27 @@ func (s *PostService) Update(ctx context.Context, userUUID uuid.UUID, postUUID u
 
 	if req.Title != nil {
 		updates["title"] = *req.Title
-		updates["slug"] = slug.Generate(*req.Title)
+		updates["slug"] = slug.GenerateWithMaxLength(*req.Title, s.slugMaxLength)
 	}
 
 	if req.Content != nil {
-		updates["content"] = *req.Content
+		updates["content"] = s.sanitize(*req.Content)
 	}
 
 	if req.Excerpt != nil {
-		updates["excerpt"] = *req.Excerpt
+		updates["excerpt"] = s.sanitize(*req.Excerpt)
 	}
 
-	if req.Status != nil {
-		// Get current post to check status transitions
-		currentPost, err := s.postRepo.GetByUUID(ctx, postUUID)
-		if err != nil {
-			return nil, err
-		}
+	if req.Format != nil {
+		updates["format"] = *req.Format
+	}
+
+	if req.Visibility != nil {
+		updates["visibility"] = *req.Visibility
+	}
 
+	publish := false
+	if req.Status != nil {
 		// Handle publish status change via queue
 		if *req.Status == domain.PostStatusPublished {
 			// Check if already published
@@ -211,37 +559,15 @@ func (s *PostService) Update(ctx context.Context, userUUID uuid.UUID, postUUID u
 				return nil, domain.ErrPostAlreadyPublished
 			}
 
-			// Enqueue publish event
-			event := &domain.PostPublishEvent{
-				PostUUID:     postUUID.String(),
-				AuthorUUID:   userUUID.String(),
-				RequestedAt:  time.Now(),
-				ScheduledFor: req.ScheduledFor,
-			}
-
-			if err := s.postPublisher.PublishPostPublishEvent(ctx, event); err != nil {
+			// The gates apply to the post as it will be once this request's
+			// field changes are saved
+			content, excerpt := publishFields(&currentPost.Post, req)
+			if err := s.validatePublishable(content, excerpt); err != nil {
 				return nil, err
 			}
 
 			// Don't update status directly - worker will handle it
-			// Return current post state
-			post, err := s.postRepo.GetByUUID(ctx, postUUID)
-			if err != nil {
-				return nil, err
-			}
-
-			return &domain.PostResponse{
-				UUID:        post.UUID,
-				Title:       post.Title,
-				Slug:        post.Slug,
-				Content:     post.Content,
-				Excerpt:     post.Excerpt,
-				Status:      post.Status,
-				PublishedAt: post.PublishedAt,
-				CreatedAt:   post.CreatedAt,
-				UpdatedAt:   post.UpdatedAt,
-				Author:      post.Author,
-			}, nil
+			publish = true
 		} else {
 			// Validate status transitions
 			if err := s.validateStatusChange(currentPost.Status, *req.Status); err != nil {
@@ -258,9 +584,27 @@ func (s *PostService) Update(ctx context.Context, userUUID uuid.UUID, postUUID u
 	}
 
 	// Update post
-	updatedPost, err := s.postRepo.Update(ctx, postUUID, updates)
-	if err != nil {
-		return nil, err
+	if len(updates) > 0 {
+		if _, err := s.postRepo.Update(ctx, postUUID, updates); err != nil {
+			return nil, err
+		}
+		s.listCache.Invalidate()
+	}
+
+	// Enqueue the publish event once the field changes are saved, so the
+	// worker publishes the updated post
+	if publish {
+		event := &domain.PostPublishEvent{
+			EventID:      uuid.New().String(),
+			PostUUID:     postUUID.String(),
+			AuthorUUID:   userUUID.String(),
+			RequestedAt:  time.Now(),
+			ScheduledFor: req.ScheduledFor,
+		}
+
+		if err := s.postPublisher.PublishPostPublishEvent(ctx, event); err != nil {
+			return nil, err
+		}
 	}
 
 	// Get full post with author info
@@ -275,58 +619,639 @@ func (s *PostService) Update(ctx context.Context, userUUID uuid.UUID, postUUID u
 		Slug:        post.Slug,
 		Content:     post.Content,
 		Excerpt:     post.Excerpt,
+		Format:      post.Format,
+		Visibility:  post.Visibility,
 		Status:      post.Status,
 		PublishedAt: post.PublishedAt,
 		CreatedAt:   post.CreatedAt,
-		UpdatedAt:   updatedPost.UpdatedAt,
+		UpdatedAt:   post.UpdatedAt,
 		Author:      post.Author,
 	}, nil
 }
 
-// validateStatusChange validates if a status transition is allowed
-func (s *PostService) validateStatusChange(currentStatus, newStatus domain.PostStatus) error {
-	// Allow transitions to the same status (no-op)
-	if currentStatus == newStatus {
-		return nil
-	}
+// ForcePublish publishes a post immediately, bypassing the publish queue,
+// author check and publish gates. It is an admin override. It returns the
+// post's previous status.
+func (s *PostService) ForcePublish(ctx context.Context, postUUID uuid.UUID) (*domain.PostResponse, domain.PostStatus, error) {
+	return s.forceStatus(ctx, postUUID, domain.PostStatusPublished)
+}
 
-	// Define allowed transitions
-	allowedTransitions := map[domain.PostStatus][]domain.PostStatus{
-		domain.PostStatusDraft:     {domain.PostStatusPublished, domain.PostStatusArchived},
-		domain.PostStatusPublished: {domain.PostStatusDraft, domain.PostStatusArchived},
-		domain.PostStatusArchived:  {domain.PostStatusDraft},
+// ForceUnpublish moves a published post back to draft. It is an admin
+// override. It returns the post's previous status.
+func (s *PostService) ForceUnpublish(ctx context.Context, postUUID uuid.UUID) (*domain.PostResponse, domain.PostStatus, error) {
+	return s.forceStatus(ctx, postUUID, domain.PostStatusDraft)
+}
+
+func (s *PostService) forceStatus(ctx context.Context, postUUID uuid.UUID, status domain.PostStatus) (*domain.PostResponse, domain.PostStatus, error) {
+	// Read from the primary so the returned post reflects this write
+	ctx = database.WithPrimary(ctx)
+
+	currentPost, err := s.postRepo.GetByUUID(ctx, postUUID)
+	if err != nil {
+		return nil, "", err
 	}
 
-	allowed, exists := allowedTransitions[currentStatus]
-	if !exists {
-		return domain.ErrInvalidStatusChange
+	switch {
+	case status == domain.PostStatusPublished && currentPost.Status == domain.PostStatusPublished:
+		return nil, "", domain.ErrPostAlreadyPublished
+	case status == domain.PostStatusDraft && currentPost.Status != domain.PostStatusPublished:
+		return nil, "", domain.ErrInvalidStatusChange
 	}
 
-	for _, allowedStatus := range allowed {
-		if allowedStatus == newStatus {
-			return nil
-		}
+	if err := s.validateStatusChange(currentPost.Status, status); err != nil {
+		return nil, "", err
 	}
 
-	return domain.ErrInvalidStatusChange
-}
+	updates := map[string]interface{}{"status": status}
+	if status == domain.PostStatusPublished {
+		updates["published_at"] = time.Now()
+	} else {
+		updates["published_at"] = nil
+	}
+
+	updatedPost, err := s.postRepo.Update(ctx, postUUID, updates)
+	if err != nil {
+		return nil, "", err
+	}
+	s.listCache.Invalidate()
+
+	if status == domain.PostStatusPublished {
+		s.announcePublished(ctx, currentPost, updatedPost)
+	}
+
+	return &domain.PostResponse{
+		UUID:        updatedPost.UUID,
+		Title:       updatedPost.Title,
+		Slug:        updatedPost.Slug,
+		Content:     updatedPost.Content,
+		Excerpt:     updatedPost.Excerpt,
+		Format:      updatedPost.Format,
+		Visibility:  updatedPost.Visibility,
+		Status:      updatedPost.Status,
+		PublishedAt: updatedPost.PublishedAt,
+		CreatedAt:   updatedPost.CreatedAt,
+		UpdatedAt:   updatedPost.UpdatedAt,
+		Author:      currentPost.Author,
+	}, currentPost.Status, nil
+}
+
+// announcePublished does what the publish worker does once a post is
+// published: public posts are pushed to live subscribers and the author gets
+// a notification. The post is already published, so a failed notification
+// is not reported.
+func (s *PostService) announcePublished(ctx context.Context, post *domain.PostWithAuthor, published *domain.Post) {
+	notification := domain.PostPublishedNotification{
+		PostUUID:   published.UUID,
+		AuthorUUID: post.Author.UUID,
+		Title:      published.Title,
+		Slug:       published.Slug,
+	}
+	if published.PublishedAt != nil {
+		notification.PublishedAt = *published.PublishedAt
+	}
+
+	if published.Visibility == domain.PostVisibilityPublic {
+		s.broker.Publish(ctx, notification)
+	}
+
+	author := &domain.User{ID: post.AuthorID, UUID: post.Author.UUID}
+	_ = s.notifier.Notify(ctx, author, domain.NotificationPostPublished, notification)
+}
+
+// TransferOwnership makes another user the author of a post. The new author
+// must exist and be active. It is an admin action.
+func (s *PostService) TransferOwnership(ctx context.Context, postUUID, newAuthorUUID uuid.UUID) (*domain.TransferPostResponse, error) {
+	// Read from the primary so the returned post reflects this write
+	ctx = database.WithPrimary(ctx)
+
+	currentPost, err := s.postRepo.GetByUUID(ctx, postUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	newAuthor, err := s.userRepo.GetByUUID(ctx, newAuthorUUID)
+	if err != nil {
+		return nil, err
+	}
+	if !newAuthor.IsActive {
+		return nil, domain.ErrUserInactive
+	}
+
+	if err := s.postRepo.TransferOwnership(ctx, postUUID, newAuthor.ID); err != nil {
+		return nil, err
+	}
+	s.listCache.Invalidate()
+
+	post, err := s.postRepo.GetByUUID(ctx, postUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.TransferPostResponse{
+		Post: domain.PostResponse{
+			UUID:        post.UUID,
+			Title:       post.Title,
+			Slug:        post.Slug,
+			Content:     post.Content,
+			Excerpt:     post.Excerpt,
+			Format:      post.Format,
+			Visibility:  post.Visibility,
+			Status:      post.Status,
+			PublishedAt: post.PublishedAt,
+			CreatedAt:   post.CreatedAt,
+			UpdatedAt:   post.UpdatedAt,
+			Author:      post.Author,
+		},
+		PreviousAuthor: currentPost.Author,
+	}, nil
+}
+
+// AttachTOC sets the table of contents of a Markdown post from its headings.
+// Other formats are left without one.
+func (s *PostService) AttachTOC(post *domain.PostResponse) {
+	if post.Format != domain.PostFormatMarkdown {
+		return
+	}
+
+	entries := toc.Extract(post.Content)
+	post.TOC = make([]domain.TOCEntry, len(entries))
+	for i, entry := range entries {
+		post.TOC[i] = domain.TOCEntry{
+			Level:  entry.Level,
+			Text:   entry.Text,
+			Anchor: entry.Anchor,
+		}
+	}
+}
+
+// withUniqueSlug calls create with base as the slug and, on a collision,
+// retries with "-2", "-3", ... up to the configured limit, after which
+// ErrSlugTaken is returned. The unique constraint decides, so concurrent
+// creates can't both claim a slug.
+func (s *PostService) withUniqueSlug(base string, create func(slug string) error) error {
+	for attempt := 0; ; attempt++ {
+		err := create(s.slugCandidate(base, attempt))
+		if errors.Is(err, domain.ErrSlugTaken) && attempt < s.postsCfg.SlugMaxRetries {
+			continue
+		}
+		return err
+	}
+}
+
+// slugCandidate returns the slug to try on the given attempt: the base slug
+// first, then the base with a numeric suffix, shortened to keep within the
+// maximum length
+func (s *PostService) slugCandidate(base string, attempt int) string {
+	if attempt == 0 {
+		return base
+	}
+
+	suffix := "-" + strconv.Itoa(attempt+1)
+	return slug.Truncate(base, s.slugMaxLength-len(suffix)) + suffix
+}
+
+// sanitize strips unsafe HTML from user content when sanitization is enabled
+func (s *PostService) sanitize(content string) string {
+	if !s.postsCfg.SanitizeHTML {
+		return content
+	}
+	return sanitize.HTML(content)
+}
+
+// checkContentPolicy checks field lengths against the content policy,
+// returning ErrInvalidPostContent with the failed checks listed, or
+// ErrContentTooLarge when content exceeds the size cap. Nil fields are not
+// checked. isDraft reports whether the post is, or will be, a draft.
+func (s *PostService) checkContentPolicy(title, content, excerpt *string, isDraft bool) error {
+	policy := s.postsCfg.Content
+	var problems []string
+
+	if content != nil && len(*content) > policy.MaxContentBytes {
+		return fmt.Errorf("%w: content must be at most %d bytes, got %d",
+			domain.ErrContentTooLarge, policy.MaxContentBytes, len(*content))
+	}
+
+	if title != nil {
+		length := utf8.RuneCountInString(*title)
+		if length < policy.MinTitleLength || length > policy.MaxTitleLength {
+			problems = append(problems, fmt.Sprintf("title must be between %d and %d characters",
+				policy.MinTitleLength, policy.MaxTitleLength))
+		}
+	}
+
+	if content != nil && !(isDraft && policy.DraftContentOptional) {
+		if utf8.RuneCountInString(*content) < policy.MinContentLength {
+			problems = append(problems, fmt.Sprintf("content must be at least %d characters", policy.MinContentLength))
+		}
+	}
+
+	if excerpt != nil && utf8.RuneCountInString(*excerpt) > policy.MaxExcerptLength {
+		problems = append(problems, fmt.Sprintf("excerpt must be at most %d characters", policy.MaxExcerptLength))
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("%w: %s", domain.ErrInvalidPostContent, strings.Join(problems, "; "))
+	}
+
+	return nil
+}
+
+// publishFields returns the content and excerpt post will have once the
+// fields set in req are applied
+func publishFields(post *domain.Post, req domain.UpdatePostRequest) (string, *string) {
+	content := post.Content
+	if req.Content != nil {
+		content = *req.Content
+	}
+
+	excerpt := post.Excerpt
+	if req.Excerpt != nil {
+		excerpt = req.Excerpt
+	}
+
+	return content, excerpt
+}
+
+// generateExcerpt builds an excerpt from content with the configured
+// strategy, cut to the maximum excerpt length so it passes the content
+// policy, now and when sent back on a later update
+func (s *PostService) generateExcerpt(content string) string {
+	generated := excerpt.Generate(content, s.postsCfg.ExcerptStrategy, s.postsCfg.ExcerptLength)
+	return excerpt.Truncate(generated, s.postsCfg.Content.MaxExcerptLength)
+}
+
+// validatePublishable checks the configured publish gates, returning
+// ErrPostNotReady with the failed checks listed
+func (s *PostService) validatePublishable(content string, excerpt *string) error {
+	policy := s.postsCfg.Content
+	var missing []string
+
+	if strings.TrimSpace(content) == "" {
+		missing = append(missing, "content is required")
+	}
+
+	if policy.PublishRequireExcerpt && (excerpt == nil || strings.TrimSpace(*excerpt) == "") {
+		missing = append(missing, "excerpt is required")
+	}
+
+	minLength := policy.PublishMinContentLength
+	if minLength > 0 && utf8.RuneCountInString(strings.TrimSpace(content)) < minLength {
+		missing = append(missing, fmt.Sprintf("content must be at least %d characters", minLength))
+	}
+
+	if len(missing) > 0 {
+		return fmt.Errorf("%w: %s", domain.ErrPostNotReady, strings.Join(missing, "; "))
+	}
+
+	return nil
+}
+
+// validateStatusChange validates if a status transition is allowed
+func (s *PostService) validateStatusChange(currentStatus, newStatus domain.PostStatus) error {
+	// Allow transitions to the same status (no-op)
+	if currentStatus == newStatus {
+		return nil
+	}
+
+	// Define allowed transitions
+	allowedTransitions := map[domain.PostStatus][]domain.PostStatus{
+		domain.PostStatusDraft:     {domain.PostStatusPublished, domain.PostStatusArchived},
+		domain.PostStatusPublished: {domain.PostStatusDraft, domain.PostStatusArchived},
+		domain.PostStatusArchived:  {domain.PostStatusDraft},
+	}
+
+	allowed, exists := allowedTransitions[currentStatus]
+	if !exists {
+		return domain.ErrInvalidStatusChange
+	}
+
+	for _, allowedStatus := range allowed {
+		if allowedStatus == newStatus {
+			return nil
+		}
+	}
+
+	return domain.ErrInvalidStatusChange
+}
 
 // Delete deletes a post
 func (s *PostService) Delete(ctx context.Context, userUUID uuid.UUID, postUUID uuid.UUID) error {
-	// Get user by UUID
+	if _, err := s.ownPost(database.WithPrimary(ctx), userUUID, postUUID); err != nil {
+		return err
+	}
+
+	if err := s.postRepo.Delete(ctx, postUUID); err != nil {
+		return err
+	}
+	s.listCache.Invalidate()
+
+	return nil
+}
+
+// MarkRead marks a published post as read by the user
+func (s *PostService) MarkRead(ctx context.Context, userUUID uuid.UUID, postUUID uuid.UUID) (*domain.MarkReadResponse, error) {
+	user, err := s.userRepo.GetByUUID(ctx, userUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	post, err := s.postRepo.GetByUUID(ctx, postUUID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkInteractable(ctx, post, userUUID); err != nil {
+		return nil, err
+	}
+
+	readAt, err := s.postRepo.MarkRead(ctx, user.ID, post.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.MarkReadResponse{
+		PostUUID: post.UUID,
+		ReadAt:   readAt,
+	}, nil
+}
+
+// SaveReadProgress stores how far the user has read a published post,
+// clamping percent to 0-100. Saving the same value again is harmless.
+func (s *PostService) SaveReadProgress(ctx context.Context, userUUID uuid.UUID, postUUID uuid.UUID, percent int) (*domain.ReadProgressResponse, error) {
+	user, err := s.userRepo.GetByUUID(ctx, userUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	post, err := s.postRepo.GetByUUID(ctx, postUUID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkInteractable(ctx, post, userUUID); err != nil {
+		return nil, err
+	}
+
+	percent = max(0, min(percent, 100))
+
+	updatedAt, err := s.postRepo.SaveReadProgress(ctx, user.ID, post.ID, percent)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.ReadProgressResponse{
+		PostUUID:  post.UUID,
+		Percent:   percent,
+		UpdatedAt: updatedAt,
+	}, nil
+}
+
+// ReadHistory lists the posts the user has recently read
+func (s *PostService) ReadHistory(ctx context.Context, userUUID uuid.UUID, req domain.ReadHistoryRequest) (*domain.ReadHistoryResponse, error) {
+	// Set defaults
+	if req.Page == 0 {
+		req.Page = 1
+	}
+	if req.Limit == 0 {
+		req.Limit = 10
+	}
+
+	user, err := s.userRepo.GetByUUID(ctx, userUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	posts, totalCount, err := s.postRepo.ListReadHistory(ctx, user.ID, req)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]domain.ReadHistoryEntry, len(posts))
+	for i, post := range posts {
+		entries[i] = domain.ReadHistoryEntry{
+			Post: domain.PostResponse{
+				UUID:        post.UUID,
+				Title:       post.Title,
+				Slug:        post.Slug,
+				Content:     post.Content,
+				Excerpt:     post.Excerpt,
+				Format:      post.Format,
+				Visibility:  post.Visibility,
+				Status:      post.Status,
+				PublishedAt: post.PublishedAt,
+				CreatedAt:   post.CreatedAt,
+				UpdatedAt:   post.UpdatedAt,
+				Author:      post.Author,
+				ReadByMe:    true,
+			},
+			ReadAt:   post.ReadAt,
+			Progress: post.Progress,
+		}
+	}
+
+	return &domain.ReadHistoryResponse{
+		Entries:    entries,
+		TotalCount: totalCount,
+		Page:       req.Page,
+		Limit:      req.Limit,
+	}, nil
+}
+
+// viewerState holds per-viewer flags for a set of posts, keyed by post ID
+type viewerState struct {
+	read       map[int]bool
+	bookmarked map[int]bool
+}
+
+// getViewerState looks up the viewer's read and bookmark state for the posts.
+// Anonymous viewers get empty state.
+func (s *PostService) getViewerState(ctx context.Context, viewerUUID *uuid.UUID, postIDs []int) (*viewerState, error) {
+	state := &viewerState{
+		read:       map[int]bool{},
+		bookmarked: map[int]bool{},
+	}
+
+	if viewerUUID == nil || len(postIDs) == 0 {
+		return state, nil
+	}
+
+	var err error
+	state.read, err = s.postRepo.ReadPostIDs(ctx, *viewerUUID, postIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	state.bookmarked, err = s.postRepo.BookmarkedPostIDs(ctx, *viewerUUID, postIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	return state, nil
+}
+
+// Interactions returns the user's interaction flags for each requested post
+// in one lookup, so list views don't need a request per post. Repeated IDs
+// are listed once.
+func (s *PostService) Interactions(ctx context.Context, userUUID uuid.UUID, req domain.PostInteractionsRequest) (*domain.PostInteractionsResponse, error) {
+	found, err := s.postRepo.Interactions(ctx, userUUID, req.PostIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[uuid.UUID]bool, len(req.PostIDs))
+	interactions := make([]domain.PostInteraction, 0, len(req.PostIDs))
+	for _, postUUID := range req.PostIDs {
+		if seen[postUUID] {
+			continue
+		}
+		seen[postUUID] = true
+
+		interaction, ok := found[postUUID]
+		if !ok {
+			interaction = domain.PostInteraction{PostUUID: postUUID}
+		}
+		interactions = append(interactions, interaction)
+	}
+
+	return &domain.PostInteractionsResponse{Interactions: interactions}, nil
+}
+
+// Bookmark saves a published post for the user. Bookmarking is idempotent.
+func (s *PostService) Bookmark(ctx context.Context, userUUID uuid.UUID, postUUID uuid.UUID) error {
+	user, err := s.userRepo.GetByUUID(ctx, userUUID)
+	if err != nil {
+		return err
+	}
+
+	post, err := s.postRepo.GetByUUID(ctx, postUUID)
+	if err != nil {
+		return err
+	}
+	if err := s.checkInteractable(ctx, post, userUUID); err != nil {
+		return err
+	}
+
+	return s.postRepo.AddBookmark(ctx, user.ID, post.ID)
+}
+
+// RemoveBookmark removes a post from the user's bookmarks. Removing a missing bookmark is a no-op.
+func (s *PostService) RemoveBookmark(ctx context.Context, userUUID uuid.UUID, postUUID uuid.UUID) error {
 	user, err := s.userRepo.GetByUUID(ctx, userUUID)
 	if err != nil {
 		return err
 	}
 
-	// Check if user is the author
-	isAuthor, err := s.postRepo.IsAuthor(ctx, postUUID, user.ID)
+	post, err := s.postRepo.GetByUUID(ctx, postUUID)
 	if err != nil {
 		return err
 	}
-	if !isAuthor {
-		return domain.ErrForbidden
+
+	return s.postRepo.RemoveBookmark(ctx, user.ID, post.ID)
+}
+
+// ListBookmarks lists the user's bookmarked posts that are still published
+func (s *PostService) ListBookmarks(ctx context.Context, userUUID uuid.UUID, req domain.ListBookmarksRequest) (*domain.ListBookmarksResponse, error) {
+	// Set defaults
+	if req.Page == 0 {
+		req.Page = 1
+	}
+	if req.Limit == 0 {
+		req.Limit = 10
+	}
+
+	user, err := s.userRepo.GetByUUID(ctx, userUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	posts, totalCount, err := s.postRepo.ListBookmarks(ctx, user.ID, req)
+	if err != nil {
+		return nil, err
+	}
+
+	bookmarks := make([]domain.BookmarkResponse, len(posts))
+	for i, post := range posts {
+		bookmarks[i] = domain.BookmarkResponse{
+			Post: domain.PostResponse{
+				UUID:           post.UUID,
+				Title:          post.Title,
+				Slug:           post.Slug,
+				Content:        post.Content,
+				Excerpt:        post.Excerpt,
+				Format:         post.Format,
+				Visibility:     post.Visibility,
+				Status:         post.Status,
+				PublishedAt:    post.PublishedAt,
+				CreatedAt:      post.CreatedAt,
+				UpdatedAt:      post.UpdatedAt,
+				Author:         post.Author,
+				BookmarkedByMe: true,
+			},
+			BookmarkedAt: post.BookmarkedAt,
+		}
+	}
+
+	return &domain.ListBookmarksResponse{
+		Bookmarks:  bookmarks,
+		TotalCount: totalCount,
+		Page:       req.Page,
+		Limit:      req.Limit,
+	}, nil
+}
+
+// ListGrouped returns the user's own posts grouped by status, capped per
+// status at the configured bucket size
+func (s *PostService) ListGrouped(ctx context.Context, userUUID uuid.UUID) (*domain.GroupedPostsResponse, error) {
+	user, err := s.userRepo.GetByUUID(ctx, userUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	posts, counts, err := s.postRepo.ListGroupedByStatus(ctx, user.ID, s.postsCfg.GroupedBucketSize)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &domain.GroupedPostsResponse{
+		Draft:     domain.PostBucket{Posts: []domain.PostResponse{}, TotalCount: counts[domain.PostStatusDraft]},
+		Published: domain.PostBucket{Posts: []domain.PostResponse{}, TotalCount: counts[domain.PostStatusPublished]},
+		Archived:  domain.PostBucket{Posts: []domain.PostResponse{}, TotalCount: counts[domain.PostStatusArchived]},
+	}
+
+	buckets := map[domain.PostStatus]*domain.PostBucket{
+		domain.PostStatusDraft:     &resp.Draft,
+		domain.PostStatusPublished: &resp.Published,
+		domain.PostStatusArchived:  &resp.Archived,
+	}
+
+	for _, post := range posts {
+		bucket, ok := buckets[post.Status]
+		if !ok {
+			continue
+		}
+		bucket.Posts = append(bucket.Posts, domain.PostResponse{
+			UUID:        post.UUID,
+			Title:       post.Title,
+			Slug:        post.Slug,
+			Content:     post.Content,
+			Excerpt:     post.Excerpt,
+			Format:      post.Format,
+			Visibility:  post.Visibility,
+			Status:      post.Status,
+			PublishedAt: post.PublishedAt,
+			CreatedAt:   post.CreatedAt,
+			UpdatedAt:   post.UpdatedAt,
+			Author:      post.Author,
+		})
+	}
+
+	return resp, nil
+}
+
+// AuthorStats returns dashboard statistics for the user's own posts
+func (s *PostService) AuthorStats(ctx context.Context, userUUID uuid.UUID) (*domain.AuthorStatsResponse, error) {
+	user, err := s.userRepo.GetByUUID(ctx, userUUID)
+	if err != nil {
+		return nil, err
 	}
 
-	return s.postRepo.Delete(ctx, postUUID)
+	return s.postRepo.AuthorStats(ctx, user.ID)
 }
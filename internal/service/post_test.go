@@ -0,0 +1,528 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/saimonsiddique/blog-api/internal/config"
+	"github.com/saimonsiddique/blog-api/internal/database/dbtest"
+	"github.com/saimonsiddique/blog-api/internal/domain"
+	"github.com/saimonsiddique/blog-api/internal/pkg/excerpt"
+	"github.com/saimonsiddique/blog-api/internal/repository"
+)
+
+func strPtr(s string) *string {
+	return &s
+}
+
+func newTestPostService(policy config.ContentPolicy) *PostService {
+	return &PostService{postsCfg: &config.PostsConfig{Content: policy}}
+}
+
+func testContentPolicy() config.ContentPolicy {
+	return config.ContentPolicy{
+		MinTitleLength:          3,
+		MaxTitleLength:          20,
+		MinContentLength:        10,
+		DraftContentOptional:    true,
+		PublishMinContentLength: 10,
+		MaxExcerptLength:        30,
+		MaxContentBytes:         100,
+	}
+}
+
+func TestCheckContentPolicyDraftWithNoContent(t *testing.T) {
+	tests := []struct {
+		name     string
+		optional bool
+		isDraft  bool
+		wantErr  bool
+	}{
+		{name: "draft when optional", optional: true, isDraft: true},
+		{name: "draft when required", optional: false, isDraft: true, wantErr: true},
+		{name: "published when optional", optional: true, isDraft: false, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy := testContentPolicy()
+			policy.DraftContentOptional = tt.optional
+			s := newTestPostService(policy)
+
+			err := s.checkContentPolicy(strPtr("Quick idea"), strPtr(""), nil, tt.isDraft)
+			if tt.wantErr != (err != nil) {
+				t.Fatalf("checkContentPolicy() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, domain.ErrInvalidPostContent) {
+				t.Errorf("checkContentPolicy() error = %v, want ErrInvalidPostContent", err)
+			}
+		})
+	}
+}
+
+func TestValidatePublishable(t *testing.T) {
+	tests := []struct {
+		name           string
+		requireExcerpt bool
+		minLength      int
+		content        string
+		excerpt        *string
+		wantErr        bool
+	}{
+		{name: "ready", minLength: 10, content: "long enough content"},
+		{name: "empty content", content: "", wantErr: true},
+		{name: "blank content", content: "   \n\t", wantErr: true},
+		{name: "empty content without minimum", minLength: 0, content: "", wantErr: true},
+		{name: "short content", minLength: 10, content: "too short", wantErr: true},
+		{name: "length counts characters", minLength: 4, content: "日本語です"},
+		{name: "surrounding space not counted", minLength: 10, content: "  short  ", wantErr: true},
+		{name: "excerpt required and missing", requireExcerpt: true, content: "content", wantErr: true},
+		{name: "excerpt required and blank", requireExcerpt: true, content: "content", excerpt: strPtr("  "), wantErr: true},
+		{name: "excerpt required and set", requireExcerpt: true, content: "content", excerpt: strPtr("summary")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy := testContentPolicy()
+			policy.PublishRequireExcerpt = tt.requireExcerpt
+			policy.PublishMinContentLength = tt.minLength
+			s := newTestPostService(policy)
+
+			err := s.validatePublishable(tt.content, tt.excerpt)
+			if tt.wantErr != (err != nil) {
+				t.Fatalf("validatePublishable() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, domain.ErrPostNotReady) {
+				t.Errorf("validatePublishable() error = %v, want ErrPostNotReady", err)
+			}
+		})
+	}
+}
+
+// An update that publishes a post is gated on the post as it will be once the
+// update's own field changes are saved, not on the stored post
+func TestValidatePublishableWithUpdate(t *testing.T) {
+	published := domain.PostStatusPublished
+	stored := &domain.Post{Content: "", Excerpt: nil}
+
+	tests := []struct {
+		name    string
+		stored  *domain.Post
+		req     domain.UpdatePostRequest
+		wantErr bool
+	}{
+		{
+			name:   "content and excerpt set in the same request",
+			stored: stored,
+			req: domain.UpdatePostRequest{
+				Content: strPtr("content written just now"),
+				Excerpt: strPtr("summary"),
+				Status:  &published,
+			},
+		},
+		{
+			name:    "stored draft has no content",
+			stored:  stored,
+			req:     domain.UpdatePostRequest{Status: &published},
+			wantErr: true,
+		},
+		{
+			name:    "excerpt set but content still empty",
+			stored:  stored,
+			req:     domain.UpdatePostRequest{Excerpt: strPtr("summary"), Status: &published},
+			wantErr: true,
+		},
+		{
+			name:   "stored content kept when the request omits it",
+			stored: &domain.Post{Content: "stored content is fine", Excerpt: strPtr("summary")},
+			req:    domain.UpdatePostRequest{Status: &published},
+		},
+		{
+			name:    "request clears stored content",
+			stored:  &domain.Post{Content: "stored content is fine", Excerpt: strPtr("summary")},
+			req:     domain.UpdatePostRequest{Content: strPtr(""), Status: &published},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy := testContentPolicy()
+			policy.PublishRequireExcerpt = true
+			s := newTestPostService(policy)
+
+			content, excerpt := publishFields(tt.stored, tt.req)
+			err := s.validatePublishable(content, excerpt)
+			if tt.wantErr != (err != nil) {
+				t.Fatalf("validatePublishable() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSlugCandidate(t *testing.T) {
+	s := &PostService{slugMaxLength: 20}
+
+	tests := []struct {
+		name    string
+		base    string
+		attempt int
+		want    string
+	}{
+		{name: "first attempt uses the base", base: "hello-world", attempt: 0, want: "hello-world"},
+		{name: "retry adds a suffix", base: "hello-world", attempt: 1, want: "hello-world-2"},
+		{name: "suffix counts up", base: "hello-world", attempt: 4, want: "hello-world-5"},
+		{name: "base shortened to fit the suffix", base: "hello-wonderful-world", attempt: 1, want: "hello-wonderful-2"},
+		{name: "longer suffix", base: "hello-wonderful-world", attempt: 9, want: "hello-wonderful-10"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := s.slugCandidate(tt.base, tt.attempt)
+			if got != tt.want {
+				t.Errorf("slugCandidate(%q, %d) = %q, want %q", tt.base, tt.attempt, got, tt.want)
+			}
+			if len(got) > s.slugMaxLength {
+				t.Errorf("len(%q) = %d, want at most %d", got, len(got), s.slugMaxLength)
+			}
+		})
+	}
+}
+
+func TestCheckContentPolicy(t *testing.T) {
+	tests := []struct {
+		name    string
+		title   *string
+		content *string
+		excerpt *string
+		isDraft bool
+		wantErr error
+	}{
+		{name: "all fields valid", title: strPtr("Hello"), content: strPtr("long enough content"), excerpt: strPtr("summary")},
+		{name: "nil fields not checked", title: nil, content: nil, excerpt: nil},
+		{name: "title too short", title: strPtr("Hi"), wantErr: domain.ErrInvalidPostContent},
+		{name: "title at minimum", title: strPtr("Hey")},
+		{name: "title too long", title: strPtr(strings.Repeat("a", 21)), wantErr: domain.ErrInvalidPostContent},
+		{name: "title at maximum", title: strPtr(strings.Repeat("a", 20))},
+		{name: "title counts characters", title: strPtr(strings.Repeat("é", 20))},
+		{name: "content too short", content: strPtr("too short"), wantErr: domain.ErrInvalidPostContent},
+		{name: "content at minimum", content: strPtr("ten chars!")},
+		{name: "short content allowed in drafts", content: strPtr("short"), isDraft: true},
+		{name: "content at byte limit", content: strPtr(strings.Repeat("a", 100))},
+		{name: "content just over byte limit", content: strPtr(strings.Repeat("a", 101)), wantErr: domain.ErrContentTooLarge},
+		{name: "content limit counts bytes", content: strPtr(strings.Repeat("é", 51)), wantErr: domain.ErrContentTooLarge},
+		{name: "content over limit in drafts", content: strPtr(strings.Repeat("a", 101)), isDraft: true, wantErr: domain.ErrContentTooLarge},
+		{name: "excerpt too long", excerpt: strPtr(strings.Repeat("a", 31)), wantErr: domain.ErrInvalidPostContent},
+		{name: "excerpt at maximum", excerpt: strPtr(strings.Repeat("é", 30))},
+		{
+			name: "problems reported together", title: strPtr("Hi"), content: strPtr("short"),
+			excerpt: strPtr(strings.Repeat("a", 31)), wantErr: domain.ErrInvalidPostContent,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestPostService(testContentPolicy())
+
+			err := s.checkContentPolicy(tt.title, tt.content, tt.excerpt, tt.isDraft)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("checkContentPolicy() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCheckContentPolicyListsEveryProblem(t *testing.T) {
+	s := newTestPostService(testContentPolicy())
+
+	err := s.checkContentPolicy(strPtr("Hi"), strPtr("short"), strPtr(strings.Repeat("a", 31)), false)
+	if err == nil {
+		t.Fatal("checkContentPolicy() error = nil, want an error")
+	}
+
+	for _, want := range []string{"title", "content", "excerpt"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("checkContentPolicy() error = %q, want it to mention %s", err, want)
+		}
+	}
+}
+
+func TestWithUniqueSlug(t *testing.T) {
+	errDB := errors.New("connection reset")
+
+	tests := []struct {
+		name       string
+		maxRetries int
+		taken      int
+		failWith   error
+		wantSlug   string
+		wantErr    error
+		wantCalls  int
+	}{
+		{name: "free slug", maxRetries: 5, taken: 0, wantSlug: "hello", wantCalls: 1},
+		{name: "one collision", maxRetries: 5, taken: 1, wantSlug: "hello-2", wantCalls: 2},
+		{name: "collisions up to the limit", maxRetries: 5, taken: 5, wantSlug: "hello-6", wantCalls: 6},
+		{name: "many collisions", maxRetries: 5, taken: 1000, wantErr: domain.ErrSlugTaken, wantCalls: 6},
+		{name: "no retries", maxRetries: 0, taken: 1, wantErr: domain.ErrSlugTaken, wantCalls: 1},
+		{name: "other errors not retried", maxRetries: 5, failWith: errDB, wantErr: errDB, wantCalls: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &PostService{
+				slugMaxLength: 100,
+				postsCfg:      &config.PostsConfig{SlugMaxRetries: tt.maxRetries},
+			}
+
+			// The base slug and its first taken-1 suffixed forms already exist
+			var calls int
+			var created string
+			err := s.withUniqueSlug("hello", func(slug string) error {
+				calls++
+				if tt.failWith != nil {
+					return tt.failWith
+				}
+				if calls <= tt.taken {
+					return domain.ErrSlugTaken
+				}
+				created = slug
+				return nil
+			})
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("withUniqueSlug() error = %v, want %v", err, tt.wantErr)
+			}
+			if created != tt.wantSlug {
+				t.Errorf("created slug = %q, want %q", created, tt.wantSlug)
+			}
+			if calls != tt.wantCalls {
+				t.Errorf("create called %d times, want %d", calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestConcealmentPolicy(t *testing.T) {
+	statuses := []domain.PostStatus{domain.PostStatusDraft, domain.PostStatusPublished, domain.PostStatusArchived}
+	visibilities := []domain.PostVisibility{domain.PostVisibilityPublic, domain.PostVisibilityUnlisted, domain.PostVisibilityPrivate}
+
+	for _, concealUnpublished := range []bool{false, true} {
+		for _, status := range statuses {
+			for _, visibility := range visibilities {
+				name := fmt.Sprintf("conceal=%v/%s/%s", concealUnpublished, status, visibility)
+				t.Run(name, func(t *testing.T) {
+					s := &PostService{postsCfg: &config.PostsConfig{ConcealUnpublished: concealUnpublished}}
+					post := &domain.PostWithAuthor{
+						Post:   domain.Post{Status: status, Visibility: visibility},
+						Author: domain.PostAuthor{UUID: uuid.New()},
+					}
+
+					wantConcealed := visibility == domain.PostVisibilityPrivate ||
+						(concealUnpublished && status != domain.PostStatusPublished)
+					if got := s.concealed(post); got != wantConcealed {
+						t.Fatalf("concealed() = %v, want %v", got, wantConcealed)
+					}
+
+					// Non-owners updating or deleting
+					wantDenied := domain.ErrForbidden
+					if wantConcealed {
+						wantDenied = domain.ErrPostNotFound
+					}
+					if err := s.deniedAccess(post); !errors.Is(err, wantDenied) {
+						t.Errorf("deniedAccess() = %v, want %v", err, wantDenied)
+					}
+
+					// Anonymous readers
+					var wantAnonymous error
+					if wantConcealed {
+						wantAnonymous = domain.ErrPostNotFound
+					}
+					if err := s.checkVisible(context.Background(), post, nil); !errors.Is(err, wantAnonymous) {
+						t.Errorf("checkVisible(anonymous) = %v, want %v", err, wantAnonymous)
+					}
+
+					// The author always sees their post
+					author := post.Author.UUID
+					if err := s.checkVisible(context.Background(), post, &author); err != nil {
+						t.Errorf("checkVisible(author) = %v, want nil", err)
+					}
+				})
+			}
+		}
+	}
+}
+
+func TestCheckInteractableUnpublished(t *testing.T) {
+	s := &PostService{postsCfg: &config.PostsConfig{}}
+
+	for _, status := range []domain.PostStatus{domain.PostStatusDraft, domain.PostStatusArchived} {
+		t.Run(string(status), func(t *testing.T) {
+			post := &domain.PostWithAuthor{
+				Post:   domain.Post{Status: status, Visibility: domain.PostVisibilityPublic},
+				Author: domain.PostAuthor{UUID: uuid.New()},
+			}
+
+			// Even the author can't bookmark or record reading an unpublished post
+			if err := s.checkInteractable(context.Background(), post, post.Author.UUID); !errors.Is(err, domain.ErrPostNotFound) {
+				t.Errorf("checkInteractable() = %v, want ErrPostNotFound", err)
+			}
+		})
+	}
+}
+
+func TestCheckVisibleOtherUsers(t *testing.T) {
+	db := dbtest.New(t)
+	ctx := context.Background()
+
+	userUUID := func(id int) uuid.UUID {
+		t.Helper()
+		var u uuid.UUID
+		if err := db.QueryRow(ctx, `SELECT uuid FROM users WHERE id = $1`, id).Scan(&u); err != nil {
+			t.Fatalf("failed to read user: %v", err)
+		}
+		return u
+	}
+	reader := userUUID(dbtest.CreateUser(t, db, "reader"))
+	adminID := dbtest.CreateUser(t, db, "admin")
+	dbtest.Exec(t, db, `UPDATE users SET role = 'admin' WHERE id = $1`, adminID)
+	admin := userUUID(adminID)
+	unknown := uuid.New()
+
+	s := &PostService{
+		userRepo: repository.NewUserRepository(db),
+		postsCfg: &config.PostsConfig{ConcealUnpublished: true},
+	}
+
+	tests := []struct {
+		name       string
+		status     domain.PostStatus
+		visibility domain.PostVisibility
+		viewer     uuid.UUID
+		wantErr    error
+	}{
+		{name: "reader sees a published post", status: domain.PostStatusPublished, visibility: domain.PostVisibilityPublic, viewer: reader},
+		{name: "reader sees an unlisted post", status: domain.PostStatusPublished, visibility: domain.PostVisibilityUnlisted, viewer: reader},
+		{name: "reader can't see a private post", status: domain.PostStatusPublished, visibility: domain.PostVisibilityPrivate, viewer: reader, wantErr: domain.ErrPostNotFound},
+		{name: "reader can't see a draft", status: domain.PostStatusDraft, visibility: domain.PostVisibilityPublic, viewer: reader, wantErr: domain.ErrPostNotFound},
+		{name: "admin sees a private post", status: domain.PostStatusPublished, visibility: domain.PostVisibilityPrivate, viewer: admin},
+		{name: "admin sees a draft", status: domain.PostStatusDraft, visibility: domain.PostVisibilityPublic, viewer: admin},
+		{name: "deleted user can't see a draft", status: domain.PostStatusDraft, visibility: domain.PostVisibilityPublic, viewer: unknown, wantErr: domain.ErrPostNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			post := &domain.PostWithAuthor{
+				Post:   domain.Post{Status: tt.status, Visibility: tt.visibility},
+				Author: domain.PostAuthor{UUID: uuid.New()},
+			}
+
+			viewer := tt.viewer
+			if err := s.checkVisible(ctx, post, &viewer); !errors.Is(err, tt.wantErr) {
+				t.Errorf("checkVisible() = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// Listings hide other users' unpublished posts just as reading them does
+func TestListConcealsUnpublished(t *testing.T) {
+	db := dbtest.New(t)
+	ctx := context.Background()
+
+	userUUID := func(id int) uuid.UUID {
+		t.Helper()
+		var u uuid.UUID
+		if err := db.QueryRow(ctx, `SELECT uuid FROM users WHERE id = $1`, id).Scan(&u); err != nil {
+			t.Fatalf("failed to read user: %v", err)
+		}
+		return u
+	}
+	authorID := dbtest.CreateUser(t, db, "author")
+	author := userUUID(authorID)
+	reader := userUUID(dbtest.CreateUser(t, db, "reader"))
+	adminID := dbtest.CreateUser(t, db, "admin")
+	dbtest.Exec(t, db, `UPDATE users SET role = 'admin' WHERE id = $1`, adminID)
+	admin := userUUID(adminID)
+
+	for _, status := range []domain.PostStatus{domain.PostStatusPublished, domain.PostStatusDraft, domain.PostStatusArchived} {
+		dbtest.Exec(t, db, `
+			INSERT INTO posts (author_id, title, slug, content, status)
+			VALUES ($1, $2, $2, 'content', $2)
+		`, authorID, string(status))
+	}
+
+	newService := func(conceal bool) *PostService {
+		return &PostService{
+			postRepo: repository.NewPostRepository(db, nil),
+			userRepo: repository.NewUserRepository(db),
+			postsCfg: &config.PostsConfig{ConcealUnpublished: conceal},
+		}
+	}
+	draft := domain.PostStatusDraft
+
+	tests := []struct {
+		name    string
+		conceal bool
+		status  *domain.PostStatus
+		viewer  *uuid.UUID
+		want    int
+	}{
+		{name: "anonymous, no filter", conceal: true, want: 1},
+		{name: "anonymous, drafts", conceal: true, status: &draft, want: 0},
+		{name: "reader, no filter", conceal: true, viewer: &reader, want: 1},
+		{name: "reader, drafts", conceal: true, status: &draft, viewer: &reader, want: 0},
+		{name: "author, no filter", conceal: true, viewer: &author, want: 3},
+		{name: "author, drafts", conceal: true, status: &draft, viewer: &author, want: 1},
+		{name: "admin, no filter", conceal: true, viewer: &admin, want: 3},
+		{name: "not concealed", conceal: false, status: &draft, want: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := newService(tt.conceal).List(ctx, domain.ListPostsRequest{Status: tt.status}, tt.viewer)
+			if err != nil {
+				t.Fatalf("List() error = %v", err)
+			}
+			if resp.TotalCount != tt.want || len(resp.Posts) != tt.want {
+				t.Errorf("List() returned %d posts (total %d), want %d", len(resp.Posts), resp.TotalCount, tt.want)
+			}
+		})
+	}
+}
+
+// Generated excerpts are cut to the policy's maximum, whatever the strategy
+// and length, so they pass the policy when sent back on an update
+func TestGenerateExcerptFitsPolicy(t *testing.T) {
+	content := strings.Repeat("word ", 100)
+
+	tests := []struct {
+		strategy string
+		length   int
+	}{
+		{strategy: excerpt.StrategyChars, length: 30},
+		{strategy: excerpt.StrategyChars, length: 200},
+		{strategy: excerpt.StrategyWords, length: 20},
+		{strategy: excerpt.StrategyFirstParagraph, length: 200},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s/%d", tt.strategy, tt.length), func(t *testing.T) {
+			s := newTestPostService(testContentPolicy())
+			s.postsCfg.ExcerptStrategy = tt.strategy
+			s.postsCfg.ExcerptLength = tt.length
+
+			generated := s.generateExcerpt(content)
+			if utf8.RuneCountInString(generated) > s.postsCfg.Content.MaxExcerptLength {
+				t.Errorf("generateExcerpt() = %q, longer than %d characters", generated, s.postsCfg.Content.MaxExcerptLength)
+			}
+			if err := s.checkContentPolicy(nil, nil, &generated, false); err != nil {
+				t.Errorf("generated excerpt %q fails the content policy: %v", generated, err)
+			}
+		})
+	}
+}
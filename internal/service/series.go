@@ -0,0 +1,201 @@
+package service
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/saimonsiddique/blog-api/internal/domain"
+	"github.com/saimonsiddique/blog-api/internal/pkg/slug"
+	"github.com/saimonsiddique/blog-api/internal/repository"
+)
+
+type SeriesService struct {
+	seriesRepo    *repository.SeriesRepository
+	postRepo      *repository.PostRepository
+	userRepo      *repository.UserRepository
+	slugMaxLength int
+}
+
+func NewSeriesService(seriesRepo *repository.SeriesRepository, postRepo *repository.PostRepository, userRepo *repository.UserRepository, slugMaxLength int) *SeriesService {
+	return &SeriesService{
+		seriesRepo:    seriesRepo,
+		postRepo:      postRepo,
+		userRepo:      userRepo,
+		slugMaxLength: slugMaxLength,
+	}
+}
+
+// Create creates a new, empty series owned by the user
+func (s *SeriesService) Create(ctx context.Context, userUUID uuid.UUID, req domain.CreateSeriesRequest) (*domain.SeriesResponse, error) {
+	user, err := s.userRepo.GetByUUID(ctx, userUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	series := &domain.Series{
+		AuthorID:    user.ID,
+		Title:       req.Title,
+		Slug:        slug.GenerateWithMaxLength(req.Title, s.slugMaxLength),
+		Description: req.Description,
+	}
+
+	if err := s.seriesRepo.Create(ctx, series); err != nil {
+		return nil, err
+	}
+
+	return &domain.SeriesResponse{
+		UUID:        series.UUID,
+		Title:       series.Title,
+		Slug:        series.Slug,
+		Description: series.Description,
+		CreatedAt:   series.CreatedAt,
+		UpdatedAt:   series.UpdatedAt,
+		Author: domain.PostAuthor{
+			UUID:     user.UUID,
+			Username: user.Username,
+		},
+		Posts: []domain.SeriesPostResponse{},
+	}, nil
+}
+
+// GetBySlug retrieves a series with its published posts in order
+func (s *SeriesService) GetBySlug(ctx context.Context, seriesSlug string) (*domain.SeriesResponse, error) {
+	series, err := s.seriesRepo.GetBySlug(ctx, seriesSlug)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.buildResponse(ctx, series, true)
+}
+
+// AddPost appends one of the series author's posts to the series
+func (s *SeriesService) AddPost(ctx context.Context, userUUID uuid.UUID, role domain.UserRole, seriesSlug string, req domain.AddSeriesPostRequest) (*domain.SeriesResponse, error) {
+	series, err := s.getManageable(ctx, userUUID, role, seriesSlug)
+	if err != nil {
+		return nil, err
+	}
+
+	post, err := s.postRepo.GetByUUID(ctx, req.PostUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	// A series only collects posts by its own author
+	if post.AuthorID != series.AuthorID {
+		return nil, domain.ErrForbidden
+	}
+
+	if err := s.seriesRepo.AddPost(ctx, series.ID, post.ID); err != nil {
+		return nil, err
+	}
+
+	return s.buildResponse(ctx, series, false)
+}
+
+// RemovePost removes a post from the series
+func (s *SeriesService) RemovePost(ctx context.Context, userUUID uuid.UUID, role domain.UserRole, seriesSlug string, postUUID uuid.UUID) (*domain.SeriesResponse, error) {
+	series, err := s.getManageable(ctx, userUUID, role, seriesSlug)
+	if err != nil {
+		return nil, err
+	}
+
+	post, err := s.postRepo.GetByUUID(ctx, postUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.seriesRepo.RemovePost(ctx, series.ID, post.ID); err != nil {
+		return nil, err
+	}
+
+	return s.buildResponse(ctx, series, false)
+}
+
+// Reorder sets the order of the series' posts
+func (s *SeriesService) Reorder(ctx context.Context, userUUID uuid.UUID, role domain.UserRole, seriesSlug string, req domain.ReorderSeriesRequest) (*domain.SeriesResponse, error) {
+	series, err := s.getManageable(ctx, userUUID, role, seriesSlug)
+	if err != nil {
+		return nil, err
+	}
+
+	posts, err := s.seriesRepo.ListPosts(ctx, series.ID, false)
+	if err != nil {
+		return nil, err
+	}
+
+	// Map the requested UUIDs onto the series' post IDs
+	postIDsByUUID := make(map[uuid.UUID]int, len(posts))
+	for _, post := range posts {
+		postIDsByUUID[post.UUID] = post.ID
+	}
+
+	seen := make(map[uuid.UUID]bool, len(req.PostUUIDs))
+	postIDs := make([]int, 0, len(req.PostUUIDs))
+	for _, postUUID := range req.PostUUIDs {
+		postID, ok := postIDsByUUID[postUUID]
+		if !ok || seen[postUUID] {
+			return nil, domain.ErrInvalidSeriesOrder
+		}
+		seen[postUUID] = true
+		postIDs = append(postIDs, postID)
+	}
+
+	if err := s.seriesRepo.Reorder(ctx, series.ID, postIDs); err != nil {
+		return nil, err
+	}
+
+	return s.buildResponse(ctx, series, false)
+}
+
+// getManageable retrieves a series the user is allowed to modify
+func (s *SeriesService) getManageable(ctx context.Context, userUUID uuid.UUID, role domain.UserRole, seriesSlug string) (*domain.SeriesWithAuthor, error) {
+	series, err := s.seriesRepo.GetBySlug(ctx, seriesSlug)
+	if err != nil {
+		return nil, err
+	}
+
+	if role != domain.RoleAdmin && series.Author.UUID != userUUID {
+		return nil, domain.ErrForbidden
+	}
+
+	return series, nil
+}
+
+func (s *SeriesService) buildResponse(ctx context.Context, series *domain.SeriesWithAuthor, publishedOnly bool) (*domain.SeriesResponse, error) {
+	posts, err := s.seriesRepo.ListPosts(ctx, series.ID, publishedOnly)
+	if err != nil {
+		return nil, err
+	}
+
+	postResponses := make([]domain.SeriesPostResponse, len(posts))
+	for i, post := range posts {
+		postResponses[i] = domain.SeriesPostResponse{
+			Position: post.Position,
+			Post: domain.PostResponse{
+				UUID:        post.UUID,
+				Title:       post.Title,
+				Slug:        post.Slug,
+				Content:     post.Content,
+				Excerpt:     post.Excerpt,
+				Format:      post.Format,
+				Visibility:  post.Visibility,
+				Status:      post.Status,
+				PublishedAt: post.PublishedAt,
+				CreatedAt:   post.CreatedAt,
+				UpdatedAt:   post.UpdatedAt,
+				Author:      post.Author,
+			},
+		}
+	}
+
+	return &domain.SeriesResponse{
+		UUID:        series.UUID,
+		Title:       series.Title,
+		Slug:        series.Slug,
+		Description: series.Description,
+		CreatedAt:   series.CreatedAt,
+		UpdatedAt:   series.UpdatedAt,
+		Author:      series.Author,
+		Posts:       postResponses,
+	}, nil
+}
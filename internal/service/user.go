@@ -2,19 +2,24 @@ package service
 
 import (
 	"context"
+	"strings"
 
 	"github.com/google/uuid"
+	"github.com/saimonsiddique/blog-api/internal/config"
 	"github.com/saimonsiddique/blog-api/internal/domain"
+	"github.com/saimonsiddique/blog-api/internal/pkg/email"
 	"github.com/saimonsiddique/blog-api/internal/repository"
 )
 
 type UserService struct {
 	userRepo *repository.UserRepository
+	usersCfg *config.UsersConfig
 }
 
-func NewUserService(userRepo *repository.UserRepository) *UserService {
+func NewUserService(userRepo *repository.UserRepository, usersCfg *config.UsersConfig) *UserService {
 	return &UserService{
 		userRepo: userRepo,
+		usersCfg: usersCfg,
 	}
 }
 
@@ -27,6 +32,35 @@ func (s *UserService) GetProfile(ctx context.Context, userUUID uuid.UUID) (*doma
 	return user.ToResponse(), nil
 }
 
+// GetPublicProfiles returns the public profiles of several users in one
+// lookup, so list views don't need a request per user. Repeated IDs are
+// listed once.
+func (s *UserService) GetPublicProfiles(ctx context.Context, req domain.UsersBatchRequest) (*domain.UsersBatchResponse, error) {
+	users, err := s.userRepo.GetByUUIDs(ctx, req.UserIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	found := make(map[uuid.UUID]*domain.User, len(users))
+	for i := range users {
+		if users[i].IsActive {
+			found[users[i].UUID] = &users[i]
+		}
+	}
+
+	profiles := make([]domain.PublicProfile, 0, len(found))
+	for _, userUUID := range req.UserIDs {
+		user, ok := found[userUUID]
+		if !ok {
+			continue
+		}
+		delete(found, userUUID)
+		profiles = append(profiles, user.ToPublicProfile())
+	}
+
+	return &domain.UsersBatchResponse{Users: profiles}, nil
+}
+
 func (s *UserService) UpdateProfile(ctx context.Context, userUUID uuid.UUID, req domain.UpdateProfileRequest) (*domain.UserResponse, error) {
 	user, err := s.userRepo.GetByUUID(ctx, userUUID)
 	if err != nil {
@@ -35,10 +69,14 @@ func (s *UserService) UpdateProfile(ctx context.Context, userUUID uuid.UUID, req
 
 	// Update fields if provided
 	if req.Username != "" {
+		if s.usersCfg.IsReservedUsername(req.Username) && !strings.EqualFold(req.Username, user.Username) {
+			return nil, domain.ErrUsernameReserved
+		}
 		user.Username = req.Username
 	}
 	if req.Email != "" {
 		user.Email = req.Email
+		user.EmailNormalized = email.Normalize(req.Email, s.usersCfg.NormalizeGmailAliases)
 	}
 
 	// Save updates
@@ -0,0 +1,114 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/saimonsiddique/blog-api/internal/repository"
+	"github.com/sirupsen/logrus"
+)
+
+// inactiveAccountBatchSize bounds how many accounts a single run deactivates
+const inactiveAccountBatchSize = 500
+
+// InactiveAccountJanitor periodically deactivates accounts that haven't
+// logged in within the configured period and deletes their refresh tokens.
+// Access tokens already issued stay valid until they expire.
+type InactiveAccountJanitor struct {
+	userRepo        *repository.UserRepository
+	logger          *logrus.Logger
+	deactivateAfter time.Duration
+	interval        time.Duration
+	includeAdmins   bool
+	wg              sync.WaitGroup
+}
+
+func NewInactiveAccountJanitor(userRepo *repository.UserRepository, logger *logrus.Logger, deactivateAfter, interval time.Duration, includeAdmins bool) *InactiveAccountJanitor {
+	return &InactiveAccountJanitor{
+		userRepo:        userRepo,
+		logger:          logger,
+		deactivateAfter: deactivateAfter,
+		interval:        interval,
+		includeAdmins:   includeAdmins,
+	}
+}
+
+// Start runs the janitor until ctx is cancelled
+func (j *InactiveAccountJanitor) Start(ctx context.Context) {
+	j.logger.Infof("Inactive account janitor started, deactivating accounts without a login for %s", j.deactivateAfter)
+
+	j.wg.Add(1)
+	go func() {
+		defer j.wg.Done()
+
+		ticker := time.NewTicker(j.interval)
+		defer ticker.Stop()
+
+		for {
+			j.runOnce(ctx)
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Wait blocks until the current run finishes or the context expires
+func (j *InactiveAccountJanitor) Wait(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		j.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		j.logger.Info("Inactive account janitor stopped")
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("timed out waiting for inactive account janitor: %w", ctx.Err())
+	}
+}
+
+func (j *InactiveAccountJanitor) runOnce(ctx context.Context) {
+	before := time.Now().Add(-j.deactivateAfter)
+
+	users, err := j.userRepo.ListInactive(ctx, before, j.includeAdmins, inactiveAccountBatchSize)
+	if err != nil {
+		if ctx.Err() == nil {
+			j.logger.WithError(err).Error("Failed to list inactive accounts")
+		}
+		return
+	}
+
+	deactivated := 0
+	for _, user := range users {
+		ok, err := j.userRepo.DeactivateInactive(ctx, user.ID, before)
+		if err != nil {
+			if ctx.Err() != nil {
+				break
+			}
+			j.logger.WithError(err).Errorf("Failed to deactivate inactive account %s", user.UUID)
+			continue
+		}
+		if ok {
+			deactivated++
+			j.logger.WithFields(logrus.Fields{
+				"userUuid":    user.UUID,
+				"role":        user.Role,
+				"lastLoginAt": user.LastLoginAt,
+				"createdAt":   user.CreatedAt,
+			}).Info("Deactivated inactive account")
+		}
+	}
+
+	j.logger.WithFields(logrus.Fields{
+		"found":       len(users),
+		"deactivated": deactivated,
+	}).Info("Inactive account janitor run complete")
+}
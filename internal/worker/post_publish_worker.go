@@ -3,106 +3,344 @@ package worker
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
 	"time"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	amqp "github.com/rabbitmq/amqp091-go"
+	"github.com/saimonsiddique/blog-api/internal/cache"
 	"github.com/saimonsiddique/blog-api/internal/domain"
+	"github.com/saimonsiddique/blog-api/internal/metrics"
 	"github.com/saimonsiddique/blog-api/internal/queue"
 	"github.com/sirupsen/logrus"
 )
 
+const queueDepthInterval = 15 * time.Second
+
 type PostPublishWorker struct {
-	queue  *queue.RabbitMQ
-	db     *pgxpool.Pool
-	logger *logrus.Logger
+	queue       *queue.RabbitMQ
+	db          *pgxpool.Pool
+	logger      *logrus.Logger
+	concurrency int
+	broker      *queue.Relay[domain.PostPublishedNotification]
+	listCache   *cache.TTL[*domain.ListPostsResponse]
+	inbox       *queue.Relay[domain.Notification]
+	unread      *cache.TTL[*domain.UnreadNotificationsResponse]
+	wg          sync.WaitGroup
 }
 
-func NewPostPublishWorker(queue *queue.RabbitMQ, db *pgxpool.Pool, logger *logrus.Logger) *PostPublishWorker {
+// NewPostPublishWorker creates the worker. Published posts are announced
+// through broker to live subscribers on every instance and empty listCache, which may be nil. The
+// author's notification is pushed on inbox and their count dropped from
+// unread, which may also be nil.
+func NewPostPublishWorker(
+	queue *queue.RabbitMQ,
+	db *pgxpool.Pool,
+	logger *logrus.Logger,
+	concurrency int,
+	broker *queue.Relay[domain.PostPublishedNotification],
+	listCache *cache.TTL[*domain.ListPostsResponse],
+	inbox *queue.Relay[domain.Notification],
+	unread *cache.TTL[*domain.UnreadNotificationsResponse],
+) *PostPublishWorker {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
 	return &PostPublishWorker{
-		queue:  queue,
-		db:     db,
-		logger: logger,
+		queue:       queue,
+		db:          db,
+		logger:      logger,
+		concurrency: concurrency,
+		broker:      broker,
+		listCache:   listCache,
+		inbox:       inbox,
+		unread:      unread,
 	}
 }
 
 func (w *PostPublishWorker) Start(ctx context.Context) error {
 	// Declare queue
-	err := w.queue.DeclareQueue(domain.QueuePostPublish)
+	err := w.queue.DeclareQueueWithDLQ(domain.QueuePostPublish, domain.QueuePostPublishDLQ)
 	if err != nil {
 		return err
 	}
 
+	// Limit in-flight deliveries to the number of processors
+	if err := w.queue.SetQos(w.concurrency); err != nil {
+		return err
+	}
+
 	// Start consuming
 	msgs, err := w.queue.Consume(domain.QueuePostPublish)
 	if err != nil {
 		return err
 	}
 
-	w.logger.Info("Post publish worker started")
+	w.logger.Infof("Post publish worker started with %d processor(s)", w.concurrency)
 
-	go func() {
-		for {
-			select {
-			case <-ctx.Done():
-				w.logger.Info("Post publish worker stopped")
+	// Messages may be processed in any order across processors
+	for i := 0; i < w.concurrency; i++ {
+		w.wg.Add(1)
+		go w.run(ctx, msgs)
+	}
+
+	// Drain events queued before the move to the dead-lettering queue
+	legacy, err := w.queue.QueueExists(domain.QueuePostPublishLegacy)
+	if err != nil {
+		return err
+	}
+	if legacy {
+		legacyMsgs, err := w.queue.Consume(domain.QueuePostPublishLegacy)
+		if err != nil {
+			return err
+		}
+		w.logger.Infof("Draining legacy queue '%s'", domain.QueuePostPublishLegacy)
+
+		w.wg.Add(1)
+		go w.run(ctx, legacyMsgs)
+	}
+
+	go w.reportQueueDepth(ctx)
+
+	return nil
+}
+
+// run processes deliveries until the context is cancelled or the channel closes
+func (w *PostPublishWorker) run(ctx context.Context, msgs <-chan amqp.Delivery) {
+	defer w.wg.Done()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case msg, ok := <-msgs:
+			if !ok {
 				return
-			case msg := <-msgs:
-				w.processMessage(msg)
 			}
+			w.processMessage(ctx, msg)
 		}
+	}
+}
+
+// Wait blocks until all processors have finished their in-flight messages or
+// the context expires
+func (w *PostPublishWorker) Wait(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		w.wg.Wait()
+		close(done)
 	}()
 
-	return nil
+	select {
+	case <-done:
+		w.logger.Info("Post publish worker stopped")
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("timed out waiting for post publish worker: %w", ctx.Err())
+	}
 }
 
-func (w *PostPublishWorker) processMessage(msg amqp.Delivery) {
-	var event domain.PostPublishEvent
-	err := json.Unmarshal(msg.Body, &event)
+// reportQueueDepth periodically records the publish queue depth
+func (w *PostPublishWorker) reportQueueDepth(ctx context.Context) {
+	ticker := time.NewTicker(queueDepthInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			depth, err := w.queue.QueueDepth(domain.QueuePostPublish)
+			if err != nil {
+				w.logger.Warnf("Failed to inspect queue depth: %v", err)
+				continue
+			}
+			metrics.QueueDepth.WithLabelValues(domain.QueuePostPublish).Set(float64(depth))
+		}
+	}
+}
+
+func (w *PostPublishWorker) processMessage(ctx context.Context, msg amqp.Delivery) {
+	var envelope domain.EventEnvelope
+	err := json.Unmarshal(msg.Body, &envelope)
 	if err != nil {
 		w.logger.Errorf("Failed to unmarshal message: %v", err)
-		msg.Nack(false, false) // Don't requeue invalid messages
+		w.deadLetter(msg)
 		return
 	}
 
-	w.logger.Infof("Processing post publish event for post: %s", event.PostUUID)
+	// Events queued before the envelope was introduced are a bare publish
+	// event; treat them as version 1
+	if envelope.Type == "" && envelope.Payload == nil {
+		envelope = domain.EventEnvelope{
+			Type:    domain.EventTypePostPublish,
+			Version: domain.PostPublishEventVersion,
+			Payload: msg.Body,
+		}
+	}
+
+	// Carry the originating request ID through the worker's logs
+	log := logrus.NewEntry(w.logger)
+	if envelope.CorrelationID != "" {
+		log = log.WithField("requestId", envelope.CorrelationID)
+	}
+
+	// Reject events this worker does not understand
+	if envelope.Type != domain.EventTypePostPublish || envelope.Version != domain.PostPublishEventVersion {
+		log.Errorf("Unsupported event %s (version %d)", envelope.Type, envelope.Version)
+		w.deadLetter(msg)
+		return
+	}
+
+	var event domain.PostPublishEvent
+	err = json.Unmarshal(envelope.Payload, &event)
+	if err != nil {
+		log.Errorf("Failed to unmarshal event payload: %v", err)
+		w.deadLetter(msg)
+		return
+	}
+
+	log.Infof("Processing post publish event for post: %s", event.PostUUID)
 
 	// Check if scheduled for future
 	if event.ScheduledFor != nil && event.ScheduledFor.After(time.Now()) {
 		delay := time.Until(*event.ScheduledFor)
-		w.logger.Infof("Post %s scheduled for %v, waiting %v", event.PostUUID, event.ScheduledFor, delay)
-		time.Sleep(delay)
+		log.Infof("Post %s scheduled for %v, waiting %v", event.PostUUID, event.ScheduledFor, delay)
+
+		// Don't hold up shutdown: hand the message back to the queue instead
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			log.Infof("Shutting down, requeueing scheduled post %s", event.PostUUID)
+			msg.Nack(false, true)
+			return
+		}
 	}
 
 	// Publish the post
-	err = w.publishPost(context.Background(), event.PostUUID)
+	err = w.publishPost(context.Background(), log, &event)
 	if err != nil {
-		w.logger.Errorf("Failed to publish post %s: %v", event.PostUUID, err)
+		log.Errorf("Failed to publish post %s: %v", event.PostUUID, err)
 		msg.Nack(false, true) // Requeue on failure
+		metrics.EventRetries.WithLabelValues(domain.QueuePostPublish).Inc()
 		return
 	}
 
-	w.logger.Infof("Successfully published post: %s", event.PostUUID)
+	log.Infof("Successfully published post: %s", event.PostUUID)
 	msg.Ack(false)
+	metrics.EventProcessingLatency.WithLabelValues(domain.QueuePostPublish).
+		Observe(time.Since(dueAt(&event)).Seconds())
 }
 
-func (w *PostPublishWorker) publishPost(ctx context.Context, postUUID string) error {
+// dueAt is when the event was due to be processed: when it was requested, or
+// when it was scheduled for if that's later. Latency is measured from it so
+// scheduled posts don't count their wait as lag.
+func dueAt(event *domain.PostPublishEvent) time.Time {
+	if event.ScheduledFor != nil && event.ScheduledFor.After(event.RequestedAt) {
+		return *event.ScheduledFor
+	}
+	return event.RequestedAt
+}
+
+// deadLetter rejects a message without requeue so it is routed to the DLQ
+func (w *PostPublishWorker) deadLetter(msg amqp.Delivery) {
+	msg.Nack(false, false)
+	metrics.EventsDeadLettered.WithLabelValues(domain.QueuePostPublish).Inc()
+}
+
+// publishPost publishes the post referenced by the event. It is idempotent:
+// events that were already processed are skipped, and published_at is only
+// set the first time a post is published.
+func (w *PostPublishWorker) publishPost(ctx context.Context, log *logrus.Entry, event *domain.PostPublishEvent) error {
+	tx, err := w.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	// Record the event; a conflict means it was already processed
+	if event.EventID != "" {
+		result, err := tx.Exec(ctx, `
+			INSERT INTO processed_events (event_id, event_type)
+			VALUES ($1, $2)
+			ON CONFLICT (event_id) DO NOTHING
+		`, event.EventID, domain.EventTypePostPublish)
+		if err != nil {
+			return err
+		}
+
+		if result.RowsAffected() == 0 {
+			log.Infof("Event %s already processed, skipping", event.EventID)
+			return nil
+		}
+	}
+
 	query := `
-		UPDATE posts
+		UPDATE posts p
 		SET status = 'published',
-		    published_at = CURRENT_TIMESTAMP,
+		    published_at = COALESCE(p.published_at, CURRENT_TIMESTAMP),
 		    updated_at = CURRENT_TIMESTAMP
-		WHERE uuid = $1 AND status = 'draft'
+		FROM users u
+		WHERE p.uuid = $1 AND p.status = 'draft' AND u.id = p.author_id
+		RETURNING p.uuid, u.uuid, p.title, p.slug, p.published_at, p.visibility
 	`
 
-	result, err := w.db.Exec(ctx, query, postUUID)
+	var notification domain.PostPublishedNotification
+	var visibility domain.PostVisibility
+	err = tx.QueryRow(ctx, query, event.PostUUID).Scan(
+		&notification.PostUUID,
+		&notification.AuthorUUID,
+		&notification.Title,
+		&notification.Slug,
+		&notification.PublishedAt,
+		&visibility,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		log.Warnf("Post %s not found or already published", event.PostUUID)
+		return tx.Commit(ctx)
+	}
+	if err != nil {
+		return err
+	}
+
+	// Confirm to the author in their notification inbox. It's written in the
+	// same transaction so it is recorded exactly once.
+	payload, err := json.Marshal(notification)
+	if err != nil {
+		return err
+	}
+	var inboxEntry domain.Notification
+	err = tx.QueryRow(ctx, `
+		INSERT INTO notifications (user_id, type, payload)
+		SELECT author_id, $2, $3 FROM posts WHERE uuid = $1
+		RETURNING id, uuid, user_id, type, payload, read_at, created_at
+	`, event.PostUUID, domain.NotificationPostPublished, payload).Scan(
+		&inboxEntry.ID,
+		&inboxEntry.UUID,
+		&inboxEntry.UserID,
+		&inboxEntry.Type,
+		&inboxEntry.Payload,
+		&inboxEntry.ReadAt,
+		&inboxEntry.CreatedAt,
+	)
 	if err != nil {
 		return err
 	}
 
-	if result.RowsAffected() == 0 {
-		w.logger.Warnf("Post %s not found or already published", postUUID)
+	if err := tx.Commit(ctx); err != nil {
+		return err
 	}
 
+	w.listCache.Invalidate()
+	// Unlisted and private posts aren't announced to live subscribers
+	if visibility == domain.PostVisibilityPublic {
+		w.broker.Publish(ctx, notification)
+	}
+	w.unread.Delete(notification.AuthorUUID.String())
+	w.inbox.Publish(ctx, inboxEntry)
 	return nil
 }
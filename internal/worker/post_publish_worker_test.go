@@ -0,0 +1,124 @@
+package worker
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/saimonsiddique/blog-api/internal/cache"
+	"github.com/saimonsiddique/blog-api/internal/database/dbtest"
+	"github.com/saimonsiddique/blog-api/internal/domain"
+	"github.com/saimonsiddique/blog-api/internal/events"
+	"github.com/saimonsiddique/blog-api/internal/queue"
+	"github.com/sirupsen/logrus"
+)
+
+func TestPublishPostIsIdempotent(t *testing.T) {
+	db := dbtest.New(t)
+	ctx := context.Background()
+
+	authorID := dbtest.CreateUser(t, db, "author")
+	var postUUID uuid.UUID
+	err := db.QueryRow(ctx, `
+		INSERT INTO posts (author_id, title, slug, content)
+		VALUES ($1, 'Hello', 'hello', 'content')
+		RETURNING uuid
+	`, authorID).Scan(&postUUID)
+	if err != nil {
+		t.Fatalf("failed to create post: %v", err)
+	}
+
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	broker := events.NewBroker[domain.PostPublishedNotification]()
+	w := NewPostPublishWorker(nil, db, logger, 1,
+		queue.NewRelay(nil, domain.ExchangePostPublished, broker, logger),
+		cache.NewTTL[*domain.ListPostsResponse]("posts", time.Minute),
+		queue.NewRelay(nil, domain.ExchangeNotifications, events.NewBroker[domain.Notification](), logger),
+		cache.NewTTL[*domain.UnreadNotificationsResponse]("unread", time.Minute),
+	)
+
+	announced, unsubscribe := broker.Subscribe(nil)
+	defer unsubscribe()
+
+	event := &domain.PostPublishEvent{
+		EventID:     uuid.NewString(),
+		PostUUID:    postUUID.String(),
+		RequestedAt: time.Now(),
+	}
+	log := logrus.NewEntry(logger)
+
+	publishedAt := func() time.Time {
+		t.Helper()
+		var at *time.Time
+		if err := db.QueryRow(ctx, `SELECT published_at FROM posts WHERE uuid = $1`, postUUID).Scan(&at); err != nil {
+			t.Fatalf("failed to read post: %v", err)
+		}
+		if at == nil {
+			t.Fatal("post has no publish timestamp")
+		}
+		return *at
+	}
+
+	if err := w.publishPost(ctx, log, event); err != nil {
+		t.Fatalf("first publishPost() error = %v", err)
+	}
+	first := publishedAt()
+
+	// Redelivery of the same event
+	if err := w.publishPost(ctx, log, event); err != nil {
+		t.Fatalf("second publishPost() error = %v", err)
+	}
+	if second := publishedAt(); !second.Equal(first) {
+		t.Errorf("published_at changed from %v to %v on redelivery", first, second)
+	}
+
+	// A different event for an already published post
+	again := *event
+	again.EventID = uuid.NewString()
+	if err := w.publishPost(ctx, log, &again); err != nil {
+		t.Fatalf("publishPost() with a new event error = %v", err)
+	}
+	if third := publishedAt(); !third.Equal(first) {
+		t.Errorf("published_at changed from %v to %v on a second event", first, third)
+	}
+
+	var notifications int
+	if err := db.QueryRow(ctx, `SELECT COUNT(*) FROM notifications WHERE user_id = $1`, authorID).Scan(&notifications); err != nil {
+		t.Fatalf("failed to count notifications: %v", err)
+	}
+	if notifications != 1 {
+		t.Errorf("author has %d notifications, want 1", notifications)
+	}
+
+	if got := len(announced); got != 1 {
+		t.Errorf("announced %d times, want 1", got)
+	}
+}
+
+func TestDueAt(t *testing.T) {
+	requested := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	earlier := requested.Add(-time.Hour)
+	later := requested.Add(time.Hour)
+
+	tests := []struct {
+		name      string
+		scheduled *time.Time
+		want      time.Time
+	}{
+		{name: "not scheduled", want: requested},
+		{name: "scheduled later", scheduled: &later, want: later},
+		{name: "scheduled in the past", scheduled: &earlier, want: requested},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event := &domain.PostPublishEvent{RequestedAt: requested, ScheduledFor: tt.scheduled}
+			if got := dueAt(event); !got.Equal(tt.want) {
+				t.Errorf("dueAt() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
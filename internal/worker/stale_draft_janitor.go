@@ -0,0 +1,111 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/saimonsiddique/blog-api/internal/repository"
+	"github.com/sirupsen/logrus"
+)
+
+// staleDraftBatchSize bounds how many drafts a single run archives
+const staleDraftBatchSize = 500
+
+// StaleDraftJanitor periodically archives drafts that haven't been updated
+// within the configured period. Archiving is reversible: authors can move an
+// archived post back to draft.
+type StaleDraftJanitor struct {
+	postRepo     *repository.PostRepository
+	logger       *logrus.Logger
+	archiveAfter time.Duration
+	interval     time.Duration
+	wg           sync.WaitGroup
+}
+
+func NewStaleDraftJanitor(postRepo *repository.PostRepository, logger *logrus.Logger, archiveAfter, interval time.Duration) *StaleDraftJanitor {
+	return &StaleDraftJanitor{
+		postRepo:     postRepo,
+		logger:       logger,
+		archiveAfter: archiveAfter,
+		interval:     interval,
+	}
+}
+
+// Start runs the janitor until ctx is cancelled
+func (j *StaleDraftJanitor) Start(ctx context.Context) {
+	j.logger.Infof("Stale draft janitor started, archiving drafts untouched for %s", j.archiveAfter)
+
+	j.wg.Add(1)
+	go func() {
+		defer j.wg.Done()
+
+		ticker := time.NewTicker(j.interval)
+		defer ticker.Stop()
+
+		for {
+			j.runOnce(ctx)
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Wait blocks until the current run finishes or the context expires
+func (j *StaleDraftJanitor) Wait(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		j.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		j.logger.Info("Stale draft janitor stopped")
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("timed out waiting for stale draft janitor: %w", ctx.Err())
+	}
+}
+
+func (j *StaleDraftJanitor) runOnce(ctx context.Context) {
+	before := time.Now().Add(-j.archiveAfter)
+
+	drafts, err := j.postRepo.ListStaleDrafts(ctx, before, staleDraftBatchSize)
+	if err != nil {
+		if ctx.Err() == nil {
+			j.logger.WithError(err).Error("Failed to list stale drafts")
+		}
+		return
+	}
+
+	archived := 0
+	for _, draft := range drafts {
+		ok, err := j.postRepo.ArchiveStaleDraft(ctx, draft.ID, before)
+		if err != nil {
+			if ctx.Err() != nil {
+				break
+			}
+			j.logger.WithError(err).Errorf("Failed to archive stale draft %s", draft.UUID)
+			continue
+		}
+		if ok {
+			archived++
+			j.logger.WithFields(logrus.Fields{
+				"postUuid":  draft.UUID,
+				"authorId":  draft.AuthorID,
+				"updatedAt": draft.UpdatedAt,
+			}).Debug("Archived stale draft")
+		}
+	}
+
+	j.logger.WithFields(logrus.Fields{
+		"found":    len(drafts),
+		"archived": archived,
+	}).Info("Stale draft janitor run complete")
+}
@@ -0,0 +1,9 @@
+-- Create processed_events table to deduplicate queue deliveries
+CREATE TABLE IF NOT EXISTS processed_events (
+    event_id UUID PRIMARY KEY,
+    event_type VARCHAR(100) NOT NULL,
+    processed_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create index for cleanup of old entries
+CREATE INDEX idx_processed_events_processed_at ON processed_events(processed_at);
@@ -0,0 +1,10 @@
+-- Create read_posts table
+CREATE TABLE IF NOT EXISTS read_posts (
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    post_id INTEGER NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+    read_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, post_id)
+);
+
+-- Create index for listing a user's history by recency
+CREATE INDEX idx_read_posts_user_id_read_at ON read_posts(user_id, read_at DESC);
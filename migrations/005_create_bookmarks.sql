@@ -0,0 +1,10 @@
+-- Create bookmarks table
+CREATE TABLE IF NOT EXISTS bookmarks (
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    post_id INTEGER NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, post_id)
+);
+
+-- Create index for listing a user's bookmarks by recency
+CREATE INDEX idx_bookmarks_user_id_created_at ON bookmarks(user_id, created_at DESC);
@@ -0,0 +1,37 @@
+-- Create series table
+CREATE TABLE IF NOT EXISTS series (
+    id SERIAL PRIMARY KEY,
+    uuid UUID NOT NULL UNIQUE DEFAULT gen_random_uuid(),
+    author_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    title VARCHAR(255) NOT NULL,
+    slug VARCHAR(255) NOT NULL UNIQUE,
+    description TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create series_posts ordering table; a post belongs to at most one series
+CREATE TABLE IF NOT EXISTS series_posts (
+    series_id INTEGER NOT NULL REFERENCES series(id) ON DELETE CASCADE,
+    post_id INTEGER NOT NULL UNIQUE REFERENCES posts(id) ON DELETE CASCADE,
+    position INTEGER NOT NULL,
+    PRIMARY KEY (series_id, post_id)
+);
+
+-- Create indexes
+CREATE INDEX idx_series_author_id ON series(author_id);
+CREATE INDEX idx_series_posts_series_id_position ON series_posts(series_id, position);
+
+-- Create updated_at trigger
+CREATE OR REPLACE FUNCTION update_series_updated_at()
+RETURNS TRIGGER AS $$
+BEGIN
+    NEW.updated_at = CURRENT_TIMESTAMP;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trigger_update_series_updated_at
+    BEFORE UPDATE ON series
+    FOR EACH ROW
+    EXECUTE FUNCTION update_series_updated_at();
@@ -0,0 +1,10 @@
+-- Store a normalized email used for matching accounts; the email column keeps
+-- the address as entered for display.
+-- Backfilling fails if existing emails differ only by case; resolve those
+-- accounts before running this migration.
+ALTER TABLE users ADD COLUMN email_normalized VARCHAR(255);
+
+UPDATE users SET email_normalized = LOWER(TRIM(email));
+
+ALTER TABLE users ALTER COLUMN email_normalized SET NOT NULL;
+ALTER TABLE users ADD CONSTRAINT users_email_normalized_key UNIQUE (email_normalized);
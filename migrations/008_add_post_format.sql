@@ -0,0 +1,5 @@
+-- Record how post content is authored so clients know how to render it.
+-- Existing posts default to markdown.
+ALTER TABLE posts
+    ADD COLUMN format VARCHAR(20) NOT NULL DEFAULT 'markdown'
+    CHECK (format IN ('markdown', 'html', 'plain'));
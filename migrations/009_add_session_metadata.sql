@@ -0,0 +1,6 @@
+-- Identify refresh-token sessions and record the client that created them,
+-- so sessions can be listed and revoked without exposing token hashes.
+ALTER TABLE refresh_tokens
+    ADD COLUMN uuid UUID NOT NULL UNIQUE DEFAULT gen_random_uuid(),
+    ADD COLUMN user_agent TEXT,
+    ADD COLUMN ip_address VARCHAR(45);
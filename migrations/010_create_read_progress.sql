@@ -0,0 +1,8 @@
+-- Create read_progress table for resuming posts where the reader left off
+CREATE TABLE IF NOT EXISTS read_progress (
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    post_id INTEGER NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+    percent SMALLINT NOT NULL CHECK (percent BETWEEN 0 AND 100),
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, post_id)
+);
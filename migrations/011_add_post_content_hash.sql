@@ -0,0 +1,7 @@
+-- Hash each post's title and content so repeated submissions of the same
+-- post by an author can be detected. md5 is fine here: the hash only has to
+-- spot identical text, not resist tampering.
+ALTER TABLE posts
+    ADD COLUMN content_hash CHAR(32) GENERATED ALWAYS AS (md5(title || E'\n' || content)) STORED;
+
+CREATE INDEX idx_posts_author_id_content_hash ON posts(author_id, content_hash, created_at DESC);
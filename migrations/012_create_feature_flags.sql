@@ -0,0 +1,7 @@
+-- Create feature_flags table for runtime toggles. Flags without a row use
+-- their built-in default.
+CREATE TABLE IF NOT EXISTS feature_flags (
+    key VARCHAR(100) PRIMARY KEY,
+    enabled BOOLEAN NOT NULL,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
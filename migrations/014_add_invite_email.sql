@@ -0,0 +1,2 @@
+-- Optionally pin an invite to one email address, stored normalized
+ALTER TABLE invites ADD COLUMN email VARCHAR(255);
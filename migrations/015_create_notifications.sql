@@ -0,0 +1,17 @@
+-- Create notifications table. Payload holds type-specific details, e.g. the
+-- post for a post.published notification.
+CREATE TABLE IF NOT EXISTS notifications (
+    id SERIAL PRIMARY KEY,
+    uuid UUID NOT NULL UNIQUE DEFAULT gen_random_uuid(),
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    type VARCHAR(50) NOT NULL,
+    payload JSONB NOT NULL DEFAULT '{}',
+    read_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create index for listing a user's notifications by recency
+CREATE INDEX idx_notifications_user_id_created_at ON notifications(user_id, created_at DESC);
+
+-- Create partial index for unread listings and counts
+CREATE INDEX idx_notifications_user_id_unread ON notifications(user_id, created_at DESC) WHERE read_at IS NULL;
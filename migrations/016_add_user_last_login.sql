@@ -0,0 +1,7 @@
+-- Record when each user last logged in, so accounts left unused for a long
+-- time can be found and deactivated. NULL means no login since this column
+-- was added; created_at stands in for it then.
+ALTER TABLE users
+    ADD COLUMN last_login_at TIMESTAMP;
+
+CREATE INDEX idx_users_last_login_at ON users(COALESCE(last_login_at, created_at)) WHERE is_active;
@@ -0,0 +1,4 @@
+-- Record the client address of each user's last login alongside its time,
+-- for reviewing account activity and spotting unusual logins.
+ALTER TABLE users
+    ADD COLUMN last_login_ip VARCHAR(45);
@@ -0,0 +1,11 @@
+-- Create known_devices table. Each row is a device a user has logged in
+-- from, identified by a hash of its user agent and network (the IP's subnet)
+-- so raw client details aren't kept here.
+CREATE TABLE IF NOT EXISTS known_devices (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    device_hash CHAR(64) NOT NULL,
+    first_seen_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_seen_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (user_id, device_hash)
+);
@@ -0,0 +1,6 @@
+-- Control who can find a post, independently of its status: public posts
+-- are listed, unlisted posts are reachable by direct link only, and private
+-- posts only by their author and admins. Existing posts stay public.
+ALTER TABLE posts
+    ADD COLUMN visibility VARCHAR(20) NOT NULL DEFAULT 'public'
+    CHECK (visibility IN ('public', 'unlisted', 'private'));